@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/auth"
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func apiURLFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "api-url",
+		Usage:   "Base URL of the Squadbase API",
+		Value:   api.DefaultBaseURL,
+		EnvVars: []string{"SQUAD_API_URL"},
+	}
+}
+
+func projectFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "project",
+		Usage:   "Squadbase project name (default: the project directory's name)",
+		EnvVars: []string{"SQUAD_PROJECT"},
+	}
+}
+
+// projectName returns the project given with --project, or the name derived
+// from the project directory.
+func projectName(c *cli.Context, directory string) string {
+	if project := c.String("project"); project != "" {
+		return project
+	}
+	return export.AppName(directory)
+}
+
+func accountFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "account",
+		Aliases: []string{"a"},
+		Usage:   "Name of the logged in account to use (default: the current account)",
+		EnvVars: []string{"SQUAD_ACCOUNT"},
+	}
+}
+
+// apiCredentials is the token an API command authenticates with, and where
+// it came from.
+type apiCredentials struct {
+	// Account is the stored account, empty when the token came from $SQUAD_TOKEN.
+	Account string
+	Token   string
+	APIURL  string
+}
+
+// resolveCredentials returns $SQUAD_TOKEN when it is set, and otherwise the
+// account selected with --account or the current account. --api-url, when
+// given, wins over the API URL the account logged in to.
+func resolveCredentials(c *cli.Context) (*apiCredentials, error) {
+	if token := os.Getenv(auth.TokenEnv); token != "" {
+		return &apiCredentials{Token: token, APIURL: c.String("api-url")}, nil
+	}
+
+	path, err := auth.Path()
+	if err != nil {
+		return nil, err
+	}
+	creds, err := auth.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	name, account, err := creds.Account(c.String("account"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: run squad login or set %s", err, auth.TokenEnv)
+	}
+
+	apiURL := account.APIURL
+	if c.IsSet("api-url") || apiURL == "" {
+		apiURL = c.String("api-url")
+	}
+	return &apiCredentials{Account: name, Token: account.Token, APIURL: apiURL}, nil
+}
+
+// newAPIClient returns a client for the Squadbase API that authenticates
+// with the credentials selected by resolveCredentials.
+func newAPIClient(c *cli.Context) (*api.Client, error) {
+	creds, err := resolveCredentials(c)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, err
+	}
+	client, err := api.NewClient(creds.APIURL, creds.Token)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, err
+	}
+	return client, nil
+}
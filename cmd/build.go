@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/container"
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func BuildCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "build",
+		Usage:     "Build the container image locally with docker or podman",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			envFlag(),
+			&cli.StringSliceFlag{
+				Name:    "tag",
+				Aliases: []string{"t"},
+				Usage:   "Name and tag of the image, can be repeated (default: <project>:latest)",
+			},
+			&cli.StringFlag{
+				Name:  "platform",
+				Usage: "Platform to build for",
+				Value: container.DefaultPlatform,
+			},
+			&cli.BoolFlag{
+				Name:  "no-cache",
+				Usage: "Build without using cached layers",
+			},
+			engineFlag(),
+		},
+		Action: buildAction,
+	}
+}
+
+func engineFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "engine",
+		Usage:   "Container engine to use: docker or podman (default: the first one installed)",
+		EnvVars: []string{"SQUAD_CONTAINER_ENGINE"},
+	}
+}
+
+func buildAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadSquadbaseYml(directory, c.String("env"))
+	if err != nil {
+		return err
+	}
+
+	engine, err := container.Detect(c.String("engine"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	scratch, err := os.MkdirTemp("", "squadbase_build_")
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to create temporary directory: %v", err))
+		return err
+	}
+	defer os.RemoveAll(scratch)
+
+	opts, err := container.NewBuildOptions(directory, cfg.Build, scratch)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	opts.Platform = c.String("platform")
+	opts.NoCache = c.Bool("no-cache")
+	opts.Tags = c.StringSlice("tag")
+	if len(opts.Tags) == 0 {
+		opts.Tags = []string{export.AppName(directory) + ":latest"}
+	}
+
+	dockerfile := opts.Dockerfile
+	if opts.Generated {
+		dockerfile = fmt.Sprintf("generated for %s with %s", cfg.Build.Framework, cfg.Build.PackageManager)
+	}
+	ui.PrintSummaryBox("🐳 Build", map[string]string{
+		"Engine":     engine.Name(),
+		"Dockerfile": dockerfile,
+		"Context":    opts.Context,
+		"Platform":   opts.Platform,
+		"Tags":       strings.Join(opts.Tags, ", "),
+	})
+	fmt.Println()
+
+	if err := container.Build(c.Context, engine, opts, c.App.Writer, c.App.ErrWriter); err != nil {
+		printBuildError(err)
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Built %s", strings.Join(opts.Tags, ", ")))
+	return nil
+}
+
+// printBuildError prints the summary of a failed build.
+func printBuildError(err error) {
+	var buildErr *container.BuildError
+	if errors.As(err, &buildErr) {
+		ui.PrintErrorBox(fmt.Sprintf("❌ Build failed (exit code %d)", buildErr.ExitCode), strings.Join(buildErr.Summary, "\n"))
+		return
+	}
+	ui.PrintError(err.Error())
+}
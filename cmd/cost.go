@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/cost"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func CostCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "cost",
+		Usage:     "Estimate the monthly cost of the configured deployment",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:     "requests-per-month",
+				Aliases:  []string{"r"},
+				Usage:    "Expected number of requests per month",
+				Required: true,
+			},
+			&cli.DurationFlag{
+				Name:    "avg-duration",
+				Aliases: []string{"d"},
+				Usage:   "Average request duration, e.g. 300ms or 2s",
+				Value:   cost.DefaultAvgDuration,
+			},
+			envFlag(),
+			targetFlag(),
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the estimates as JSON",
+			},
+		},
+		Action: costAction,
+	}
+}
+
+// costColumn is one estimate in the side-by-side preset comparison.
+type costColumn struct {
+	Name     string         `json:"name"`
+	Estimate *cost.Estimate `json:"estimate"`
+}
+
+// costRow is one line of the preset comparison table.
+type costRow struct {
+	name  string
+	value func(e *cost.Estimate) string
+}
+
+func costAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	_, target, err := loadDeploymentTarget(c, directory)
+	if err != nil {
+		return err
+	}
+
+	p, err := provider.Get(target.Provider)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	usage := cost.Usage{
+		RequestsPerMonth: c.Int64("requests-per-month"),
+		AvgDuration:      c.Duration("avg-duration"),
+	}
+
+	current, err := cost.Calculate(p.Name(), p.Resources(&target.Deployment), usage)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	columns := []costColumn{{Name: "current", Estimate: current}}
+	presets, err := presetEstimates(p, target, usage)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	columns = append(columns, presets...)
+
+	w := c.App.Writer
+	if c.Bool("json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(columns)
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, ui.GetPrimaryText(fmt.Sprintf("💰 Estimated monthly cost of %s on %s", target.Name, current.Service)))
+	fmt.Fprintf(w, "%s requests/month, %s average duration, %s region\n",
+		formatThousands(usage.RequestsPerMonth), usage.AvgDuration, current.Resources.Region)
+	fmt.Fprintln(w, "")
+
+	for _, item := range current.Items {
+		fmt.Fprintf(w, "  %-26s %12s   %s\n", item.Name, formatMoney(item.Cost, current.Currency), ui.GetAccentText(item.Detail))
+	}
+	fmt.Fprintf(w, "  %-26s %12s\n", strings.Repeat("─", 26), strings.Repeat("─", 12))
+	fmt.Fprintf(w, "  %-26s %12s\n", "Total", ui.GetSecondaryText(formatMoney(current.Total, current.Currency)))
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, ui.GetPrimaryText("📊 Preset comparison"))
+	fmt.Fprintf(w, "  %-26s", "")
+	for _, column := range columns {
+		fmt.Fprintf(w, " %12s", column.Name)
+	}
+	fmt.Fprintln(w, "")
+
+	rows := []costRow{
+		{"Memory (MB)", func(e *cost.Estimate) string { return fmt.Sprintf("%d", e.Resources.MemoryMB) }},
+		{"vCPU", func(e *cost.Estimate) string { return fmt.Sprintf("%.2f", e.Resources.CPU) }},
+		{"Concurrency", func(e *cost.Estimate) string { return fmt.Sprintf("%d", e.Resources.Concurrency) }},
+		{"Always-on instances", func(e *cost.Estimate) string { return fmt.Sprintf("%d", e.Resources.MinInstances) }},
+	}
+	for _, name := range itemNames(columns) {
+		itemName := name
+		rows = append(rows, costRow{itemName, func(e *cost.Estimate) string {
+			for _, item := range e.Items {
+				if item.Name == itemName {
+					return formatMoney(item.Cost, e.Currency)
+				}
+			}
+			return "-"
+		}})
+	}
+	rows = append(rows, costRow{"Total", func(e *cost.Estimate) string { return formatMoney(e.Total, e.Currency) }})
+
+	for _, row := range rows {
+		fmt.Fprintf(w, "  %-26s", row.name)
+		for _, column := range columns {
+			fmt.Fprintf(w, " %12s", row.value(column.Estimate))
+		}
+		fmt.Fprintln(w, "")
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Prices: %s (%s). Estimates assume requests are spread evenly and exclude network egress.\n",
+		current.PriceVersion, current.Basis)
+	return nil
+}
+
+// presetEstimates estimates the cost of every resource preset of the
+// provider in the configured region.
+func presetEstimates(p provider.Provider, target *config.Target, usage cost.Usage) ([]costColumn, error) {
+	catalog, err := resources.ForProvider(p.Name())
+	if err != nil {
+		return nil, err
+	}
+	region := p.Resources(&target.Deployment).Region
+
+	columns := []costColumn{}
+	for _, name := range catalog.PresetNames() {
+		doc, err := config.ParseDocument(nil)
+		if err != nil {
+			return nil, err
+		}
+		doc.SetString(p.Name(), "deployment", "provider")
+		doc.SetString(name, "deployment", p.Name(), "preset")
+		doc.SetString(region, "deployment", p.Name(), "region")
+		if err := resources.ExpandPresets(doc); err != nil {
+			return nil, err
+		}
+		presetCfg, err := doc.Decode()
+		if err != nil {
+			return nil, err
+		}
+
+		estimate, err := cost.Calculate(p.Name(), p.Resources(&presetCfg.Deployment), usage)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, costColumn{Name: name, Estimate: estimate})
+	}
+	return columns, nil
+}
+
+// itemNames returns the names of the cost items of every column, in the
+// order they first appear.
+func itemNames(columns []costColumn) []string {
+	names := []string{}
+	for _, column := range columns {
+		for _, item := range column.Estimate.Items {
+			if !slices.Contains(names, item.Name) {
+				names = append(names, item.Name)
+			}
+		}
+	}
+	return names
+}
+
+func formatMoney(amount float64, currency string) string {
+	symbol := currency + " "
+	if currency == "USD" {
+		symbol = "$"
+	}
+	return fmt.Sprintf("%s%.2f", symbol, amount)
+}
+
+func formatThousands(n int64) string {
+	digits := fmt.Sprintf("%d", n)
+	var out strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			out.WriteByte(',')
+		}
+		out.WriteRune(d)
+	}
+	return out.String()
+}
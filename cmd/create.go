@@ -10,6 +10,7 @@ import (
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
 	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/provider"
 	"github.com/squadbase/squadbase/internal/templates"
 	"github.com/squadbase/squadbase/internal/ui"
 	"github.com/urfave/cli/v2"
@@ -224,27 +225,20 @@ func createAction(c *cli.Context) error {
 	ui.PrintStep(4, 5, "Deployment Configuration")
 	fmt.Println(ui.GetAccentText("\n🚀 Deployment Configuration"))
 
-	deploymentOptions := []string{"aws", "gcp"}
-	deploymentDefault := "aws"
-
-	if templateName == "streamlit" || templateName == "nextjs" {
-		deploymentOptions = []string{"gcp"}
-		deploymentDefault = "gcp"
+	deploymentProvider, err := provider.Select(templateName, "")
+	if err != nil {
+		ui.PrintError("Deployment provider selection cancelled")
+		return fmt.Errorf("project creation cancelled")
 	}
 
-	dpPrompt := &survey.Select{
-		Message: "Select deployment provider:",
-		Options: deploymentOptions,
-		Default: deploymentDefault,
-	}
-	var deploymentProvider string
-	err = survey.AskOne(dpPrompt, &deploymentProvider)
+	deploymentRegion, err := deploymentProvider.PromptRegion("")
 	if err != nil {
-		ui.PrintError("Deployment provider selection cancelled")
+		ui.PrintError("Deployment region selection cancelled")
 		return fmt.Errorf("project creation cancelled")
 	}
 
-	config.DeploymentProvider = deploymentProvider
+	config.DeploymentProvider = deploymentProvider.Name()
+	config.DeploymentRegion = deploymentRegion
 
 	ui.PrintStep(5, 6, "Project Creation")
 	ui.PrintInfo(fmt.Sprintf("Creating %s project...", templateName))
@@ -302,6 +296,7 @@ func createAction(c *cli.Context) error {
 		successBox["Package Manager"] = config.PackageManager
 	}
 	successBox["Deployment Provider"] = config.DeploymentProvider
+	successBox["Deployment Region"] = config.DeploymentRegion
 	successBox["Git Initialized"] = fmt.Sprintf("%v", useGit)
 
 	fmt.Println()
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func DeployCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "deploy",
+		Usage:     "Upload the project and deploy it to Squadbase",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			envFlag(),
+			targetFlag(),
+			projectFlag(),
+			accountFlag(),
+			apiURLFlag(),
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "How long to wait for the deployment to finish",
+				Value: 20 * time.Minute,
+			},
+			&cli.BoolFlag{
+				Name:  "no-wait",
+				Usage: "Start the deployment without waiting for it to finish",
+			},
+		},
+		Action: deployAction,
+	}
+}
+
+func deployAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	doc, _, err := readSquadbaseYml(directory, c.String("env"))
+	if err != nil {
+		return err
+	}
+	cfg, err := doc.Decode()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	target, err := cfg.Target(c.String("target"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	// The config stored with the deployment keeps its ${VAR} expressions, so
+	// that values resolved from the environment and .env never leave the
+	// machine.
+	raw, err := config.ReadMergedDocument(directory, c.String("env"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	data, err := raw.Bytes()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	client, err := newAPIClient(c)
+	if err != nil {
+		return err
+	}
+	name := projectName(c, directory)
+
+	b, err := createBundle(directory, "")
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	ui.PrintInfo(fmt.Sprintf("Packaged %d files (%s)", len(b.Files), formatBytes(b.Size)))
+
+	f, err := os.Open(b.Path)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to open bundle: %v", err))
+		return err
+	}
+	defer f.Close()
+
+	uploaded, err := client.UploadBundle(c.Context, name, f, b.Size, b.SHA256)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to upload bundle: %v", err))
+		return err
+	}
+	ui.PrintInfo(fmt.Sprintf("Uploaded bundle %s", uploaded.ID))
+
+	commit, author := project.GetGitCommit(directory)
+	deployment, err := client.CreateDeployment(c.Context, name, &api.CreateDeploymentRequest{
+		BundleID:    uploaded.ID,
+		Target:      target.Name,
+		Environment: c.String("env"),
+		Commit:      commit,
+		Author:      author,
+		Config:      string(data),
+	})
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to start deployment: %v", err))
+		return err
+	}
+	ui.PrintInfo(fmt.Sprintf("Started deployment %s of %s to %s (%s)", deployment.ID, name, target.Name, target.Provider))
+
+	return waitForDeployment(c, client, name, deployment)
+}
+
+// waitForDeployment follows the deployment until it is done, unless --no-wait
+// was given, and reports how it ended.
+func waitForDeployment(c *cli.Context, client *api.Client, name string, deployment *api.Deployment) error {
+	if c.Bool("no-wait") {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context, c.Duration("timeout"))
+	defer cancel()
+	deployment, err := client.WaitForDeployment(ctx, name, deployment.ID, api.DefaultBackoff, printDeploymentUpdate)
+	if errors.Is(err, context.DeadlineExceeded) {
+		ui.PrintWarning(fmt.Sprintf("Stopped waiting after %s. The deployment continues on Squadbase.", c.Duration("timeout")))
+		return err
+	}
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to get deployment status: %v", err))
+		return err
+	}
+
+	switch deployment.Status {
+	case api.StatusReady:
+		ui.PrintSuccess(fmt.Sprintf("Deployed %s to %s", name, deployment.URL))
+		return nil
+	case api.StatusCanceled:
+		ui.PrintWarning(fmt.Sprintf("Deployment %s was canceled", deployment.ID))
+		return fmt.Errorf("deployment canceled")
+	default:
+		ui.PrintErrorBox("❌ Deployment failed", deployment.Message)
+		return fmt.Errorf("deployment failed")
+	}
+}
+
+func printDeploymentUpdate(d *api.Deployment) {
+	status := ui.GetAccentText(d.Status)
+	if d.Message != "" {
+		ui.PrintInfo(fmt.Sprintf("%s: %s", status, d.Message))
+		return
+	}
+	ui.PrintInfo(status)
+}
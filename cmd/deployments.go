@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func DeploymentsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "deployments",
+		Usage: "Inspect the deployment history of the project",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "list",
+				Usage:     "List past deployments, newest first",
+				ArgsUsage: "[DIRECTORY]",
+				Flags: []cli.Flag{
+					targetFlag(),
+					projectFlag(),
+					accountFlag(),
+					apiURLFlag(),
+					&cli.IntFlag{
+						Name:  "limit",
+						Usage: "Number of deployments to list",
+						Value: 20,
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the deployments as JSON",
+					},
+				},
+				Action: deploymentsListAction,
+			},
+		},
+	}
+}
+
+func deploymentsListAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+	client, err := newAPIClient(c)
+	if err != nil {
+		return err
+	}
+	name := projectName(c, directory)
+
+	deployments, err := client.ListDeployments(c.Context, name, api.ListDeploymentsOptions{
+		Target: c.String("target"),
+		Limit:  c.Int("limit"),
+	})
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to list deployments of %s: %v", name, err))
+		return err
+	}
+
+	w := c.App.Writer
+	if c.Bool("json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(deployments)
+	}
+
+	if len(deployments) == 0 {
+		ui.PrintWarning(fmt.Sprintf("%s has not been deployed yet. Run squad deploy first.", name))
+		return nil
+	}
+	fmt.Fprintf(w, "  %-14s %-10s %-12s %-8s %-20s %-13s %s\n", "ID", "STATUS", "TARGET", "COMMIT", "AUTHOR", "CONFIG", "CREATED")
+	for _, d := range deployments {
+		live := " "
+		if d.Live {
+			live = ui.GetSuccessText("●")
+		}
+		fmt.Fprintf(w, "%s %s %s %-12s %-8s %-20s %-13s %s\n",
+			live,
+			ui.GetPrimaryText(fmt.Sprintf("%-14s", d.ID)),
+			deploymentStatusText(fmt.Sprintf("%-10s", d.Status), d.Status),
+			d.Target,
+			shortHash(d.Commit, 7),
+			truncate(d.Author, 20),
+			shortHash(d.ConfigSHA256, 12),
+			d.CreatedAt.Local().Format("2006-01-02 15:04"),
+		)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "%s live deployment. Roll back with squad rollback <ID>.\n", ui.GetSuccessText("●"))
+	return nil
+}
+
+// deploymentStatusText colors text after the deployment status.
+func deploymentStatusText(text string, status string) string {
+	switch status {
+	case api.StatusReady:
+		return ui.GetSuccessText(text)
+	case api.StatusFailed:
+		return ui.GetErrorText(text)
+	case api.StatusCanceled:
+		return ui.GetWarningText(text)
+	default:
+		return ui.GetInfoText(text)
+	}
+}
+
+func shortHash(hash string, length int) string {
+	if hash == "" {
+		return "-"
+	}
+	if len(hash) > length {
+		return hash[:length]
+	}
+	return hash
+}
+
+func truncate(s string, length int) string {
+	if s == "" {
+		return "-"
+	}
+	if len([]rune(s)) > length {
+		return string([]rune(s)[:length-1]) + "…"
+	}
+	return s
+}
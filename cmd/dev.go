@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/dev"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+// devStopGrace is how long the dev server gets to shut down after a signal.
+const devStopGrace = 10 * time.Second
+
+func DevCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "dev",
+		Usage:     "Start the framework's local dev server",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			envFlag(),
+			&cli.DurationFlag{
+				Name:  "poll-interval",
+				Usage: "How often to check the dependency manifests for changes",
+				Value: time.Second,
+			},
+		},
+		Action: devAction,
+	}
+}
+
+func devAction(c *cli.Context) error {
+	if interval := c.Duration("poll-interval"); interval <= 0 {
+		err := fmt.Errorf("invalid --poll-interval %s: use a positive duration such as 1s", interval)
+		ui.PrintError(err.Error())
+		return err
+	}
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadSquadbaseYml(directory, c.String("env"))
+	if err != nil {
+		return err
+	}
+
+	plan, err := dev.NewPlan(directory, cfg.Build)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	dotEnv, err := dotenv.Read(dotenv.Path(directory))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if err := checkRequiredEnv(cfg, envValues(dotEnv, cfg.Env)); err != nil {
+		return err
+	}
+	env := dev.Environ(dotEnv)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(c.Duration("poll-interval"))
+	defer ticker.Stop()
+
+	snapshot := dev.TakeSnapshot(directory, plan.Manifests)
+	for {
+		ui.PrintInfo(fmt.Sprintf("Running %s", strings.Join(plan.Command, " ")))
+		process, err := dev.Start(directory, plan.Command, env, c.App.Writer, c.App.ErrWriter)
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to start %s: %v", plan.Command[0], err))
+			return err
+		}
+
+		restart := false
+		for !restart {
+			select {
+			case sig := <-signals:
+				process.Stop(sig, devStopGrace)
+				return nil
+			case <-process.Done():
+				if err := process.Err(); err != nil {
+					ui.PrintError(fmt.Sprintf("The dev server exited: %v", err))
+					return err
+				}
+				return nil
+			case <-ticker.C:
+				changed := snapshot.Changed(directory, plan.Manifests)
+				if len(changed) == 0 {
+					continue
+				}
+				ui.PrintInfo(fmt.Sprintf("%s changed, restarting the dev server", strings.Join(changed, ", ")))
+				process.Stop(syscall.SIGTERM, devStopGrace)
+				if !installDependencies(c, directory, plan, env, signals) {
+					return nil
+				}
+				// Installing rewrites lock files, which must not trigger another restart.
+				snapshot = dev.TakeSnapshot(directory, plan.Manifests)
+				restart = true
+			}
+		}
+	}
+}
+
+// installDependencies runs the install commands of the plan. It reports
+// false when it was interrupted by a signal.
+func installDependencies(c *cli.Context, directory string, plan *dev.Plan, env []string, signals <-chan os.Signal) bool {
+	for _, install := range plan.Install {
+		ui.PrintInfo(fmt.Sprintf("Running %s", strings.Join(install, " ")))
+		process, err := dev.Start(directory, install, env, c.App.Writer, c.App.ErrWriter)
+		if err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to start %s: %v", install[0], err))
+			continue
+		}
+		select {
+		case sig := <-signals:
+			process.Stop(sig, devStopGrace)
+			return false
+		case <-process.Done():
+			if err := process.Err(); err != nil {
+				ui.PrintWarning(fmt.Sprintf("%s failed: %v", strings.Join(install, " "), err))
+			}
+		}
+	}
+	return true
+}
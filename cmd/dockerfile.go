@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func DockerfileCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "dockerfile",
+		Usage:     "Write a Dockerfile and .dockerignore for the build settings",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			envFlag(),
+			&cli.BoolFlag{
+				Name:    "force",
+				Aliases: []string{"f"},
+				Usage:   "Overwrite an existing Dockerfile and .dockerignore without asking",
+			},
+			&cli.BoolFlag{
+				Name:  "use",
+				Usage: "Also set build.use_custom_dockerfile to true in squadbase.yml",
+			},
+		},
+		Action: dockerfileAction,
+	}
+}
+
+func dockerfileAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadSquadbaseYml(directory, c.String("env"))
+	if err != nil {
+		return err
+	}
+
+	files, err := export.Dockerfile(cfg.Build)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	output := filepath.Join(directory, cfg.Build.Context)
+	existing := []string{}
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Join(output, file.Name)); err == nil {
+			existing = append(existing, file.Name)
+		}
+	}
+	if len(existing) > 0 && !c.Bool("force") {
+		var confirm bool
+		confirmPrompt := &survey.Confirm{
+			Message: fmt.Sprintf("Overwrite the existing %v in %s?", existing, output),
+			Default: false,
+		}
+		err = survey.AskOne(confirmPrompt, &confirm)
+		if err != nil {
+			return fmt.Errorf("dockerfile generation cancelled")
+		}
+		if !confirm {
+			ui.PrintInfo("Dockerfile generation cancelled by user.")
+			return nil
+		}
+	}
+
+	if err := export.WriteFiles(output, files); err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	for _, file := range files {
+		fmt.Fprintf(c.App.Writer, "  %s\n", filepath.Join(output, file.Name))
+	}
+
+	if c.Bool("use") && !cfg.Build.UseCustomDockerfile {
+		doc, err := config.ReadDocument(directory)
+		if err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+		if err := doc.SetValue(true, "build", "use_custom_dockerfile"); err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+		if err := doc.WriteFile(directory); err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to write %s: %v", config.FileName, err))
+			return err
+		}
+		ui.PrintInfo(fmt.Sprintf("Set build.use_custom_dockerfile to true in %s", config.Path(directory)))
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Generated a Dockerfile for %s with %s", cfg.Build.Framework, cfg.Build.PackageManager))
+	if !c.Bool("use") && !cfg.Build.UseCustomDockerfile {
+		ui.PrintInfo("Set build.use_custom_dockerfile to true in squadbase.yml, or run again with --use, to build with it.")
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func EnvCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "env",
+		Usage: "Manage the environment variables declared in squadbase.yml and their values in .env",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the declared variables and whether they have a value",
+				Flags: []cli.Flag{
+					dirFlag(),
+					envFlag(),
+					&cli.BoolFlag{
+						Name:  "show-secrets",
+						Usage: "Print the values of secret variables instead of masking them",
+					},
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "Print the variables as JSON",
+					},
+				},
+				Action: envListAction,
+			},
+			{
+				Name:      "set",
+				Usage:     "Set variables in .env, declaring them in squadbase.yml when needed",
+				ArgsUsage: "NAME[=VALUE]...",
+				Flags: []cli.Flag{
+					dirFlag(),
+					&cli.StringFlag{
+						Name:    "description",
+						Aliases: []string{"d"},
+						Usage:   "Description of the variable",
+					},
+					&cli.BoolFlag{
+						Name:  "required",
+						Usage: "Declare the variable as required",
+					},
+					&cli.BoolFlag{
+						Name:  "secret",
+						Usage: "Declare the variable as secret (default: guessed from the name)",
+					},
+				},
+				Action: envSetAction,
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove variables from .env",
+				ArgsUsage: "NAME...",
+				Flags: []cli.Flag{
+					dirFlag(),
+					&cli.BoolFlag{
+						Name:  "undeclare",
+						Usage: "Also remove the declarations from squadbase.yml",
+					},
+				},
+				Action: envUnsetAction,
+			},
+		},
+	}
+}
+
+func dirFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "dir",
+		Usage: "Project directory (default: the current directory)",
+	}
+}
+
+// dirFlagDirectory returns the directory given with --dir, or the current
+// directory.
+func dirFlagDirectory(c *cli.Context) (string, error) {
+	if directory := c.String("dir"); directory != "" {
+		return directory, nil
+	}
+	directory, err := os.Getwd()
+	if err != nil {
+		ui.PrintError("Failed to get current directory")
+		return "", err
+	}
+	return directory, nil
+}
+
+// envValues returns the values the app sees locally: the .env file,
+// overridden by the environment like squad dev does.
+func envValues(dotEnv map[string]string, vars []config.EnvVar) map[string]string {
+	values := map[string]string{}
+	for key, value := range dotEnv {
+		values[key] = value
+	}
+	for _, v := range vars {
+		if value, ok := os.LookupEnv(v.Name); ok {
+			values[v.Name] = value
+		}
+	}
+	return values
+}
+
+// checkRequiredEnv fails when a required variable has no value in values.
+func checkRequiredEnv(cfg *config.Config, values map[string]string) error {
+	missing := config.MissingEnv(cfg.Env, values)
+	if len(missing) == 0 {
+		return nil
+	}
+	lines := []string{}
+	for _, name := range missing {
+		line := "  " + name
+		if v := cfg.EnvVar(name); v.Description != "" {
+			line += ": " + v.Description
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", "Set them with squad env set NAME=VALUE")
+	ui.PrintErrorBox("❌ Missing required environment variables", strings.Join(lines, "\n"))
+	return fmt.Errorf("missing required environment variables: %s", strings.Join(missing, ", "))
+}
+
+type envListEntry struct {
+	config.EnvVar
+	Declared bool   `json:"declared"`
+	Value    string `json:"value,omitempty"`
+	// Source is where the value comes from: ".env", "environment", or empty
+	// when the variable has no value.
+	Source string `json:"source,omitempty"`
+}
+
+func envListAction(c *cli.Context) error {
+	directory, err := dirFlagDirectory(c)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadSquadbaseYml(directory, c.String("env"))
+	if err != nil {
+		return err
+	}
+	dotEnv, err := dotenv.Read(dotenv.Path(directory))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	entries := []envListEntry{}
+	for _, v := range cfg.Env {
+		entry := envListEntry{EnvVar: v, Declared: true}
+		if value, ok := os.LookupEnv(v.Name); ok {
+			entry.Value, entry.Source = value, "environment"
+		} else if value, ok := dotEnv[v.Name]; ok {
+			entry.Value, entry.Source = value, dotenv.FileName
+		}
+		entries = append(entries, entry)
+	}
+	for _, name := range sortedKeys(dotEnv) {
+		if cfg.EnvVar(name) == nil {
+			entries = append(entries, envListEntry{
+				EnvVar: config.EnvVar{Name: name, Secret: config.LikelySecret(name)},
+				Value:  dotEnv[name],
+				Source: dotenv.FileName,
+			})
+		}
+	}
+	if !c.Bool("show-secrets") {
+		for i := range entries {
+			if entries[i].Secret && entries[i].Value != "" {
+				entries[i].Value = "********"
+			}
+		}
+	}
+
+	w := c.App.Writer
+	if c.Bool("json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			return err
+		}
+	} else {
+		printEnvList(c, entries)
+	}
+
+	return checkRequiredEnv(cfg, envValues(dotEnv, cfg.Env))
+}
+
+func printEnvList(c *cli.Context, entries []envListEntry) {
+	w := c.App.Writer
+	if len(entries) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No variables are declared in %s. Add one with squad env set NAME=VALUE.", config.FileName))
+		return
+	}
+
+	undeclared := 0
+	for _, e := range entries {
+		if !e.Declared {
+			undeclared++
+			continue
+		}
+		value := e.Value
+		switch {
+		case e.Source == "" && e.Required:
+			value = ui.GetErrorText("missing")
+		case e.Source == "":
+			value = ui.GetSecondaryText("not set")
+		case e.Source == "environment":
+			value += ui.GetSecondaryText(" (from the environment)")
+		}
+
+		var flags []string
+		if e.Required {
+			flags = append(flags, "required")
+		}
+		if e.Secret {
+			flags = append(flags, "secret")
+		}
+		name := ui.GetPrimaryText(fmt.Sprintf("%-24s", e.Name))
+		fmt.Fprintf(w, "%s %s\n", name, value)
+		if len(flags) > 0 || e.Description != "" {
+			details := strings.Join(flags, ", ")
+			if e.Description != "" && details != "" {
+				details += " · "
+			}
+			fmt.Fprintf(w, "  %s\n", ui.GetAccentText(details+e.Description))
+		}
+	}
+
+	if undeclared > 0 {
+		fmt.Fprintln(w, "")
+		ui.PrintWarning(fmt.Sprintf("Set in %s but not declared in %s:", dotenv.FileName, config.FileName))
+		for _, e := range entries {
+			if !e.Declared {
+				fmt.Fprintf(w, "  %s\n", e.Name)
+			}
+		}
+		fmt.Fprintf(w, "Declare them with squad env set NAME, or remove them with squad env unset NAME.\n")
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readSquadbaseDocument reads the squadbase.yml of the directory given with
+// --dir for editing, with its decoded settings, after checking that its
+// schema version is supported. Overlays are not merged, so that only the
+// base file is written back.
+func readSquadbaseDocument(c *cli.Context) (string, *config.Document, *config.Config, error) {
+	directory, err := dirFlagDirectory(c)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	doc, err := config.ReadDocument(directory)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return "", nil, nil, err
+	}
+	if err := checkSquadbaseYmlVersion(doc.GetString("version")); err != nil {
+		return "", nil, nil, err
+	}
+	cfg, err := doc.Decode()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return "", nil, nil, err
+	}
+	return directory, doc, cfg, nil
+}
+
+// writeEnvDeclarations stores the declared variables in squadbase.yml and
+// regenerates .env.example.
+func writeEnvDeclarations(directory string, doc *config.Document, vars []config.EnvVar) error {
+	if len(vars) == 0 {
+		doc.Delete("env")
+	} else if err := doc.SetValue(vars, "env"); err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if err := doc.WriteFile(directory); err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to write %s: %v", config.FileName, err))
+		return err
+	}
+	written, err := config.WriteEnvExample(directory, vars)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if written {
+		ui.PrintInfo(fmt.Sprintf("Updated %s", config.EnvExampleFileName))
+	}
+	return nil
+}
+
+func envSetAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		err := fmt.Errorf("expected NAME=VALUE or NAME")
+		ui.PrintError(err.Error())
+		return err
+	}
+	directory, doc, cfg, err := readSquadbaseDocument(c)
+	if err != nil {
+		return err
+	}
+	vars := cfg.Env
+
+	declared := false
+	for _, arg := range c.Args().Slice() {
+		name, value, hasValue := strings.Cut(arg, "=")
+		if !dotenv.IsValidKey(name) {
+			err := fmt.Errorf("invalid variable name %q", name)
+			ui.PrintError(err.Error())
+			return err
+		}
+
+		index := -1
+		for i := range vars {
+			if vars[i].Name == name {
+				index = i
+			}
+		}
+		if index < 0 {
+			vars = append(vars, config.EnvVar{Name: name, Secret: config.LikelySecret(name)})
+			index = len(vars) - 1
+			declared = true
+		}
+		v := &vars[index]
+		if c.IsSet("description") {
+			v.Description = c.String("description")
+			declared = true
+		}
+		if c.IsSet("required") {
+			v.Required = c.Bool("required")
+			declared = true
+		}
+		if c.IsSet("secret") {
+			v.Secret = c.Bool("secret")
+			declared = true
+		}
+
+		if !hasValue {
+			if value, err = promptEnvValue(v); err != nil {
+				return err
+			}
+		}
+		if err := dotenv.Set(dotenv.Path(directory), name, value); err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+		ui.PrintSuccess(fmt.Sprintf("Set %s in %s", name, dotenv.FileName))
+	}
+
+	if declared {
+		return writeEnvDeclarations(directory, doc, vars)
+	}
+	return nil
+}
+
+func promptEnvValue(v *config.EnvVar) (string, error) {
+	message := fmt.Sprintf("Value of %s:", v.Name)
+	var value string
+	var prompt survey.Prompt = &survey.Input{Message: message, Help: v.Description}
+	if v.Secret {
+		prompt = &survey.Password{Message: message, Help: v.Description}
+	}
+	if err := survey.AskOne(prompt, &value); err != nil {
+		return "", fmt.Errorf("cancelled")
+	}
+	return value, nil
+}
+
+func envUnsetAction(c *cli.Context) error {
+	if c.NArg() == 0 {
+		err := fmt.Errorf("expected at least one variable name")
+		ui.PrintError(err.Error())
+		return err
+	}
+	directory, doc, cfg, err := readSquadbaseDocument(c)
+	if err != nil {
+		return err
+	}
+	vars := cfg.Env
+
+	undeclared := false
+	for _, name := range c.Args().Slice() {
+		removed, err := dotenv.Unset(dotenv.Path(directory), name)
+		if err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+		if removed {
+			ui.PrintSuccess(fmt.Sprintf("Removed %s from %s", name, dotenv.FileName))
+		} else {
+			ui.PrintInfo(fmt.Sprintf("%s is not set in %s", name, dotenv.FileName))
+		}
+
+		if c.Bool("undeclare") {
+			for i := range vars {
+				if vars[i].Name == name {
+					vars = append(vars[:i], vars[i+1:]...)
+					undeclared = true
+					ui.PrintSuccess(fmt.Sprintf("Removed the declaration of %s from %s", name, config.FileName))
+					break
+				}
+			}
+		}
+	}
+
+	if undeclared {
+		return writeEnvDeclarations(directory, doc, vars)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func ExportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "export",
+		Usage: "Generate infrastructure definitions from squadbase.yml",
+		Subcommands: []*cli.Command{
+			exportSubcommand("terraform", "Write a Terraform module for the deployment", "terraform", export.Terraform),
+			exportSubcommand("sam", "Write an AWS SAM template.yaml for the deployment", "sam", export.SAMTemplate),
+			exportSubcommand("cloudrun", "Write a Cloud Run service.yaml for gcloud run services replace", "cloudrun", export.CloudRunService,
+				imageFlag(),
+				&cli.StringFlag{
+					Name:  "previous-revision",
+					Usage: "Name of the revision that traffic.split calls previous",
+				},
+			),
+			exportK8sSubcommand(),
+		},
+	}
+}
+
+func exportSubcommand(name string, usage string, defaultOutput string, generate func(*export.App) ([]export.File, error), flags ...cli.Flag) *cli.Command {
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		ArgsUsage: "[DIRECTORY]",
+		Flags:     exportFlags(defaultOutput, flags...),
+		Action: func(c *cli.Context) error {
+			return exportAction(c, defaultOutput, generate)
+		},
+	}
+}
+
+func exportK8sSubcommand() *cli.Command {
+	return &cli.Command{
+		Name:      "k8s",
+		Usage:     "Write Kubernetes manifests, or a Helm chart with --helm",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: exportFlags("k8s",
+			imageFlag(),
+			&cli.BoolFlag{
+				Name:  "helm",
+				Usage: "Write a Helm chart instead of plain manifests",
+			},
+		),
+		Action: func(c *cli.Context) error {
+			if c.Bool("helm") {
+				return exportAction(c, "k8s", export.HelmChart)
+			}
+			return exportAction(c, "k8s", export.KubernetesManifests)
+		},
+	}
+}
+
+func exportFlags(defaultOutput string, flags ...cli.Flag) []cli.Flag {
+	return append([]cli.Flag{
+		envFlag(),
+		targetFlag(),
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   fmt.Sprintf("Directory to write to (default: DIRECTORY/%s)", defaultOutput),
+		},
+	}, flags...)
+}
+
+func imageFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "image",
+		Usage: "Container image to deploy (default: a placeholder to fill in)",
+	}
+}
+
+func exportAction(c *cli.Context, defaultOutput string, generate func(*export.App) ([]export.File, error)) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	cfg, target, err := loadDeploymentTarget(c, directory)
+	if err != nil {
+		return err
+	}
+
+	app, err := export.NewApp(export.AppName(directory), cfg, target)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if c.IsSet("image") {
+		app.Image = c.String("image")
+	}
+	if c.IsSet("previous-revision") {
+		app.PreviousRevision = c.String("previous-revision")
+	}
+
+	output := c.String("output")
+	if output == "" {
+		output = filepath.Join(directory, defaultOutput)
+	}
+	if rel, err := filepath.Rel(output, directory); err == nil {
+		app.ProjectPath = filepath.ToSlash(rel)
+	}
+
+	files, err := generate(app)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	if err := export.WriteFiles(output, files); err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	for _, file := range files {
+		fmt.Fprintf(c.App.Writer, "  %s\n", filepath.Join(output, file.Name))
+	}
+	ui.PrintSuccess(fmt.Sprintf("Exported %s for target %q (%s)", c.Command.Name, target.Name, target.Provider))
+	return nil
+}
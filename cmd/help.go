@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/bundle"
+	"github.com/squadbase/squadbase/internal/container"
+	"github.com/squadbase/squadbase/internal/services"
 	"github.com/squadbase/squadbase/internal/ui"
 	"github.com/urfave/cli/v2"
 )
@@ -40,6 +45,29 @@ func ShowHelp(c *cli.Context) error {
 	commandsInfo := make(map[string]string)
 	commandsInfo["create [PROJECT_NAME]"] = "Create a new project from a template"
 	commandsInfo["init [DIRECTORY]"] = "Initialize an existing directory with squadbase.yml"
+	commandsInfo["migrate [DIRECTORY]"] = "Upgrade squadbase.yml to the current schema version"
+	commandsInfo["render [DIRECTORY]"] = "Print squadbase.yml with overlays merged and variables resolved"
+	commandsInfo["cost [DIRECTORY]"] = "Estimate the monthly cost of the configured deployment"
+	commandsInfo["export terraform [DIRECTORY]"] = "Generate a Terraform module from squadbase.yml"
+	commandsInfo["export cloudrun [DIRECTORY]"] = "Generate a Cloud Run service.yaml from squadbase.yml"
+	commandsInfo["export sam [DIRECTORY]"] = "Generate an AWS SAM template.yaml from squadbase.yml"
+	commandsInfo["export k8s [DIRECTORY]"] = "Generate Kubernetes manifests or a Helm chart from squadbase.yml"
+	commandsInfo["dockerfile [DIRECTORY]"] = "Generate a Dockerfile and .dockerignore from the build settings"
+	commandsInfo["build [DIRECTORY]"] = "Build the container image locally with docker or podman"
+	commandsInfo["run [DIRECTORY]"] = "Build and run the app locally with the deployment's resource limits"
+	commandsInfo["dev [DIRECTORY]"] = "Start the framework's local dev server"
+	commandsInfo["package [DIRECTORY]"] = "Write a reproducible tar.gz bundle of the project"
+	commandsInfo["deploy [DIRECTORY]"] = "Upload the project and deploy it to Squadbase"
+	commandsInfo["login"] = "Log in to Squadbase with a code confirmed in the browser"
+	commandsInfo["logout"] = "Remove the stored credentials of an account"
+	commandsInfo["whoami"] = "Show the identity API commands authenticate as"
+	commandsInfo["status [DIRECTORY]"] = "Show what runs on each deployment target and whether it is healthy"
+	commandsInfo["logs [DIRECTORY]"] = "Show or follow the runtime logs of the deployed app"
+	commandsInfo["deployments list [DIRECTORY]"] = "List past deployments with their commit, author, config hash and status"
+	commandsInfo["rollback <ID> [DIRECTORY]"] = "Promote an earlier deployment back to its target"
+	commandsInfo["env list|set|unset"] = "Manage the declared environment variables and their values in .env"
+	commandsInfo["secrets edit|encrypt|decrypt|exec"] = "Manage the encrypted secrets file and run commands with its values"
+	commandsInfo["service add|up|down"] = "Manage backing services such as Postgres and run them locally"
 	commandsInfo["help [COMMAND]"] = "Show help information"
 
 	ui.PrintSummaryBox("💻 Available Commands", commandsInfo)
@@ -113,8 +141,494 @@ func showCommandHelp(c *cli.Context, commandName string) error {
 		fmt.Fprintln(w, "  1. Specify a directory (or use current directory)")
 		fmt.Fprintln(w, "  2. Select a template")
 		fmt.Fprintln(w, "  3. Configure runtime and package manager settings")
-		fmt.Fprintln(w, "  4. Select deployment provider")
+		fmt.Fprintln(w, "  4. Select deployment provider (aws, gcp or azure) and region")
+		fmt.Fprintln(w, "     (for an existing squadbase.yml, pick the deployment target to update or add a new one)")
 		fmt.Fprintln(w, "  5. A squadbase.yml file will be created in the specified directory")
+		fmt.Fprintln(w, "     (an existing squadbase.yml is updated in place after showing a diff)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Deployment targets:"))
+		fmt.Fprintln(w, "  To deploy one app to several providers or regions, list named targets under")
+		fmt.Fprintln(w, "  deployment.targets, each with its own provider and settings. Commands that read the")
+		fmt.Fprintln(w, "  deployment accept --target NAME; without it, deployment.default_target or the first")
+		fmt.Fprintln(w, "  target is used. Adding a target moves an existing single deployment to a target named default.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Traffic splitting:"))
+		fmt.Fprintln(w, "  The traffic block splits requests between the latest and previous revisions (or named")
+		fmt.Fprintln(w, "  revisions), tags the latest revision for a preview URL and sets a rollback threshold.")
+		fmt.Fprintln(w, "  It maps to Lambda alias weights on aws and Cloud Run traffic on gcp.")
+		fmt.Fprintln(w, "")
+
+	case "migrate":
+		fmt.Fprintf(w, "\n%s\n\n", green("MIGRATE COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad migrate [DIRECTORY]"))
+		fmt.Fprintln(w, "Upgrade squadbase.yml to the current schema version in place.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --dry-run: Show the changes without writing them")
+		fmt.Fprintln(w, "  --yes, -y: Write the changes without asking for confirmation")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Preview the migration of the current project"))
+		fmt.Fprintln(w, "  squad migrate --dry-run")
+		fmt.Fprintln(w, "")
+
+	case "render":
+		fmt.Fprintf(w, "\n%s\n\n", green("RENDER COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad render [DIRECTORY] [--env ENV]"))
+		fmt.Fprintln(w, "Print squadbase.yml as the CLI sees it, with the environment overlay deep-merged onto it")
+		fmt.Fprintln(w, "and ${VAR} expressions resolved. Each resolved value is annotated with where it came from.")
+		fmt.Fprintln(w, "Resource presets (small, medium, large) are expanded, and every setting is checked against")
+		fmt.Fprintln(w, "the provider's limits (e.g. Lambda memory 128-10240 MB, Cloud Run CPU/memory pairings).")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --no-sources: Do not annotate interpolated values")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Overlays:"))
+		fmt.Fprintln(w, "  Mappings are merged key by key; scalars and lists replace the base value.")
+		fmt.Fprintln(w, "  Set a key to null in the overlay to remove it.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Variables:"))
+		fmt.Fprintln(w, "  ${VAR} is required; ${VAR:-default} falls back to default when VAR is unset or empty.")
+		fmt.Fprintln(w, "  Values come from the environment first, then from the project's .env file. Use $$ for a literal $.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Show what the staging environment gets"))
+		fmt.Fprintln(w, "  squad render --env staging")
+		fmt.Fprintln(w, "")
+
+	case "cost":
+		fmt.Fprintf(w, "\n%s\n\n", green("COST COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad cost [--requests-per-month N] [--avg-duration D] [--target NAME] [DIRECTORY]"))
+		fmt.Fprintln(w, "Estimate the monthly cost of the deployment configured in squadbase.yml from the expected")
+		fmt.Fprintln(w, "traffic. The estimate is itemized (compute, requests, storage, always-on instances) and")
+		fmt.Fprintln(w, "compared side by side with the provider's small, medium and large presets.")
+		fmt.Fprintln(w, "Prices come from a table bundled with the CLI, so no network access is needed.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --requests-per-month, -r: Expected number of requests per month (required)")
+		fmt.Fprintln(w, "  --avg-duration, -d: Average request duration (default: 300ms)")
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --target, -t: Deployment target to estimate (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --json: Print the estimates as JSON")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Estimate one million 300ms requests a month"))
+		fmt.Fprintln(w, "  squad cost --requests-per-month 1000000 --avg-duration 300ms")
+		fmt.Fprintln(w, "")
+
+	case "export":
+		fmt.Fprintf(w, "\n%s\n\n", green("EXPORT COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad export <FORMAT> [--target NAME] [--output DIR] [DIRECTORY]"))
+		fmt.Fprintln(w, "Generate infrastructure definitions for a deployment target from squadbase.yml.")
+		fmt.Fprintln(w, "The output is deterministic, so it can be checked in and diffed.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Formats:"))
+		fmt.Fprintln(w, "  terraform: A Terraform module (main.tf, variables.tf, outputs.tf) with a Lambda function")
+		fmt.Fprintln(w, "             for aws or a Cloud Run service for gcp. The image URI, project ID and other")
+		fmt.Fprintln(w, "             values the CLI cannot know are variables.")
+		fmt.Fprintln(w, "  cloudrun:  A Knative service.yaml for gcp targets that gcloud run services replace accepts.")
+		fmt.Fprintln(w, "             An explicit ephemeral_storage becomes an in-memory volume mounted at /tmp; its")
+		fmt.Fprintln(w, "             space comes out of the memory limit, so raise memory to match.")
+		fmt.Fprintln(w, "  sam:       An AWS SAM template.yaml with an image function, function URL and provisioned")
+		fmt.Fprintln(w, "             concurrency for aws targets. Region and build args become parameters. The image")
+		fmt.Fprintln(w, "             must handle Lambda invocations, e.g. with the AWS Lambda Web Adapter.")
+		fmt.Fprintln(w, "  k8s:       Deployment, Service and HorizontalPodAutoscaler manifests, or a Helm chart with")
+		fmt.Fprintln(w, "             --helm. Resources become requests and limits, and the framework sets the port.")
+		fmt.Fprintln(w, "             Concurrency drives autoscaling only in the Helm chart; hpa.yaml carries it as a")
+		fmt.Fprintln(w, "             commented-out Pods metric to enable once a metrics adapter serves one.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --target, -t: Deployment target to export (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --output, -o: Directory to write to (default: DIRECTORY/<FORMAT>)")
+		fmt.Fprintln(w, "  --image: Container image to deploy (cloudrun, k8s)")
+		fmt.Fprintln(w, "  --helm: Write a Helm chart instead of plain manifests (k8s)")
+		fmt.Fprintln(w, "  --previous-revision: Revision that traffic.split calls previous (cloudrun)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Write the production target as a Terraform module to infra/app"))
+		fmt.Fprintln(w, "  squad export terraform --target production --output infra/app")
+		fmt.Fprintf(w, "  %s\n", blue("# Deploy the tokyo target with gcloud"))
+		fmt.Fprintln(w, "  squad export cloudrun --target tokyo --image us-docker.pkg.dev/my-project/apps/app:v1")
+		fmt.Fprintln(w, "  gcloud run services replace cloudrun/service.yaml --region asia-northeast1")
+		fmt.Fprintln(w, "")
+
+	case "dockerfile":
+		fmt.Fprintf(w, "\n%s\n\n", green("DOCKERFILE COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad dockerfile [--force] [--use] [DIRECTORY]"))
+		fmt.Fprintln(w, "Write a multi-stage Dockerfile and a .dockerignore for the framework, runtime and package")
+		fmt.Fprintln(w, "manager in the build settings of squadbase.yml. Dependencies are installed before the sources")
+		fmt.Fprintln(w, "are copied so that the dependency layers stay cached. The files are written to build.context.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Supported builds:"))
+		fmt.Fprintln(w, "  streamlit, morph: python3.9 to python3.12 with poetry, uv or pip")
+		fmt.Fprintln(w, "  nextjs:           nodejs16 to nodejs20 with npm, yarn or pnpm")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "build.build_args become ARG instructions; those listed in build.runtime_args are also set as")
+		fmt.Fprintln(w, "environment variables of the app, and so end up in the image config. For streamlit,")
+		fmt.Fprintln(w, "build.entrypoint names the script to run (default: main.py).")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --force, -f: Overwrite existing files without asking")
+		fmt.Fprintln(w, "  --use: Also set build.use_custom_dockerfile to true in squadbase.yml")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Start from a generated Dockerfile and build with it from now on"))
+		fmt.Fprintln(w, "  squad dockerfile --use")
+		fmt.Fprintln(w, "")
+
+	case "build":
+		fmt.Fprintf(w, "\n%s\n\n", green("BUILD COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad build [--tag NAME:TAG] [--platform PLATFORM] [--engine ENGINE] [DIRECTORY]"))
+		fmt.Fprintln(w, "Build the container image from squadbase.yml with the local docker or podman CLI, the way the")
+		fmt.Fprintln(w, "platform builds it. With build.use_custom_dockerfile, the Dockerfile in build.context is used;")
+		fmt.Fprintln(w, "otherwise one is generated like squad dockerfile does. build.build_args are passed as --build-arg.")
+		fmt.Fprintln(w, "The build output is streamed, and the error lines are summarized when the build fails.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --tag, -t: Name and tag of the image, can be repeated (default: <project>:latest)")
+		fmt.Fprintf(w, "  --platform: Platform to build for (default: %s)\n", container.DefaultPlatform)
+		fmt.Fprintln(w, "  --no-cache: Build without using cached layers")
+		fmt.Fprintln(w, "  --engine: docker or podman (or $SQUAD_CONTAINER_ENGINE, default: the first one installed)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Build for Apple silicon instead of the platform's linux/amd64"))
+		fmt.Fprintln(w, "  squad build --tag dashboard:dev --platform linux/arm64")
+		fmt.Fprintln(w, "")
+
+	case "run":
+		fmt.Fprintf(w, "\n%s\n\n", green("RUN COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad run [--target NAME] [--port PORT] [--engine ENGINE] [DIRECTORY]"))
+		fmt.Fprintln(w, "Build the image like squad build and run it with the memory and CPU limits of the deployment")
+		fmt.Fprintln(w, "target. Variables from .env are passed to the app, and the app is probed until it is healthy.")
+		fmt.Fprintln(w, "Requests go through a local proxy that cuts them off after the target's request timeout.")
+		fmt.Fprintln(w, "When the app runs out of memory or a request times out, the squadbase.yml setting is named.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --target, -t: Deployment target whose limits to apply (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --port, -p: Local port to serve the app on (default: the framework's port)")
+		fmt.Fprintf(w, "  --platform: Platform to build and run (default: %s)\n", container.DefaultPlatform)
+		fmt.Fprintln(w, "  --health-timeout: How long to wait for the app to become healthy (default: 60s)")
+		fmt.Fprintln(w, "  --engine: docker or podman (or $SQUAD_CONTAINER_ENGINE, default: the first one installed)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintf(w, "  %s\n", blue("# Check that the app fits the production target's limits"))
+		fmt.Fprintln(w, "  squad run --target production")
+		fmt.Fprintln(w, "")
+
+	case "dev":
+		fmt.Fprintf(w, "\n%s\n\n", green("DEV COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad dev [DIRECTORY]"))
+		fmt.Fprintln(w, "Start the framework's dev server through the package manager in squadbase.yml:")
+		fmt.Fprintln(w, "  streamlit: poetry run / uv run streamlit run <entrypoint> (pip: the project's .venv)")
+		fmt.Fprintln(w, "  morph:     poetry run / uv run morph serve")
+		fmt.Fprintln(w, "  nextjs:    npm run dev, yarn dev or pnpm dev")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Variables from .env are added to the environment without overriding variables that are")
+		fmt.Fprintln(w, "already set. Ctrl+C is forwarded to the dev server. When a dependency manifest such as")
+		fmt.Fprintln(w, "pyproject.toml or package.json changes, the dependencies are installed and the server restarts.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --poll-interval: How often to check the dependency manifests for changes (default: 1s)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad dev")
+		fmt.Fprintln(w, "")
+
+	case "package":
+		fmt.Fprintf(w, "\n%s\n\n", green("PACKAGE COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad package [--output FILE] [--top N] [--json] [DIRECTORY]"))
+		fmt.Fprintln(w, "Write the project as a tar.gz bundle. Files are sorted, modification times are zeroed and")
+		fmt.Fprintln(w, "owners are dropped, so the same sources always give the same bundle. The bundle starts with a")
+		fmt.Fprintf(w, "%s file that lists the sha256 hash of every file.\n", bundle.ManifestName)
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "Files matching %s are left out. It uses gitignore syntax and comes after these defaults:\n", bundle.IgnoreFileName)
+		fmt.Fprintf(w, "  %s\n", strings.Join(bundle.DefaultIgnores, " "))
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --output, -o: Path of the bundle (default: DIRECTORY/.squad/<project>.tar.gz)")
+		fmt.Fprintln(w, "  --top: Number of largest files to list (default: 10)")
+		fmt.Fprintln(w, "  --json: Print the bundle and its files as JSON")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad package --output dist/app.tar.gz")
+		fmt.Fprintln(w, "")
+
+	case "deploy":
+		fmt.Fprintf(w, "\n%s\n\n", green("DEPLOY COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad deploy [--target NAME] [--project NAME] [--no-wait] [DIRECTORY]"))
+		fmt.Fprintln(w, "Package the project like squad package, upload the bundle and deploy it with the validated")
+		fmt.Fprintln(w, "squadbase.yml. The deployment is polled until it is ready or fails.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --target, -t: Deployment target to deploy to (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --project: Squadbase project name (or $SQUAD_PROJECT, default: the directory's name)")
+		fmt.Fprintln(w, "  --account, -a: Logged in account to deploy with (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "  --timeout: How long to wait for the deployment to finish (default: 20m)")
+		fmt.Fprintln(w, "  --no-wait: Start the deployment without waiting for it to finish")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "Log in with squad login, or set $SQUAD_TOKEN to an API token in CI.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad deploy --env production --target tokyo")
+		fmt.Fprintln(w, "")
+
+	case "login":
+		fmt.Fprintf(w, "\n%s\n\n", green("LOGIN COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad login [--account NAME]"))
+		fmt.Fprintln(w, "Print a code and a URL to confirm it at, then wait until the code is confirmed in the browser.")
+		fmt.Fprintln(w, "The token is stored in the user config directory, readable only by you, under the account name")
+		fmt.Fprintln(w, "and becomes the current account.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --account, -a: Name to store the login under (or $SQUAD_ACCOUNT, default: the current account or \"default\")")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "$SQUAD_TOKEN, when set, is used by every API command instead of the stored accounts.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad login --account work")
+		fmt.Fprintln(w, "")
+
+	case "logout":
+		fmt.Fprintf(w, "\n%s\n\n", green("LOGOUT COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad logout [--account NAME] [--all]"))
+		fmt.Fprintln(w, "Remove the stored token of the current account, or of the given one.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --account, -a: Account to log out of (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintln(w, "  --all: Log out of every account")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad logout --all")
+		fmt.Fprintln(w, "")
+
+	case "whoami":
+		fmt.Fprintf(w, "\n%s\n\n", green("WHOAMI COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad whoami [--account NAME] [--json]"))
+		fmt.Fprintln(w, "Show the user, account and API that API commands authenticate with.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --account, -a: Account to show (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "  --json: Print the identity as JSON")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad whoami")
+		fmt.Fprintln(w, "")
+
+	case "status":
+		fmt.Fprintf(w, "\n%s\n\n", green("STATUS COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad status [--target NAME] [--json] [DIRECTORY]"))
+		fmt.Fprintln(w, "Show the running revision, URL, provider, region, instances and health of every deployment target.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --target, -t: Only show this deployment target (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --project: Squadbase project name (or $SQUAD_PROJECT, default: the directory's name)")
+		fmt.Fprintln(w, "  --account, -a: Logged in account to use (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "  --json: Print the status as JSON")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad status --target production")
+		fmt.Fprintln(w, "")
+
+	case "logs":
+		fmt.Fprintf(w, "\n%s\n\n", green("LOGS COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad logs [--follow] [--since TIME] [--until TIME] [--severity LEVEL] [--json] [DIRECTORY]"))
+		fmt.Fprintln(w, "Show the runtime logs of the deployed app. With --follow, new entries are streamed until you")
+		fmt.Fprintln(w, "press Ctrl+C, and a dropped connection is reopened where it left off.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --follow, -f: Keep streaming new log entries")
+		fmt.Fprintln(w, "  --since: Show entries from this time on, as a duration (1h) or a timestamp (2024-05-01T09:00:00Z)")
+		fmt.Fprintln(w, "  --until: Show entries up to this time, as a duration or a timestamp. Cannot be used with --follow")
+		fmt.Fprintln(w, "  --severity, -s: Least severity to show: debug, info, warning or error")
+		fmt.Fprintln(w, "  --json: Print one JSON object per entry")
+		fmt.Fprintln(w, "  --target, -t: Deployment target to read the logs of (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --project: Squadbase project name (or $SQUAD_PROJECT, default: the directory's name)")
+		fmt.Fprintln(w, "  --account, -a: Logged in account to use (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad logs --follow --since 15m --severity warning")
+		fmt.Fprintln(w, "")
+
+	case "deployments":
+		fmt.Fprintf(w, "\n%s\n\n", green("DEPLOYMENTS COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad deployments list [--target NAME] [--limit N] [--json] [DIRECTORY]"))
+		fmt.Fprintln(w, "List past deployments, newest first, with the git commit and author they were made from,")
+		fmt.Fprintln(w, "the hash of their squadbase.yml and their status. The live deployment of each target is marked.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --target, -t: Only list deployments to this target (or $SQUAD_TARGET)")
+		fmt.Fprintln(w, "  --limit: Number of deployments to list (default: 20)")
+		fmt.Fprintln(w, "  --json: Print the deployments as JSON")
+		fmt.Fprintln(w, "  --project: Squadbase project name (or $SQUAD_PROJECT, default: the directory's name)")
+		fmt.Fprintln(w, "  --account, -a: Logged in account to use (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad deployments list --target production")
+		fmt.Fprintln(w, "")
+
+	case "rollback":
+		fmt.Fprintf(w, "\n%s\n\n", green("ROLLBACK COMMAND"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad rollback [--yes] [--no-wait] <DEPLOYMENT_ID> [DIRECTORY]"))
+		fmt.Fprintln(w, "Deploy the bundle and squadbase.yml of an earlier deployment again. The commit and a diff")
+		fmt.Fprintln(w, "between the live and the earlier squadbase.yml are shown before asking for confirmation.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Arguments:"))
+		fmt.Fprintln(w, "  DEPLOYMENT_ID: The deployment to roll back to, from squad deployments list")
+		fmt.Fprintln(w, "  DIRECTORY: (Optional) The project directory. If not provided, the current directory will be used.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --yes, -y: Roll back without asking for confirmation")
+		fmt.Fprintln(w, "  --timeout: How long to wait for the rollback to finish (default: 20m)")
+		fmt.Fprintln(w, "  --no-wait: Start the rollback without waiting for it to finish")
+		fmt.Fprintln(w, "  --project: Squadbase project name (or $SQUAD_PROJECT, default: the directory's name)")
+		fmt.Fprintln(w, "  --account, -a: Logged in account to use (or $SQUAD_ACCOUNT, default: the current account)")
+		fmt.Fprintf(w, "  --api-url: Base URL of the Squadbase API (or $SQUAD_API_URL, default: %s)\n", api.DefaultBaseURL)
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad rollback dep_41")
+		fmt.Fprintln(w, "")
+
+	case "env":
+		fmt.Fprintf(w, "\n%s\n\n", green("ENV COMMAND"))
+		fmt.Fprintf(w, "%s\n", bold("squad env list [--show-secrets] [--json]"))
+		fmt.Fprintf(w, "%s\n", bold("squad env set [--required] [--secret] [--description TEXT] NAME[=VALUE]..."))
+		fmt.Fprintf(w, "%s\n\n", bold("squad env unset [--undeclare] NAME..."))
+		fmt.Fprintln(w, "The variables the app reads are declared in the env section of squadbase.yml with a name,")
+		fmt.Fprintln(w, "a description, and whether they are required or secret. Their local values live in .env.")
+		fmt.Fprintln(w, "squad env list, squad run and squad dev fail while a required variable has no value.")
+		fmt.Fprintln(w, "Changing the declarations regenerates .env.example, unless it was written by hand.")
+		fmt.Fprintln(w, "set and unset edit squadbase.yml itself, never an environment overlay.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Subcommands:"))
+		fmt.Fprintln(w, "  list: Show the declared variables, their values (secrets masked) and variables set in .env but not declared")
+		fmt.Fprintln(w, "  set: Write values to .env, declaring new variables. Without =VALUE the value is asked for")
+		fmt.Fprintln(w, "  unset: Remove values from .env")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --env, -e: (list) Environment overlay to apply, read from squadbase.<ENV>.yml (or $SQUAD_ENV)")
+		fmt.Fprintln(w, "  --show-secrets: (list) Print the values of secret variables")
+		fmt.Fprintln(w, "  --json: (list) Print the variables as JSON")
+		fmt.Fprintln(w, "  --required: (set) Declare the variables as required")
+		fmt.Fprintln(w, "  --secret: (set) Declare the variables as secret (default: guessed from the name)")
+		fmt.Fprintln(w, "  --description, -d: (set) Description of the variables")
+		fmt.Fprintln(w, "  --undeclare: (unset) Also remove the declarations from squadbase.yml")
+		fmt.Fprintln(w, "  --dir: Project directory (default: the current directory)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad env set --required -d \"Key of the OpenAI API\" OPENAI_API_KEY")
+		fmt.Fprintln(w, "")
+
+	case "secrets":
+		fmt.Fprintf(w, "\n%s\n\n", green("SECRETS COMMAND"))
+		fmt.Fprintf(w, "%s\n", bold("squad secrets keygen [--output FILE]"))
+		fmt.Fprintf(w, "%s\n", bold("squad secrets edit [--recipient KEY]... [--passphrase]"))
+		fmt.Fprintf(w, "%s\n", bold("squad secrets encrypt [--recipient KEY]... [--passphrase] FILE"))
+		fmt.Fprintf(w, "%s\n", bold("squad secrets decrypt [--format yaml|env|json] [--output FILE]"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad secrets exec -- COMMAND [ARGS...]"))
+		fmt.Fprintln(w, "Secrets are kept in secrets.enc.yml next to squadbase.yml, safe to commit. Names stay readable and")
+		fmt.Fprintln(w, "every value is encrypted on its own, so a diff shows which secrets changed but not their values.")
+		fmt.Fprintln(w, "The file is encrypted to recipient keys made with squad secrets keygen, to a passphrase, or both.")
+		fmt.Fprintln(w, "Everything runs offline.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Subcommands:"))
+		fmt.Fprintln(w, "  keygen: Write a key to the user config directory and print its recipient")
+		fmt.Fprintln(w, "  edit: Open the decrypted secrets in $VISUAL or $EDITOR and encrypt them on save")
+		fmt.Fprintln(w, "  encrypt: Encrypt the values of a YAML or .env file, replacing the current secrets")
+		fmt.Fprintln(w, "  decrypt: Print the decrypted secrets")
+		fmt.Fprintln(w, "  exec: Run a command with the secrets in its environment. Variables already set take precedence")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --recipient, -r: (edit, encrypt) Also encrypt to this recipient")
+		fmt.Fprintln(w, "  --passphrase: (edit, encrypt) Also encrypt with a passphrase")
+		fmt.Fprintln(w, "  --identity, -i: Key file to decrypt with (default: the key written by keygen)")
+		fmt.Fprintln(w, "  --format, -f: (decrypt) Output format: yaml, env or json")
+		fmt.Fprintln(w, "  --output, -o: (keygen, decrypt) File to write")
+		fmt.Fprintln(w, "  --dir: Project directory (default: the current directory)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Environment:"))
+		fmt.Fprintln(w, "  SQUAD_SECRETS_KEY: Key to decrypt with, for example in CI")
+		fmt.Fprintln(w, "  SQUAD_SECRETS_PASSPHRASE: Passphrase to use instead of prompting")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad secrets exec -- squad dev")
+		fmt.Fprintln(w, "")
+
+	case "service":
+		fmt.Fprintf(w, "\n%s\n\n", green("SERVICE COMMAND"))
+		fmt.Fprintf(w, "%s\n", bold("squad service add [--name NAME] [--version VERSION] [--port PORT] TYPE"))
+		fmt.Fprintf(w, "%s\n", bold("squad service up"))
+		fmt.Fprintf(w, "%s\n\n", bold("squad service down [--volumes]"))
+		fmt.Fprintln(w, "Backing services are listed in the services section of squadbase.yml. squad service add records")
+		fmt.Fprintln(w, "a service, generates compose.yaml with a healthcheck and a data volume for every service, and")
+		fmt.Fprintln(w, "sets its connection URL in .env. A compose.yaml written by hand is left alone.")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Subcommands:"))
+		fmt.Fprintf(w, "  add: Add a service of type %s\n", strings.Join(services.Types(), " or "))
+		fmt.Fprintln(w, "  up: Start the services with docker or podman compose and wait until they are healthy")
+		fmt.Fprintln(w, "  down: Stop the services")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Options:"))
+		fmt.Fprintln(w, "  --name: (add) Name of the service (default: the type)")
+		fmt.Fprintln(w, "  --version: (add) Image version (default: the latest supported major version)")
+		fmt.Fprintln(w, "  --port: (add) Local port to publish the service on (default: the service's port)")
+		fmt.Fprintln(w, "  --dir: Project directory (default: the current directory)")
+		fmt.Fprintln(w, "  --volumes: (down) Also delete the data of the services")
+		fmt.Fprintln(w, "  --engine: (up, down) Container engine to use: docker or podman (or $SQUAD_CONTAINER_ENGINE)")
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, bold("Example:"))
+		fmt.Fprintln(w, "  squad service add postgres && squad service up")
 		fmt.Fprintln(w, "")
 
 	case "help":
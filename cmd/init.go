@@ -10,7 +10,10 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/fatih/color"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
 	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/provider"
 	"github.com/squadbase/squadbase/internal/templates"
 	"github.com/squadbase/squadbase/internal/ui"
 	"github.com/urfave/cli/v2"
@@ -71,6 +74,23 @@ func initAction(c *cli.Context) error {
 		return err
 	}
 
+	var existing *config.Document
+	var existingLanguageVersion string
+	if config.Exists(directory) {
+		var err error
+		existing, err = config.ReadDocument(directory)
+		if err != nil {
+			fmt.Printf("%s Failed to read existing squadbase.yml: %v\n", color.RedString("ERROR:"), err)
+			return err
+		}
+		if err := checkSquadbaseYmlVersion(existing.GetString("version")); err != nil {
+			return err
+		}
+		_, existingLanguageVersion = config.ParseRuntime(existing.GetString("build", "runtime"))
+
+		fmt.Printf("\n%s Found an existing squadbase.yml. Its current values are used as defaults.\n", cyan("INFO:"))
+	}
+
 	templates, err := templates.GetAvailableTemplates(false)
 	if err != nil {
 		fmt.Printf("%s Failed to get available templates: %v\n", color.RedString("ERROR:"), err)
@@ -93,6 +113,9 @@ func initAction(c *cli.Context) error {
 		Message: "",
 		Options: templateNames,
 	}
+	if existing != nil && slices.Contains(templateNames, existing.GetString("build", "framework")) {
+		templatePrompt.Default = existing.GetString("build", "framework")
+	}
 	err = survey.AskOne(templatePrompt, &templateName)
 	if err != nil {
 		return fmt.Errorf("initialization cancelled")
@@ -100,8 +123,6 @@ func initAction(c *cli.Context) error {
 
 	var languageVersion string
 	var packageManager string
-	var deploymentProvider string
-
 	if templateName == "morph" || templateName == "streamlit" {
 		fmt.Printf("\n%s %s\n", cyan("Python Configuration"), "🐍")
 
@@ -109,7 +130,9 @@ func initAction(c *cli.Context) error {
 		supportedVersions := []string{"3.9", "3.10", "3.11", "3.12"}
 
 		defaultVersion := "3.10"
-		if slices.Contains(supportedVersions, currentPyVersion) {
+		if slices.Contains(supportedVersions, existingLanguageVersion) {
+			defaultVersion = existingLanguageVersion
+		} else if slices.Contains(supportedVersions, currentPyVersion) {
 			defaultVersion = currentPyVersion
 		}
 
@@ -126,7 +149,7 @@ func initAction(c *cli.Context) error {
 		pmPrompt := &survey.Select{
 			Message: "Select package manager:",
 			Options: []string{"poetry", "uv", "pip"},
-			Default: "poetry",
+			Default: existingDefault(existing, []string{"poetry", "uv", "pip"}, "poetry", "build", "package_manager"),
 		}
 		err = survey.AskOne(pmPrompt, &packageManager)
 		if err != nil {
@@ -139,7 +162,9 @@ func initAction(c *cli.Context) error {
 		supportedNodeVersions := []string{"16", "18", "20"}
 
 		defaultNodeVersion := "18"
-		if slices.Contains(supportedNodeVersions, currentNodeVersion) {
+		if slices.Contains(supportedNodeVersions, existingLanguageVersion) {
+			defaultNodeVersion = existingLanguageVersion
+		} else if slices.Contains(supportedNodeVersions, currentNodeVersion) {
 			defaultNodeVersion = currentNodeVersion
 		}
 
@@ -156,7 +181,7 @@ func initAction(c *cli.Context) error {
 		pmPrompt := &survey.Select{
 			Message: "Select package manager:",
 			Options: []string{"npm", "yarn", "pnpm"},
-			Default: "npm",
+			Default: existingDefault(existing, []string{"npm", "yarn", "pnpm"}, "npm", "build", "package_manager"),
 		}
 		err = survey.AskOne(pmPrompt, &packageManager)
 		if err != nil {
@@ -166,20 +191,26 @@ func initAction(c *cli.Context) error {
 
 	fmt.Printf("\n%s %s\n", cyan("Deployment Configuration"), "🚀")
 
-	deploymentOptions := []string{"aws", "gcp"}
-	deploymentDefault := "aws"
-
-	if templateName == "streamlit" || templateName == "nextjs" {
-		deploymentOptions = []string{"gcp"}
-		deploymentDefault = "gcp"
+	var deploymentTarget string
+	var currentProvider string
+	if existing != nil {
+		deploymentTarget, err = selectDeploymentTarget(existing)
+		if err != nil {
+			return fmt.Errorf("initialization cancelled")
+		}
+		currentProvider = existing.GetString(append(existing.TargetPath(deploymentTarget), "provider")...)
 	}
+	selectedProvider, err := provider.Select(templateName, currentProvider)
+	if err != nil {
+		return fmt.Errorf("initialization cancelled")
+	}
+	deploymentProvider := selectedProvider.Name()
 
-	dpPrompt := &survey.Select{
-		Message: "Select deployment provider:",
-		Options: deploymentOptions,
-		Default: deploymentDefault,
+	var currentRegion string
+	if existing != nil {
+		currentRegion = existing.GetString(append(existing.TargetPath(deploymentTarget), deploymentProvider, "region")...)
 	}
-	err = survey.AskOne(dpPrompt, &deploymentProvider)
+	deploymentRegion, err := selectedProvider.PromptRegion(currentRegion)
 	if err != nil {
 		return fmt.Errorf("initialization cancelled")
 	}
@@ -199,7 +230,15 @@ func initAction(c *cli.Context) error {
 		fmt.Printf("  %-20s %s\n", "Package Manager:", green(packageManager))
 	}
 
+	if deploymentTarget != "" {
+		fmt.Printf("  %-20s %s\n", "Deployment Target:", green(deploymentTarget))
+	}
 	fmt.Printf("  %-20s %s\n", "Deployment Provider:", green(deploymentProvider))
+	fmt.Printf("  %-20s %s\n", "Deployment Region:", green(deploymentRegion))
+
+	if existing != nil {
+		return updateSquadbaseYml(existing, directory, templateName, languageVersion, packageManager, deploymentTarget, deploymentProvider, deploymentRegion)
+	}
 
 	var confirm bool
 	confirmPrompt := &survey.Confirm{
@@ -219,11 +258,19 @@ func initAction(c *cli.Context) error {
 	fmt.Printf("%s Creating squadbase.yml...\n", cyan("INFO:"))
 	time.Sleep(500 * time.Millisecond)
 
-	err = project.CreateSquadbaseYml(directory, templateName, languageVersion, packageManager, deploymentProvider)
+	env := project.DetectEnvVars(directory)
+	err = project.CreateSquadbaseYml(directory, templateName, languageVersion, packageManager, deploymentProvider, deploymentRegion, env)
 	if err != nil {
 		fmt.Printf("%s Failed to create squadbase.yml: %v\n", color.RedString("ERROR:"), err)
 		return err
 	}
+	if _, err := config.WriteEnvExample(directory, env); err != nil {
+		fmt.Printf("%s %v\n", color.RedString("ERROR:"), err)
+		return err
+	}
+	if len(env) > 0 {
+		fmt.Printf("%s Declared %d environment variables found in %s and %s\n", cyan("INFO:"), len(env), config.EnvExampleFileName, dotenv.FileName)
+	}
 
 	absPath, err := filepath.Abs(directory)
 	if err != nil {
@@ -237,3 +284,115 @@ func initAction(c *cli.Context) error {
 
 	return nil
 }
+
+func updateSquadbaseYml(
+	doc *config.Document,
+	directory string,
+	templateName string,
+	languageVersion string,
+	packageManager string,
+	deploymentTarget string,
+	deploymentProvider string,
+	deploymentRegion string,
+) error {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen, color.Bold).SprintFunc()
+
+	project.MergeSquadbaseYml(doc, templateName, languageVersion, packageManager, deploymentTarget, deploymentProvider, deploymentRegion)
+
+	changes, err := doc.Diff(config.FileName)
+	if err != nil {
+		fmt.Printf("%s Failed to update squadbase.yml: %v\n", color.RedString("ERROR:"), err)
+		return err
+	}
+
+	if changes == "" {
+		fmt.Printf("\n%s squadbase.yml is already up to date. Nothing to write.\n", green("✅"))
+		return nil
+	}
+
+	fmt.Printf("\n%s\n", cyan("Changes to squadbase.yml:"))
+	ui.PrintDiff(changes)
+
+	var confirm bool
+	confirmPrompt := &survey.Confirm{
+		Message: "Apply these changes to squadbase.yml?",
+		Default: true,
+	}
+	err = survey.AskOne(confirmPrompt, &confirm)
+	if err != nil {
+		return fmt.Errorf("initialization cancelled")
+	}
+
+	if !confirm {
+		fmt.Println("Configuration cancelled by user.")
+		return nil
+	}
+
+	err = doc.WriteFile(directory)
+	if err != nil {
+		fmt.Printf("%s Failed to update squadbase.yml: %v\n", color.RedString("ERROR:"), err)
+		return err
+	}
+
+	fmt.Printf("\n%s Successfully updated %s\n", green("✅"), config.Path(directory))
+	return nil
+}
+
+// selectDeploymentTarget asks whether to update one of the deployment targets
+// of an existing squadbase.yml or to add a new one, and returns the target's
+// name. It returns an empty name when the file has no deployment yet.
+func selectDeploymentTarget(doc *config.Document) (string, error) {
+	names := doc.TargetNames()
+	if names == nil {
+		if doc.GetString("deployment", "provider") == "" {
+			return "", nil
+		}
+		names = []string{config.DefaultTargetName}
+	}
+
+	const addTarget = "+ Add a new deployment target"
+	var selected string
+	prompt := &survey.Select{
+		Message: "Select the deployment target to configure:",
+		Options: append(slices.Clone(names), addTarget),
+		Default: names[0],
+	}
+	if err := survey.AskOne(prompt, &selected); err != nil {
+		return "", err
+	}
+	if selected != addTarget {
+		return selected, nil
+	}
+
+	var name string
+	namePrompt := &survey.Input{
+		Message: "Name of the new deployment target (e.g. production, tokyo):",
+	}
+	err := survey.AskOne(namePrompt, &name, survey.WithValidator(func(answer interface{}) error {
+		value, _ := answer.(string)
+		if !config.ValidTargetName(value) {
+			return fmt.Errorf("use lowercase letters, digits, '-' and '_'")
+		}
+		if slices.Contains(names, value) {
+			return fmt.Errorf("target %q already exists", value)
+		}
+		return nil
+	}))
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// existingDefault returns the value found at path in an existing squadbase.yml
+// when it is one of the options, and fallback otherwise.
+func existingDefault(doc *config.Document, options []string, fallback string, path ...string) string {
+	if doc == nil {
+		return fallback
+	}
+	if value := doc.GetString(path...); slices.Contains(options, value) {
+		return value
+	}
+	return fallback
+}
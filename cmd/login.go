@@ -0,0 +1,235 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/auth"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func LoginCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "login",
+		Usage: "Log in to Squadbase with a code confirmed in the browser",
+		Flags: []cli.Flag{
+			accountFlag(),
+			apiURLFlag(),
+		},
+		Action: loginAction,
+	}
+}
+
+func LogoutCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "logout",
+		Usage: "Remove the stored credentials of an account",
+		Flags: []cli.Flag{
+			accountFlag(),
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Log out of every account",
+			},
+		},
+		Action: logoutAction,
+	}
+}
+
+func WhoamiCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "whoami",
+		Usage: "Show the identity API commands authenticate as",
+		Flags: []cli.Flag{
+			accountFlag(),
+			apiURLFlag(),
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the identity as JSON",
+			},
+		},
+		Action: whoamiAction,
+	}
+}
+
+func loadCredentials() (string, *auth.Credentials, error) {
+	path, err := auth.Path()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return "", nil, err
+	}
+	creds, err := auth.Load(path)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return "", nil, err
+	}
+	return path, creds, nil
+}
+
+func loginAction(c *cli.Context) error {
+	path, creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	name := c.String("account")
+	if name == "" {
+		name = creds.Current
+	}
+	if name == "" {
+		name = auth.DefaultAccount
+	}
+
+	client, err := api.NewClient(c.String("api-url"), "")
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	code, err := client.RequestDeviceCode(c.Context)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to start login: %v", err))
+		return err
+	}
+
+	w := c.App.Writer
+	fmt.Fprintln(w, "")
+	fmt.Fprintf(w, "Open %s and enter the code %s\n", ui.GetPrimaryText(code.VerificationURI), ui.GetAccentText(code.UserCode))
+	if code.VerificationURIComplete != "" {
+		fmt.Fprintf(w, "or open %s to confirm it directly.\n", ui.GetSecondaryText(code.VerificationURIComplete))
+	}
+	fmt.Fprintln(w, "")
+
+	spinner := ui.ShowSpinner("Waiting for the code to be confirmed")
+	token, err := client.WaitForToken(c.Context, code, code.PollInterval())
+	if err != nil {
+		spinner.Fail(fmt.Sprintf("Login failed: %v", err))
+		return err
+	}
+	spinner.Success("Code confirmed")
+
+	client.Token = token.AccessToken
+	user, err := client.WhoAmI(c.Context)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to get the logged in user: %v", err))
+		return err
+	}
+
+	creds.Set(name, &auth.Account{
+		Token:     token.AccessToken,
+		APIURL:    client.BaseURL.String(),
+		Email:     user.Email,
+		UserID:    user.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err := creds.Save(path); err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Logged in as %s (account %s)", user.Email, name))
+	if os.Getenv(auth.TokenEnv) != "" {
+		ui.PrintWarning(fmt.Sprintf("%s is set and is used instead of this login", auth.TokenEnv))
+	}
+	return nil
+}
+
+func logoutAction(c *cli.Context) error {
+	path, creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+
+	var removed []string
+	if c.Bool("all") {
+		removed = creds.Names()
+		for _, name := range removed {
+			creds.Remove(name)
+		}
+	} else {
+		name := c.String("account")
+		if name == "" {
+			name = creds.Current
+		}
+		if name != "" && creds.Remove(name) {
+			removed = append(removed, name)
+		}
+	}
+
+	if len(removed) == 0 {
+		ui.PrintWarning("Not logged in")
+		return nil
+	}
+	if err := creds.Save(path); err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Logged out of %s", strings.Join(removed, ", ")))
+	if creds.Current != "" {
+		ui.PrintInfo(fmt.Sprintf("Now using account %s", creds.Current))
+	}
+	if os.Getenv(auth.TokenEnv) != "" {
+		ui.PrintWarning(fmt.Sprintf("%s is still set and keeps authenticating API commands", auth.TokenEnv))
+	}
+	return nil
+}
+
+type identity struct {
+	// Account is empty when the token comes from $SQUAD_TOKEN.
+	Account string    `json:"account,omitempty"`
+	Source  string    `json:"source"`
+	APIURL  string    `json:"api_url"`
+	User    *api.User `json:"user"`
+}
+
+func whoamiAction(c *cli.Context) error {
+	creds, err := resolveCredentials(c)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	client, err := api.NewClient(creds.APIURL, creds.Token)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	user, err := client.WhoAmI(c.Context)
+	if api.IsUnauthorized(err) {
+		ui.PrintError("The token was rejected. Run squad login again.")
+		return err
+	}
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to get the logged in user: %v", err))
+		return err
+	}
+
+	id := identity{Account: creds.Account, Source: "credentials", APIURL: client.BaseURL.String(), User: user}
+	if creds.Account == "" {
+		id.Source = auth.TokenEnv
+	}
+
+	w := c.App.Writer
+	if c.Bool("json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(id)
+	}
+
+	fmt.Fprintln(w, ui.GetPrimaryText(user.Email))
+	if user.Name != "" {
+		fmt.Fprintf(w, "  Name:          %s\n", user.Name)
+	}
+	if user.Organization != "" {
+		fmt.Fprintf(w, "  Organization:  %s\n", user.Organization)
+	}
+	if creds.Account != "" {
+		fmt.Fprintf(w, "  Account:       %s\n", ui.GetAccentText(creds.Account))
+	} else {
+		fmt.Fprintf(w, "  Token:         %s\n", ui.GetAccentText("$"+auth.TokenEnv))
+	}
+	fmt.Fprintf(w, "  API:           %s\n", id.APIURL)
+	return nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func LogsCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "logs",
+		Usage:     "Show the runtime logs of the deployed app",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			targetFlag(),
+			projectFlag(),
+			accountFlag(),
+			apiURLFlag(),
+			&cli.BoolFlag{
+				Name:    "follow",
+				Aliases: []string{"f"},
+				Usage:   "Keep streaming new log entries",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Show entries from this time on, as a duration (1h) or a timestamp (2024-05-01T09:00:00Z)",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "Show entries up to this time, as a duration (10m) or a timestamp",
+			},
+			&cli.StringFlag{
+				Name:    "severity",
+				Aliases: []string{"s"},
+				Usage:   "Least severity to show: debug, info, warning or error",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print one JSON object per entry",
+			},
+		},
+		Action: logsAction,
+	}
+}
+
+// parseTime reads a --since or --until value: a duration counts back from now.
+func parseTime(flag string, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --%s %q: expected a duration such as 1h or a time such as 2024-05-01T09:00:00Z", flag, value)
+}
+
+func logsOptions(c *cli.Context) (api.LogsOptions, error) {
+	opts := api.LogsOptions{Target: c.String("target"), Follow: c.Bool("follow")}
+	now := time.Now()
+	var err error
+	if opts.Since, err = parseTime("since", c.String("since"), now); err != nil {
+		return opts, err
+	}
+	if opts.Until, err = parseTime("until", c.String("until"), now); err != nil {
+		return opts, err
+	}
+	if opts.Follow && !opts.Until.IsZero() {
+		return opts, fmt.Errorf("--follow cannot be combined with --until")
+	}
+	if severity := c.String("severity"); severity != "" {
+		if opts.Severity, err = api.ParseSeverity(severity); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
+
+func logsAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+	opts, err := logsOptions(c)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	client, err := newAPIClient(c)
+	if err != nil {
+		return err
+	}
+	name := projectName(c, directory)
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := c.App.Writer
+	encoder := json.NewEncoder(w)
+	// A failed write, such as into a closed pipe, stops the stream.
+	var writeErr error
+	err = client.StreamLogs(ctx, name, opts, api.DefaultBackoff, func(entry *api.LogEntry) {
+		if writeErr != nil {
+			return
+		}
+		if c.Bool("json") {
+			writeErr = encoder.Encode(entry)
+		} else {
+			writeErr = printLogEntry(w, entry)
+		}
+		if writeErr != nil {
+			stop()
+		}
+	})
+	if writeErr != nil {
+		return fmt.Errorf("failed to write the logs: %w", writeErr)
+	}
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to read the logs of %s: %v", name, err))
+		return err
+	}
+	return nil
+}
+
+func printLogEntry(w io.Writer, entry *api.LogEntry) error {
+	severity := fmt.Sprintf("%-7s", strings.ToUpper(entry.Severity))
+	switch api.SeverityRank(entry.Severity) {
+	case api.SeverityRank(api.SeverityDebug):
+		severity = ui.GetSecondaryText(severity)
+	case api.SeverityRank(api.SeverityWarning):
+		severity = ui.GetWarningText(severity)
+	case api.SeverityRank(api.SeverityError):
+		severity = ui.GetErrorText(severity)
+	default:
+		severity = ui.GetInfoText(severity)
+	}
+
+	timestamp := entry.Time.Local().Format("2006-01-02 15:04:05.000")
+	var err error
+	if entry.Instance != "" {
+		_, err = fmt.Fprintf(w, "%s %s %s %s\n", timestamp, severity, ui.GetAccentText(entry.Instance), entry.Message)
+	} else {
+		_, err = fmt.Fprintf(w, "%s %s %s\n", timestamp, severity, entry.Message)
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func MigrateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "migrate",
+		Usage:     "Upgrade squadbase.yml to the current schema version",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes without writing them",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Write the changes without asking for confirmation",
+			},
+		},
+		Action: migrateAction,
+	}
+}
+
+func migrateAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	doc, err := config.ReadDocument(directory)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	applied, err := config.Migrate(doc)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	if len(applied) == 0 {
+		ui.PrintSuccess(fmt.Sprintf("%s is already at the current version (%s)", config.FileName, config.CurrentVersion))
+		return nil
+	}
+
+	steps := make(map[string]string)
+	for _, m := range applied {
+		from := m.From
+		if from == "" {
+			from = "unversioned"
+		}
+		steps[fmt.Sprintf("%s → %s", from, m.To)] = m.Description
+	}
+	ui.PrintSummaryBox("🔧 Migration Steps", steps)
+
+	changes, err := doc.Diff(config.FileName)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	fmt.Println()
+	ui.PrintDiff(changes)
+	fmt.Println()
+
+	if c.Bool("dry-run") {
+		ui.PrintInfo("Dry run: no changes were written")
+		return nil
+	}
+
+	if !c.Bool("yes") {
+		var confirm bool
+		confirmPrompt := &survey.Confirm{
+			Message: fmt.Sprintf("Write these changes to %s?", config.FileName),
+			Default: true,
+		}
+		err = survey.AskOne(confirmPrompt, &confirm)
+		if err != nil {
+			return fmt.Errorf("migration cancelled")
+		}
+		if !confirm {
+			ui.PrintInfo("Migration cancelled by user.")
+			return nil
+		}
+	}
+
+	err = doc.WriteFile(directory)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to write %s: %v", config.FileName, err))
+		return err
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Migrated %s to version %s", config.Path(directory), config.CurrentVersion))
+	return nil
+}
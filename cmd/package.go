@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/squadbase/squadbase/internal/bundle"
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func PackageCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "package",
+		Usage:     "Write a reproducible tar.gz bundle of the project",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Path of the bundle (default: DIRECTORY/.squad/<project>.tar.gz)",
+			},
+			&cli.IntFlag{
+				Name:  "top",
+				Usage: "Number of largest files to list",
+				Value: 10,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the bundle and its files as JSON",
+			},
+		},
+		Action: packageAction,
+	}
+}
+
+func packageAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	b, err := createBundle(directory, c.String("output"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	w := c.App.Writer
+	if c.Bool("json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(b)
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, ui.GetPrimaryText(fmt.Sprintf("📦 %s", b.Path)))
+	fmt.Fprintf(w, "%d files, %s (%s uncompressed)\n", len(b.Files), formatBytes(b.Size), formatBytes(b.ContentSize()))
+	fmt.Fprintf(w, "sha256 %s\n", ui.GetAccentText(b.SHA256))
+
+	if largest := b.Largest(c.Int("top")); len(largest) > 0 {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, ui.GetPrimaryText("📊 Largest files"))
+		for _, file := range largest {
+			fmt.Fprintf(w, "  %10s  %s\n", formatBytes(file.Size), file.Path)
+		}
+	}
+	fmt.Fprintln(w, "")
+	ui.PrintSuccess(fmt.Sprintf("Packaged %s. Add patterns to %s to leave files out.", filepath.Base(directory), bundle.IgnoreFileName))
+	return nil
+}
+
+// createBundle writes the bundle of the project in directory to output, or to
+// the default location when output is empty.
+func createBundle(directory string, output string) (*bundle.Bundle, error) {
+	if output == "" {
+		output = filepath.Join(directory, ".squad", export.AppName(directory)+".tar.gz")
+	}
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", directory, err)
+	}
+	absOutput, err := filepath.Abs(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", output, err)
+	}
+	return bundle.Create(abs, absOutput)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value := float64(n)
+	for _, suffix := range []string{"KB", "MB", "GB"} {
+		value /= unit
+		if value < unit || suffix == "GB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func RenderCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "render",
+		Usage:     "Print squadbase.yml with overlays merged and variables resolved",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			envFlag(),
+			&cli.BoolFlag{
+				Name:  "no-sources",
+				Usage: "Do not annotate interpolated values with where they came from",
+			},
+		},
+		Action: renderAction,
+	}
+}
+
+func renderAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	env := c.String("env")
+	doc, resolutions, err := readSquadbaseYml(directory, env)
+	if err != nil {
+		return err
+	}
+	if !c.Bool("no-sources") {
+		doc.AnnotateSources(resolutions)
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	w := c.App.Writer
+	if env != "" {
+		fmt.Fprintf(w, "# %s + %s\n", config.FileName, config.OverlayFileName(env))
+	} else {
+		fmt.Fprintf(w, "# %s\n", config.FileName)
+	}
+	fmt.Fprint(w, string(data))
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/diff"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func RollbackCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rollback",
+		Usage:     "Promote an earlier deployment back to its target",
+		ArgsUsage: "<DEPLOYMENT_ID> [DIRECTORY]",
+		Flags: []cli.Flag{
+			projectFlag(),
+			accountFlag(),
+			apiURLFlag(),
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Roll back without asking for confirmation",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "How long to wait for the rollback to finish",
+				Value: 20 * time.Minute,
+			},
+			&cli.BoolFlag{
+				Name:  "no-wait",
+				Usage: "Start the rollback without waiting for it to finish",
+			},
+		},
+		Action: rollbackAction,
+	}
+}
+
+func rollbackAction(c *cli.Context) error {
+	id := c.Args().First()
+	if id == "" {
+		err := fmt.Errorf("missing deployment ID: run squad deployments list to find one")
+		ui.PrintError(err.Error())
+		return err
+	}
+	directory := c.Args().Get(1)
+	if directory == "" {
+		var err error
+		directory, err = os.Getwd()
+		if err != nil {
+			ui.PrintError("Failed to get current directory")
+			return err
+		}
+	}
+
+	client, err := newAPIClient(c)
+	if err != nil {
+		return err
+	}
+	name := projectName(c, directory)
+
+	target, err := client.GetDeployment(c.Context, name, id)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to get deployment %s: %v", id, err))
+		return err
+	}
+	if target.Status != api.StatusReady {
+		err := fmt.Errorf("deployment %s is %s and cannot be rolled back to", id, target.Status)
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	history, err := client.ListDeployments(c.Context, name, api.ListDeploymentsOptions{Target: target.Target})
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to list deployments of %s: %v", name, err))
+		return err
+	}
+	var live *api.Deployment
+	for _, d := range history {
+		if d.Live {
+			live = d
+			break
+		}
+	}
+	if live != nil && live.ID == target.ID {
+		ui.PrintInfo(fmt.Sprintf("%s is already live on %s", id, target.Target))
+		return nil
+	}
+
+	printRollbackChanges(live, target)
+
+	if !c.Bool("yes") {
+		var confirm bool
+		confirmPrompt := &survey.Confirm{
+			Message: fmt.Sprintf("Roll %s back to %s?", target.Target, id),
+			Default: false,
+		}
+		err = survey.AskOne(confirmPrompt, &confirm)
+		if err != nil {
+			return fmt.Errorf("rollback cancelled")
+		}
+		if !confirm {
+			ui.PrintInfo("Rollback cancelled by user.")
+			return nil
+		}
+	}
+
+	deployment, err := client.Rollback(c.Context, name, id)
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to start rollback: %v", err))
+		return err
+	}
+	ui.PrintInfo(fmt.Sprintf("Started deployment %s, rolling %s back to %s", deployment.ID, target.Target, id))
+	return waitForDeployment(c, client, name, deployment)
+}
+
+// printRollbackChanges shows what changes when live is replaced by target:
+// the commit, and a diff of squadbase.yml.
+func printRollbackChanges(live *api.Deployment, target *api.Deployment) {
+	liveID, liveCommit, liveConfig := "none", "", ""
+	if live != nil {
+		liveID, liveCommit, liveConfig = live.ID, live.Commit, live.Config
+	}
+
+	fmt.Println()
+	ui.PrintInfo(fmt.Sprintf("Target %s: %s → %s", target.Target, liveID, target.ID))
+	if liveCommit != target.Commit {
+		ui.PrintInfo(fmt.Sprintf("Commit: %s → %s", shortHash(liveCommit, 7), shortHash(target.Commit, 7)))
+	}
+
+	changes := diff.Unified(liveConfig, target.Config,
+		fmt.Sprintf("%s (%s, live)", config.FileName, liveID),
+		fmt.Sprintf("%s (%s)", config.FileName, target.ID))
+	if changes == "" {
+		ui.PrintInfo(fmt.Sprintf("%s is unchanged", config.FileName))
+		fmt.Println()
+		return
+	}
+	fmt.Println()
+	ui.PrintDiff(changes)
+	fmt.Println()
+}
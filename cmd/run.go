@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/container"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func RunCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "Build the image and run it locally with the deployment's resource limits",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			envFlag(),
+			targetFlag(),
+			&cli.IntFlag{
+				Name:    "port",
+				Aliases: []string{"p"},
+				Usage:   "Local port to serve the app on (default: the framework's port)",
+			},
+			&cli.StringFlag{
+				Name:  "platform",
+				Usage: "Platform to build and run",
+				Value: container.DefaultPlatform,
+			},
+			&cli.DurationFlag{
+				Name:  "health-timeout",
+				Usage: "How long to wait for the app to become healthy",
+				Value: 60 * time.Second,
+			},
+			engineFlag(),
+		},
+		Action: runAction,
+	}
+}
+
+func runAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+
+	cfg, target, err := loadDeploymentTarget(c, directory)
+	if err != nil {
+		return err
+	}
+	p, err := provider.Get(target.Provider)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	limits := p.Resources(&target.Deployment)
+
+	dotEnv, err := dotenv.Read(dotenv.Path(directory))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if err := checkRequiredEnv(cfg, dotEnv); err != nil {
+		return err
+	}
+
+	engine, err := container.Detect(c.String("engine"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	scratch, err := os.MkdirTemp("", "squadbase_run_")
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to create temporary directory: %v", err))
+		return err
+	}
+	defer os.RemoveAll(scratch)
+
+	name := export.AppName(directory)
+	buildOpts, err := container.NewBuildOptions(directory, cfg.Build, scratch)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	buildOpts.Platform = c.String("platform")
+	buildOpts.Tags = []string{name + ":squad-run"}
+
+	ui.PrintInfo(fmt.Sprintf("Building %s with %s", buildOpts.Tags[0], engine.Name()))
+	if err := container.Build(c.Context, engine, buildOpts, c.App.Writer, c.App.ErrWriter); err != nil {
+		printBuildError(err)
+		return err
+	}
+
+	hostPort, err := freePort()
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to find a free port: %v", err))
+		return err
+	}
+	runOpts := &container.RunOptions{
+		Image:    buildOpts.Tags[0],
+		Name:     name + "-squad-run",
+		Port:     export.ContainerPort(cfg.Build.Framework),
+		HostPort: hostPort,
+		MemoryMB: limits.MemoryMB,
+		CPU:      limits.CPU,
+		Env:      dotEnv,
+	}
+
+	port := c.Int("port")
+	if port == 0 {
+		port = runOpts.Port
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to listen on port %d: %v", port, err))
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// A container left behind by an interrupted run would hold the name.
+	_ = container.Remove(ctx, engine, runOpts.Name)
+	id, err := container.Start(ctx, engine, runOpts)
+	if err != nil {
+		listener.Close()
+		ui.PrintError(err.Error())
+		return err
+	}
+	defer container.Remove(context.Background(), engine, id)
+
+	go container.Logs(context.Background(), engine, id, c.App.Writer, c.App.ErrWriter)
+
+	exited := make(chan struct{})
+	var state *container.State
+	var waitErr error
+	go func() {
+		state, waitErr = container.Wait(context.Background(), engine, id)
+		close(exited)
+	}()
+
+	timeout := time.Duration(limits.TimeoutSeconds) * time.Second
+	proxy := container.NewTimeoutProxy(&url.URL{Scheme: "http", Host: fmt.Sprintf("127.0.0.1:%d", hostPort)}, timeout, func(r *http.Request) {
+		ui.PrintError(fmt.Sprintf("%s %s was cut off after %s, the request timeout set by %s. The platform would answer 504.",
+			r.Method, r.URL.Path, timeout, settingPath(target, "timeout")))
+	})
+	server := &http.Server{Handler: proxy}
+	go server.Serve(listener)
+	defer server.Close()
+
+	healthURL := fmt.Sprintf("http://127.0.0.1:%d%s", hostPort, export.HealthPath(cfg.Build.Framework))
+	if err := container.WaitHealthy(ctx, healthURL, 500*time.Millisecond, c.Duration("health-timeout"), exited); err != nil {
+		select {
+		case <-exited:
+			return reportExit(state, waitErr, target, limits)
+		default:
+		}
+		stopContainer(engine, id, exited)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		ui.PrintError(err.Error())
+		return err
+	}
+
+	ui.PrintSummaryBox("🚀 Running", map[string]string{
+		"URL":     fmt.Sprintf("http://localhost:%d", port),
+		"Target":  fmt.Sprintf("%s (%s)", target.Name, p.Service()),
+		"Memory":  fmt.Sprintf("%d MB", limits.MemoryMB),
+		"CPU":     fmt.Sprintf("%g vCPU", limits.CPU),
+		"Timeout": timeoutLabel(limits.TimeoutSeconds),
+	})
+	ui.PrintSuccess(fmt.Sprintf("App is healthy at http://localhost:%d. Press Ctrl+C to stop.", port))
+
+	select {
+	case <-ctx.Done():
+		stopContainer(engine, id, exited)
+		ui.PrintInfo("Stopped the app")
+		return nil
+	case <-exited:
+		return reportExit(state, waitErr, target, limits)
+	}
+}
+
+// reportExit explains why the container exited, in terms of the squadbase.yml
+// setting that caused it when a limit was hit.
+func reportExit(state *container.State, waitErr error, target *config.Target, limits provider.Resources) error {
+	if waitErr != nil {
+		ui.PrintError(waitErr.Error())
+		return waitErr
+	}
+	if state.OOMKilled {
+		message := fmt.Sprintf("The app was killed for using more than %d MB of memory, the limit set by %s.\nRaise it or choose a larger preset before deploying.",
+			limits.MemoryMB, settingPath(target, "memory"))
+		ui.PrintErrorBox("💥 Out of memory", message)
+		return fmt.Errorf("out of memory")
+	}
+	if state.ExitCode != 0 {
+		err := fmt.Errorf("the app exited with code %d", state.ExitCode)
+		ui.PrintError(err.Error())
+		return err
+	}
+	ui.PrintInfo("The app exited")
+	return nil
+}
+
+func stopContainer(engine container.Engine, id string, exited <-chan struct{}) {
+	if err := container.Stop(context.Background(), engine, id, 10*time.Second); err != nil {
+		ui.PrintError(err.Error())
+		return
+	}
+	<-exited
+}
+
+// settingPath returns the squadbase.yml key of a resource setting of the target.
+func settingPath(target *config.Target, setting string) string {
+	return fmt.Sprintf("%s.%s.%s", target.Prefix(), target.Provider, setting)
+}
+
+func timeoutLabel(seconds int) string {
+	if seconds == 0 {
+		return "none"
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/container"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/services"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func ServiceCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "service",
+		Usage: "Manage the backing services in squadbase.yml and run them locally",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "add",
+				Usage:     "Add a service to squadbase.yml and compose.yaml, and its connection URL to .env",
+				ArgsUsage: "TYPE",
+				Flags: []cli.Flag{
+					dirFlag(),
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "Name of the service (default: the type)",
+					},
+					&cli.StringFlag{
+						Name:  "version",
+						Usage: "Image version of the service (default: the latest supported major version)",
+					},
+					&cli.IntFlag{
+						Name:  "port",
+						Usage: "Local port to publish the service on (default: the service's port)",
+					},
+				},
+				Action: serviceAddAction,
+			},
+			{
+				Name:  "up",
+				Usage: "Start the services locally and wait until they are healthy",
+				Flags: []cli.Flag{
+					dirFlag(),
+					engineFlag(),
+				},
+				Action: serviceUpAction,
+			},
+			{
+				Name:  "down",
+				Usage: "Stop the local services",
+				Flags: []cli.Flag{
+					dirFlag(),
+					engineFlag(),
+					&cli.BoolFlag{
+						Name:  "volumes",
+						Usage: "Also delete the data of the services",
+					},
+				},
+				Action: serviceDownAction,
+			},
+		},
+	}
+}
+
+func serviceAddAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		err := fmt.Errorf("expected the service type: %s", strings.Join(services.Types(), " or "))
+		ui.PrintError(err.Error())
+		return err
+	}
+	d, err := services.Get(c.Args().First())
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	directory, doc, cfg, err := readSquadbaseDocument(c)
+	if err != nil {
+		return err
+	}
+
+	s := config.Service{Name: c.String("name"), Type: d.Type, Version: c.String("version"), Port: c.Int("port")}
+	if s.Name == "" {
+		s.Name = d.Type
+	}
+	s.Version = d.Version(&s)
+	s.Port = d.HostPort(&s)
+	if !config.ValidTargetName(s.Name) {
+		err := fmt.Errorf("invalid service name %q: use lowercase letters, digits, '-' and '_'", s.Name)
+		ui.PrintError(err.Error())
+		return err
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		err := fmt.Errorf("invalid port %d: use a port between 1 and 65535", s.Port)
+		ui.PrintError(err.Error())
+		return err
+	}
+	if cfg.Service(s.Name) != nil {
+		err := fmt.Errorf("a service named %s already exists in %s; choose another one with --name", s.Name, config.FileName)
+		ui.PrintError(err.Error())
+		return err
+	}
+	for i := range cfg.Services {
+		other := &cfg.Services[i]
+		if od, err := services.Get(other.Type); err == nil && od.HostPort(other) == s.Port {
+			err := fmt.Errorf("port %d is already used by %s; choose another one with --port", s.Port, other.Name)
+			ui.PrintError(err.Error())
+			return err
+		}
+	}
+
+	// The value goes into .env before the variable is declared, so that a
+	// failure never leaves a declared variable without a value.
+	envName := d.EnvName(&s)
+	dotEnv, err := dotenv.Read(dotenv.Path(directory))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if _, ok := dotEnv[envName]; ok {
+		ui.PrintInfo(fmt.Sprintf("Kept the value of %s in %s", envName, dotenv.FileName))
+	} else {
+		if err := dotenv.Set(dotenv.Path(directory), envName, d.URL(&s)); err != nil {
+			ui.PrintError(err.Error())
+			return err
+		}
+		ui.PrintSuccess(fmt.Sprintf("Set %s in %s", envName, dotenv.FileName))
+	}
+
+	cfg.Services = append(cfg.Services, s)
+	if err := doc.SetValue(cfg.Services, "services"); err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	vars := cfg.Env
+	if cfg.EnvVar(envName) == nil {
+		vars = append(vars, config.EnvVar{
+			Name:        envName,
+			Description: fmt.Sprintf("Connection URL of the %s service", s.Name),
+			Secret:      config.LikelySecret(envName),
+		})
+	}
+	if err := writeEnvDeclarations(directory, doc, vars); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Added %s %s to %s", d.Description, s.Version, config.FileName))
+
+	if err := writeCompose(directory, cfg.Services); err != nil {
+		return err
+	}
+	ui.PrintInfo("Start it with squad service up")
+	return nil
+}
+
+// writeCompose regenerates compose.yaml from the services, warning when it
+// is maintained by hand.
+func writeCompose(directory string, list []config.Service) error {
+	written, err := services.WriteCompose(directory, list)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	if written {
+		ui.PrintSuccess(fmt.Sprintf("Updated %s", services.ComposeFileName))
+	} else {
+		ui.PrintWarning(fmt.Sprintf("%s was not generated by squad and was left as it is. Keep its services in sync with %s.", services.ComposeFileName, config.FileName))
+	}
+	return nil
+}
+
+// runCompose runs docker compose or podman compose on the compose file of
+// the project.
+func runCompose(c *cli.Context, directory string, args ...string) error {
+	engine, err := container.Detect(c.String("engine"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	ctx, stop := signal.NotifyContext(c.Context, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	args = append([]string{"compose", "--file", services.ComposePath(directory)}, args...)
+	if err := engine.Run(ctx, args, os.Stdout, os.Stderr); err != nil {
+		err := fmt.Errorf("%s compose failed (exit code %d)", engine.Name(), container.ExitCode(err))
+		ui.PrintError(err.Error())
+		return err
+	}
+	return nil
+}
+
+func serviceUpAction(c *cli.Context) error {
+	directory, err := dirFlagDirectory(c)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadSquadbaseYml(directory, "")
+	if err != nil {
+		return err
+	}
+	if len(cfg.Services) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No services in %s. Add one with squad service add %s.", config.FileName, services.Types()[0]))
+		return nil
+	}
+	if err := writeCompose(directory, cfg.Services); err != nil {
+		return err
+	}
+
+	if err := runCompose(c, directory, "up", "--detach", "--wait"); err != nil {
+		return err
+	}
+	ui.PrintSuccess("Services are up")
+	for i := range cfg.Services {
+		s := &cfg.Services[i]
+		if d, err := services.Get(s.Type); err == nil {
+			fmt.Fprintf(c.App.Writer, "  %s %s\n", ui.GetPrimaryText(fmt.Sprintf("%-16s", s.Name)), ui.GetSecondaryText(fmt.Sprintf("localhost:%d (%s)", d.HostPort(s), d.EnvName(s))))
+		}
+	}
+	return nil
+}
+
+func serviceDownAction(c *cli.Context) error {
+	directory, err := dirFlagDirectory(c)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(services.ComposePath(directory)); err != nil {
+		ui.PrintInfo(fmt.Sprintf("No %s in %s", services.ComposeFileName, directory))
+		return nil
+	}
+	args := []string{"down"}
+	if c.Bool("volumes") {
+		args = append(args, "--volumes")
+	}
+	if err := runCompose(c, directory, args...); err != nil {
+		return err
+	}
+	ui.PrintSuccess("Services are stopped")
+	return nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/resources"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/squadbase/squadbase/internal/validate"
+	"github.com/urfave/cli/v2"
+)
+
+func envFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "env",
+		Aliases: []string{"e"},
+		Usage:   "Environment overlay to apply (reads squadbase.<ENV>.yml)",
+		EnvVars: []string{"SQUAD_ENV"},
+	}
+}
+
+func targetFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "target",
+		Aliases: []string{"t"},
+		Usage:   "Deployment target to use (a name from deployment.targets)",
+		EnvVars: []string{"SQUAD_TARGET"},
+	}
+}
+
+// projectDirectory returns the directory given as the first argument, or the
+// current directory when none was given.
+func projectDirectory(c *cli.Context) (string, error) {
+	directory := c.Args().First()
+	if directory != "" {
+		return directory, nil
+	}
+	directory, err := os.Getwd()
+	if err != nil {
+		ui.PrintError("Failed to get current directory")
+		return "", err
+	}
+	return directory, nil
+}
+
+// readSquadbaseYml reads squadbase.yml from directory with the overlay for env
+// merged onto it, after checking that its schema version is supported.
+// ${VAR} expressions are resolved from the environment and the project's .env file,
+// presets are expanded, and the resource settings are checked against the provider's limits.
+func readSquadbaseYml(directory string, env string) (*config.Document, []config.Resolution, error) {
+	doc, err := config.ReadMergedDocument(directory, env)
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+	if err := checkSquadbaseYmlVersion(doc.GetString("version")); err != nil {
+		return nil, nil, err
+	}
+
+	dotEnv, err := dotenv.Read(dotenv.Path(directory))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+	resolutions, err := doc.Interpolate(config.NewVariables(dotEnv))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+
+	if err := resources.ExpandPresets(doc); err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+	cfg, err := doc.Decode()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+	if err := validate.Config(cfg); err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+
+	return doc, resolutions, nil
+}
+
+// loadSquadbaseYml reads squadbase.yml like readSquadbaseYml and decodes it.
+func loadSquadbaseYml(directory string, env string) (*config.Config, error) {
+	doc, _, err := readSquadbaseYml(directory, env)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := doc.Decode()
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadDeploymentTarget reads squadbase.yml like loadSquadbaseYml and selects
+// the deployment target named by --target, or the default target.
+func loadDeploymentTarget(c *cli.Context, directory string) (*config.Config, *config.Target, error) {
+	cfg, err := loadSquadbaseYml(directory, c.String("env"))
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := cfg.Target(c.String("target"))
+	if err != nil {
+		ui.PrintError(err.Error())
+		return nil, nil, err
+	}
+	return cfg, target, nil
+}
+
+// checkSquadbaseYmlVersion refuses squadbase.yml files written for a newer
+// CLI and warns about files that squad migrate can upgrade.
+func checkSquadbaseYmlVersion(version string) error {
+	err := config.CheckVersion(version)
+	if errors.Is(err, config.ErrOutdatedVersion) {
+		ui.PrintWarning(fmt.Sprintf("%v. Run 'squad migrate' to upgrade it.", err))
+		return nil
+	}
+	if err != nil {
+		ui.PrintError(err.Error())
+		return err
+	}
+	return nil
+}
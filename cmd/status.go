@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/ui"
+	"github.com/urfave/cli/v2"
+)
+
+func StatusCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "status",
+		Usage:     "Show what runs on each deployment target and whether it is healthy",
+		ArgsUsage: "[DIRECTORY]",
+		Flags: []cli.Flag{
+			targetFlag(),
+			projectFlag(),
+			accountFlag(),
+			apiURLFlag(),
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the status as JSON",
+			},
+		},
+		Action: statusAction,
+	}
+}
+
+func statusAction(c *cli.Context) error {
+	directory, err := projectDirectory(c)
+	if err != nil {
+		return err
+	}
+	client, err := newAPIClient(c)
+	if err != nil {
+		return err
+	}
+	name := projectName(c, directory)
+
+	status, err := client.GetStatus(c.Context, name, c.String("target"))
+	if err != nil {
+		ui.PrintError(fmt.Sprintf("Failed to get the status of %s: %v", name, err))
+		return err
+	}
+
+	w := c.App.Writer
+	if c.Bool("json") {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(status)
+	}
+
+	if len(status.Targets) == 0 {
+		ui.PrintWarning(fmt.Sprintf("%s has not been deployed yet. Run squad deploy first.", name))
+		return nil
+	}
+	for _, target := range status.Targets {
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "%s  %s\n", ui.GetPrimaryText(target.Target), healthText(target.Health))
+		if target.HealthMessage != "" {
+			fmt.Fprintf(w, "  %s\n", target.HealthMessage)
+		}
+		if target.Revision != "" {
+			fmt.Fprintf(w, "  Revision:   %s (%s)\n", ui.GetAccentText(target.Revision), target.DeploymentID)
+		}
+		if target.URL != "" {
+			fmt.Fprintf(w, "  URL:        %s\n", target.URL)
+		}
+		provider := target.Provider
+		if target.Region != "" {
+			provider += " (" + target.Region + ")"
+		}
+		fmt.Fprintf(w, "  Provider:   %s\n", provider)
+		fmt.Fprintf(w, "  Instances:  %d\n", target.Instances)
+		if !target.DeployedAt.IsZero() {
+			fmt.Fprintf(w, "  Deployed:   %s\n", target.DeployedAt.Local().Format("2006-01-02 15:04:05"))
+		}
+	}
+	fmt.Fprintln(w, "")
+	return nil
+}
+
+func healthText(health string) string {
+	switch health {
+	case api.HealthHealthy:
+		return ui.GetSuccessText("● " + health)
+	case api.HealthDegraded:
+		return ui.GetWarningText("● " + health)
+	case api.HealthUnhealthy:
+		return ui.GetErrorText("● " + health)
+	default:
+		return ui.GetSecondaryText("● " + api.HealthUnknown)
+	}
+}
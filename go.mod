@@ -8,6 +8,8 @@ require (
 	github.com/fatih/color v1.16.0
 	github.com/pterm/pterm v0.12.80
 	github.com/urfave/cli/v2 v2.27.1
+	golang.org/x/crypto v0.9.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientID identifies the CLI to the authorization server.
+const ClientID = "squad-cli"
+
+// DeviceCodeGrantType is the OAuth 2.0 device authorization grant (RFC 8628).
+const DeviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Error codes of the token endpoint while a device code is being authorized
+// (RFC 8628, section 3.5).
+const (
+	ErrAuthorizationPending = "authorization_pending"
+	ErrSlowDown             = "slow_down"
+	ErrAccessDenied         = "access_denied"
+	ErrExpiredToken         = "expired_token"
+)
+
+// DeviceCode is a pending device authorization. The user enters UserCode at
+// VerificationURI while the CLI polls for a token with DeviceCode.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	// ExpiresIn and Interval are in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval,omitempty"`
+}
+
+// PollInterval returns how long to wait between token requests, 5 seconds
+// when the server does not say.
+func (d *DeviceCode) PollInterval() time.Duration {
+	if d.Interval <= 0 {
+		return 5 * time.Second
+	}
+	return time.Duration(d.Interval) * time.Second
+}
+
+// Token is an access token issued to the CLI.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	// ExpiresIn is in seconds, zero when the token does not expire.
+	ExpiresIn int `json:"expires_in,omitempty"`
+}
+
+// User is the identity a token belongs to.
+type User struct {
+	ID           string `json:"id"`
+	Email        string `json:"email"`
+	Name         string `json:"name,omitempty"`
+	Organization string `json:"organization,omitempty"`
+}
+
+// OAuthError is an error response of the OAuth endpoints, which carry the
+// error code as a top-level string instead of the API's error object.
+type OAuthError struct {
+	StatusCode  int
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return fmt.Sprintf("authorization failed: %s (%d)", e.Code, e.StatusCode)
+	}
+	return fmt.Sprintf("authorization failed: %s: %s (%d)", e.Code, e.Description, e.StatusCode)
+}
+
+// postForm sends an application/x-www-form-urlencoded request to an OAuth
+// endpoint and decodes the JSON response into out.
+func (c *Client) postForm(ctx context.Context, path string, form url.Values, out any) error {
+	req, err := c.newRequest(ctx, http.MethodPost, path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	req, cancel := c.withTimeout(req)
+	defer cancel()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.BaseURL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		oauthErr := &OAuthError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, oauthErr) == nil && oauthErr.Code != "" {
+			return oauthErr
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode API response: %w", err)
+	}
+	return nil
+}
+
+// RequestDeviceCode starts a device authorization.
+func (c *Client) RequestDeviceCode(ctx context.Context) (*DeviceCode, error) {
+	var code DeviceCode
+	if err := c.postForm(ctx, "/v1/oauth/device/code", url.Values{"client_id": {ClientID}}, &code); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// RequestToken exchanges an authorized device code for a token. Until the
+// user approves it, the request fails with an *OAuthError whose code is
+// ErrAuthorizationPending.
+func (c *Client) RequestToken(ctx context.Context, deviceCode string) (*Token, error) {
+	form := url.Values{
+		"grant_type":  {DeviceCodeGrantType},
+		"device_code": {deviceCode},
+		"client_id":   {ClientID},
+	}
+	var token Token
+	if err := c.postForm(ctx, "/v1/oauth/token", form, &token); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// WaitForToken polls for a token every interval until the user approves or
+// denies the device code, or the code expires. The interval grows by 5
+// seconds whenever the server asks to slow down.
+func (c *Client) WaitForToken(ctx context.Context, code *DeviceCode, interval time.Duration) (*Token, error) {
+	if code.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("the code %s expired before it was approved", code.UserCode)
+			}
+			return nil, err
+		}
+
+		token, err := c.RequestToken(ctx, code.DeviceCode)
+		var oauthErr *OAuthError
+		switch {
+		case err == nil:
+			return token, nil
+		case errors.As(err, &oauthErr) && oauthErr.Code == ErrAuthorizationPending:
+		case errors.As(err, &oauthErr) && oauthErr.Code == ErrSlowDown:
+			interval += 5 * time.Second
+		case errors.As(err, &oauthErr) && oauthErr.Code == ErrAccessDenied:
+			return nil, fmt.Errorf("the login request was denied")
+		case errors.As(err, &oauthErr) && oauthErr.Code == ErrExpiredToken:
+			return nil, fmt.Errorf("the code %s expired before it was approved", code.UserCode)
+		case errors.As(err, &oauthErr):
+			return nil, err
+		case temporary(err):
+		default:
+			return nil, err
+		}
+	}
+}
+
+// WhoAmI returns the user the client's token belongs to.
+func (c *Client) WhoAmI(ctx context.Context) (*User, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/me", nil)
+	if err != nil {
+		return nil, err
+	}
+	var user User
+	if err := c.do(req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/squadbase/squadbase/version"
+)
+
+// DefaultBaseURL is the Squadbase API used when no other base URL is configured.
+const DefaultBaseURL = "https://api.squadbase.dev"
+
+// DefaultRequestTimeout bounds API calls other than bundle uploads and log
+// streams, which take as long as they need.
+const DefaultRequestTimeout = time.Minute
+
+// Client talks to the Squadbase API.
+type Client struct {
+	BaseURL        *url.URL
+	Token          string
+	HTTPClient     *http.Client
+	RequestTimeout time.Duration
+}
+
+// NewClient returns a client for the API at baseURL, or DefaultBaseURL when
+// it is empty, that authenticates with token.
+func NewClient(baseURL string, token string) (*Client, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid API URL %q", baseURL)
+	}
+	return &Client{
+		BaseURL:        u,
+		Token:          token,
+		HTTPClient:     &http.Client{},
+		RequestTimeout: DefaultRequestTimeout,
+	}, nil
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API request failed: %s", http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("API request failed: %s (%d)", e.Message, e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 response of the API.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// IsUnauthorized reports whether err is a 401 response of the API.
+func IsUnauthorized(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// temporary reports whether a request that failed with err may succeed when
+// it is retried.
+func temporary(err error) bool {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// URL returns the absolute URL of an API path.
+func (c *Client) URL(path string) string {
+	return c.BaseURL.String() + path
+}
+
+// newRequest builds a request for an API path. A non-nil body that is not an
+// io.Reader is sent as JSON.
+func (c *Client) newRequest(ctx context.Context, method string, path string, body any) (*http.Request, error) {
+	var reader io.Reader
+	contentType := ""
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.URL(path), reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "squad/"+version.Version)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	return req, nil
+}
+
+// withTimeout bounds req with the client's RequestTimeout, when it is set.
+func (c *Client) withTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	if c.RequestTimeout <= 0 {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), c.RequestTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// do sends the request within the RequestTimeout and decodes a JSON response
+// into out, unless out is nil.
+func (c *Client) do(req *http.Request, out any) error {
+	req, cancel := c.withTimeout(req)
+	defer cancel()
+	return c.send(req, out)
+}
+
+// send is do without the RequestTimeout.
+func (c *Client) send(req *http.Request, out any) error {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.BaseURL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode API response: %w", err)
+	}
+	return nil
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var body struct {
+		Error *Error `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && body.Error != nil {
+		apiErr.Code = body.Error.Code
+		apiErr.Message = body.Error.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(data))
+	}
+	return apiErr
+}
+
+// Backoff is the schedule of retries and polls: the delay starts at Initial
+// and grows by Factor up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+	Factor  float64
+}
+
+// DefaultBackoff polls after 1s, 1.5s, 2.25s and so on, up to every 10s.
+var DefaultBackoff = Backoff{Initial: time.Second, Max: 10 * time.Second, Factor: 1.5}
+
+// Next returns the delay that follows delay, or Initial when delay is zero.
+func (b Backoff) Next(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return b.Initial
+	}
+	next := time.Duration(float64(delay) * b.Factor)
+	if next > b.Max {
+		return b.Max
+	}
+	return next
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
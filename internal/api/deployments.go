@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Deployment statuses. A deployment moves from queued through building and
+// deploying to one of the final statuses ready, failed or canceled.
+const (
+	StatusQueued    = "queued"
+	StatusBuilding  = "building"
+	StatusDeploying = "deploying"
+	StatusReady     = "ready"
+	StatusFailed    = "failed"
+	StatusCanceled  = "canceled"
+)
+
+// Bundle is an uploaded project bundle.
+type Bundle struct {
+	ID     string `json:"id"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Deployment is one deployment of a project to a target.
+type Deployment struct {
+	ID          string `json:"id"`
+	Project     string `json:"project"`
+	Target      string `json:"target"`
+	Environment string `json:"environment,omitempty"`
+	Status      string `json:"status"`
+	// Message describes the current step, or the reason of a failure.
+	Message  string `json:"message,omitempty"`
+	URL      string `json:"url,omitempty"`
+	BundleID string `json:"bundle_id"`
+	// Commit and Author identify the git commit the bundle was made from.
+	Commit string `json:"commit,omitempty"`
+	Author string `json:"author,omitempty"`
+	// Config is the squadbase.yml the deployment was made with, and
+	// ConfigSHA256 its hash.
+	Config       string `json:"config,omitempty"`
+	ConfigSHA256 string `json:"config_sha256,omitempty"`
+	// Live is set on the deployment that currently serves its target.
+	Live bool `json:"live"`
+	// RollbackOf is the ID of the deployment a rollback restored.
+	RollbackOf string    `json:"rollback_of,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Done reports whether the deployment reached a final status.
+func (d *Deployment) Done() bool {
+	return d.Status == StatusReady || d.Status == StatusFailed || d.Status == StatusCanceled
+}
+
+// CreateDeploymentRequest starts a deployment of an uploaded bundle.
+type CreateDeploymentRequest struct {
+	BundleID    string `json:"bundle_id"`
+	Target      string `json:"target"`
+	Environment string `json:"environment,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	Author      string `json:"author,omitempty"`
+	// Config is the validated squadbase.yml, with the environment overlay
+	// merged and variables resolved.
+	Config string `json:"config"`
+}
+
+func projectPath(project string) string {
+	return "/v1/projects/" + url.PathEscape(project)
+}
+
+// UploadBundle uploads a bundle of size bytes with the given sha256 hash.
+func (c *Client) UploadBundle(ctx context.Context, project string, bundle io.Reader, size int64, sha256 string) (*Bundle, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, projectPath(project)+"/bundles", bundle)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/gzip")
+	req.Header.Set("X-Content-Sha256", sha256)
+
+	// Large bundles on slow links take as long as they take, so the
+	// RequestTimeout does not apply.
+	var uploaded Bundle
+	if err := c.send(req, &uploaded); err != nil {
+		return nil, err
+	}
+	if uploaded.SHA256 != "" && uploaded.SHA256 != sha256 {
+		return nil, fmt.Errorf("bundle was corrupted during upload: sent sha256 %s, received %s", sha256, uploaded.SHA256)
+	}
+	return &uploaded, nil
+}
+
+// CreateDeployment starts a deployment.
+func (c *Client) CreateDeployment(ctx context.Context, project string, request *CreateDeploymentRequest) (*Deployment, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, projectPath(project)+"/deployments", request)
+	if err != nil {
+		return nil, err
+	}
+	var deployment Deployment
+	if err := c.do(req, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// GetDeployment returns a deployment of the project.
+func (c *Client) GetDeployment(ctx context.Context, project string, id string) (*Deployment, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, projectPath(project)+"/deployments/"+url.PathEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	var deployment Deployment
+	if err := c.do(req, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// ListDeploymentsOptions filters the deployment history.
+type ListDeploymentsOptions struct {
+	// Target limits the history to one deployment target when not empty.
+	Target string
+	// Limit is the most deployments to return, the server's default when zero.
+	Limit int
+}
+
+// ListDeployments returns the deployments of the project, newest first.
+func (c *Client) ListDeployments(ctx context.Context, project string, opts ListDeploymentsOptions) ([]*Deployment, error) {
+	q := url.Values{}
+	if opts.Target != "" {
+		q.Set("target", opts.Target)
+	}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	path := projectPath(project) + "/deployments"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Deployments []*Deployment `json:"deployments"`
+	}
+	if err := c.do(req, &list); err != nil {
+		return nil, err
+	}
+	return list.Deployments, nil
+}
+
+// Rollback starts a deployment that promotes the bundle and config of an
+// earlier deployment back to its target.
+func (c *Client) Rollback(ctx context.Context, project string, id string) (*Deployment, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, projectPath(project)+"/deployments/"+url.PathEscape(id)+"/rollback", nil)
+	if err != nil {
+		return nil, err
+	}
+	var deployment Deployment
+	if err := c.do(req, &deployment); err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// maxPollFailures is how many polls in a row may fail with a temporary error
+// before WaitForDeployment gives up.
+const maxPollFailures = 5
+
+// WaitForDeployment polls the deployment with backoff until it reaches a
+// final status. onUpdate is called whenever the status or message changes.
+// The backoff starts over after every change, so that progress shows up quickly.
+func (c *Client) WaitForDeployment(ctx context.Context, project string, id string, backoff Backoff, onUpdate func(*Deployment)) (*Deployment, error) {
+	var last *Deployment
+	var delay time.Duration
+	failures := 0
+	for {
+		deployment, err := c.GetDeployment(ctx, project, id)
+		switch {
+		case err != nil && temporary(err) && failures < maxPollFailures:
+			failures++
+		case err != nil:
+			return last, err
+		default:
+			failures = 0
+			if last == nil || deployment.Status != last.Status || deployment.Message != last.Message {
+				delay = 0
+				if onUpdate != nil {
+					onUpdate(deployment)
+				}
+			}
+			last = deployment
+			if deployment.Done() {
+				return deployment, nil
+			}
+		}
+
+		delay = backoff.Next(delay)
+		if err := sleep(ctx, delay); err != nil {
+			return last, err
+		}
+	}
+}
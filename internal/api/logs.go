@@ -0,0 +1,254 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Log severities, from least to most severe.
+const (
+	SeverityDebug   = "debug"
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+var severities = []string{SeverityDebug, SeverityInfo, SeverityWarning, SeverityError}
+
+// ParseSeverity normalizes a severity name, accepting any case and "warn"
+// for warning.
+func ParseSeverity(s string) (string, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warn" {
+		s = SeverityWarning
+	}
+	for _, severity := range severities {
+		if s == severity {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown severity %q, expected one of %s", s, strings.Join(severities, ", "))
+}
+
+// SeverityRank orders severities; unknown severities rank as info.
+func SeverityRank(severity string) int {
+	for i, s := range severities {
+		if strings.EqualFold(severity, s) {
+			return i
+		}
+	}
+	return 1
+}
+
+// LogEntry is one line of an app's runtime logs.
+type LogEntry struct {
+	ID       string    `json:"id"`
+	Time     time.Time `json:"time"`
+	Severity string    `json:"severity"`
+	Message  string    `json:"message"`
+	Target   string    `json:"target,omitempty"`
+	Instance string    `json:"instance,omitempty"`
+	Revision string    `json:"revision,omitempty"`
+}
+
+// LogsOptions selects the log entries to read.
+type LogsOptions struct {
+	Target string
+	// Since and Until limit the entries to a time range when they are not zero.
+	Since time.Time
+	Until time.Time
+	// Severity is the least severity to include, all entries when empty.
+	Severity string
+	// Follow keeps the stream open for new entries.
+	Follow bool
+}
+
+func (o *LogsOptions) query(after string) url.Values {
+	q := url.Values{}
+	if o.Target != "" {
+		q.Set("target", o.Target)
+	}
+	if !o.Since.IsZero() {
+		q.Set("since", o.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if !o.Until.IsZero() {
+		q.Set("until", o.Until.UTC().Format(time.RFC3339Nano))
+	}
+	if o.Severity != "" {
+		q.Set("severity", o.Severity)
+	}
+	if o.Follow {
+		q.Set("follow", "true")
+	}
+	if after != "" {
+		q.Set("after", after)
+	}
+	return q
+}
+
+// match reports whether the entry is within the options, in case the server
+// sent more than was asked for.
+func (o *LogsOptions) match(entry *LogEntry) bool {
+	if !o.Since.IsZero() && entry.Time.Before(o.Since) {
+		return false
+	}
+	if !o.Until.IsZero() && entry.Time.After(o.Until) {
+		return false
+	}
+	return o.Severity == "" || SeverityRank(entry.Severity) >= SeverityRank(o.Severity)
+}
+
+// StreamLogs calls onEntry for every log entry of the project. The server
+// may stream them as server-sent events or as newline-delimited JSON over a
+// chunked response. When following, a dropped stream is reopened with
+// backoff after the last entry received, until ctx is done. Temporary
+// failures are retried in both modes; without following, at most
+// maxPollFailures times in a row.
+func (c *Client) StreamLogs(ctx context.Context, project string, opts LogsOptions, backoff Backoff, onEntry func(*LogEntry)) error {
+	after := ""
+	var delay time.Duration
+	failures := 0
+	for {
+		received, err := c.streamLogs(ctx, project, &opts, after, func(entry *LogEntry) {
+			if entry.ID != "" {
+				after = entry.ID
+			}
+			if opts.match(entry) {
+				onEntry(entry)
+			}
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && !opts.Follow {
+			return nil
+		}
+		if err != nil {
+			failures++
+			if !temporary(err) || (!opts.Follow && failures >= maxPollFailures) {
+				return err
+			}
+		}
+
+		if received > 0 {
+			delay = 0
+			failures = 0
+		}
+		delay = backoff.Next(delay)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// streamLogs reads one response of the logs endpoint and returns how many
+// entries it contained.
+func (c *Client) streamLogs(ctx context.Context, project string, opts *LogsOptions, after string, onEntry func(*LogEntry)) (int, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, projectPath(project)+"/logs?"+opts.query(after).Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "text/event-stream, application/x-ndjson")
+
+	// Streams stay open for as long as they are followed, so the
+	// RequestTimeout does not apply.
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach %s: %w", c.BaseURL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return 0, decodeError(resp)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		return readEvents(resp.Body, onEntry)
+	}
+	return readLines(resp.Body, onEntry)
+}
+
+func newLogScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return scanner
+}
+
+// readLines reads one JSON log entry per line.
+func readLines(r io.Reader, onEntry func(*LogEntry)) (int, error) {
+	received := 0
+	scanner := newLogScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return received, fmt.Errorf("failed to decode log entry: %w", err)
+		}
+		received++
+		onEntry(&entry)
+	}
+	return received, scanner.Err()
+}
+
+// readEvents reads server-sent events. Events named "log", or without a
+// name, carry a JSON log entry; others, such as keep-alives, are skipped.
+func readEvents(r io.Reader, onEntry func(*LogEntry)) (int, error) {
+	received := 0
+	var event, id string
+	var data []string
+	dispatch := func() error {
+		defer func() { event, id, data = "", "", nil }()
+		if len(data) == 0 || (event != "" && event != "log") {
+			return nil
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &entry); err != nil {
+			return fmt.Errorf("failed to decode log entry: %w", err)
+		}
+		if entry.ID == "" {
+			entry.ID = id
+		}
+		received++
+		onEntry(&entry)
+		return nil
+	}
+
+	scanner := newLogScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return received, err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			id = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, dispatch()
+}
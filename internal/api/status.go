@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Health of a deployed app, as seen by the platform's health checks.
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"
+	HealthUnhealthy = "unhealthy"
+	HealthUnknown   = "unknown"
+)
+
+// TargetStatus is what currently runs on one deployment target of a project.
+type TargetStatus struct {
+	Target   string `json:"target"`
+	Provider string `json:"provider"`
+	Region   string `json:"region,omitempty"`
+	// Revision is the running revision, and DeploymentID the deployment that
+	// created it.
+	Revision     string `json:"revision,omitempty"`
+	DeploymentID string `json:"deployment_id,omitempty"`
+	URL          string `json:"url,omitempty"`
+	Health       string `json:"health"`
+	// HealthMessage explains a health other than healthy.
+	HealthMessage   string    `json:"health_message,omitempty"`
+	Instances       int       `json:"instances"`
+	DeployedAt      time.Time `json:"deployed_at"`
+	HealthCheckedAt time.Time `json:"health_checked_at"`
+}
+
+// ProjectStatus is the status of every deployment target of a project.
+type ProjectStatus struct {
+	Project string          `json:"project"`
+	Targets []*TargetStatus `json:"targets"`
+}
+
+// GetStatus returns the status of the project, limited to one target unless
+// target is empty.
+func (c *Client) GetStatus(ctx context.Context, project string, target string) (*ProjectStatus, error) {
+	path := projectPath(project) + "/status"
+	if target != "" {
+		path += "?" + url.Values{"target": {target}}.Encode()
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var status ProjectStatus
+	if err := c.do(req, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TokenEnv overrides the stored credentials, for CI and other
+// non-interactive use.
+const TokenEnv = "SQUAD_TOKEN"
+
+// DefaultAccount is the name of the account used when none is given.
+const DefaultAccount = "default"
+
+// Account is a logged in identity.
+type Account struct {
+	Token     string    `yaml:"token"`
+	APIURL    string    `yaml:"api_url"`
+	Email     string    `yaml:"email,omitempty"`
+	UserID    string    `yaml:"user_id,omitempty"`
+	CreatedAt time.Time `yaml:"created_at"`
+}
+
+// Credentials are the accounts the CLI is logged in with. Current names the
+// account used by default.
+type Credentials struct {
+	Current  string              `yaml:"current,omitempty"`
+	Accounts map[string]*Account `yaml:"accounts,omitempty"`
+}
+
+// Path returns the credentials file inside the user config directory, for
+// example ~/.config/squadbase/credentials.yml on Linux.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "squadbase", "credentials.yml"), nil
+}
+
+// Load reads the credentials file at path. A missing file yields no accounts.
+func Load(path string) (*Credentials, error) {
+	creds := &Credentials{Accounts: map[string]*Account{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return creds, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	if err := yaml.Unmarshal(data, creds); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if creds.Accounts == nil {
+		creds.Accounts = map[string]*Account{}
+	}
+	return creds, nil
+}
+
+// Save writes the credentials to path, readable only by the current user.
+// The file is replaced atomically so that a failed write never loses the
+// accounts already stored.
+func (c *Credentials) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+// Names returns the account names in order.
+func (c *Credentials) Names() []string {
+	names := make([]string, 0, len(c.Accounts))
+	for name := range c.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Account returns the named account, or the current one when name is empty.
+func (c *Credentials) Account(name string) (string, *Account, error) {
+	if name == "" {
+		name = c.Current
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("not logged in")
+	}
+	account, ok := c.Accounts[name]
+	if !ok {
+		return name, nil, fmt.Errorf("not logged in as %q", name)
+	}
+	return name, account, nil
+}
+
+// Set stores the account under name and makes it the current one.
+func (c *Credentials) Set(name string, account *Account) {
+	c.Accounts[name] = account
+	c.Current = name
+}
+
+// Remove deletes the named account. When it was the current account, the
+// first remaining account becomes current.
+func (c *Credentials) Remove(name string) bool {
+	if _, ok := c.Accounts[name]; !ok {
+		return false
+	}
+	delete(c.Accounts, name)
+	if c.Current == name {
+		c.Current = ""
+		if names := c.Names(); len(names) > 0 {
+			c.Current = names[0]
+		}
+	}
+	return true
+}
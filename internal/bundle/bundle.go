@@ -0,0 +1,279 @@
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestName is the file at the root of a bundle that lists the sha256
+// hash of every other file, in the format of sha256sum.
+const ManifestName = "SHA256SUMS"
+
+// File is a file of the project that goes into a bundle.
+type File struct {
+	// Path is slash-separated and relative to the project directory.
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// Link is the target of a symbolic link.
+	Link       string `json:"link,omitempty"`
+	executable bool
+}
+
+// Bundle describes a written bundle.
+type Bundle struct {
+	Path   string `json:"path"`
+	Files  []File `json:"files"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ContentSize returns the total size of the files before compression.
+func (b *Bundle) ContentSize() int64 {
+	var total int64
+	for _, file := range b.Files {
+		total += file.Size
+	}
+	return total
+}
+
+// Largest returns up to n files, largest first.
+func (b *Bundle) Largest(n int) []File {
+	files := append([]File{}, b.Files...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// Collect lists the files of directory that m does not ignore, sorted by
+// path, with their sha256 hashes.
+func Collect(directory string, m *Matcher) ([]File, error) {
+	files := []File{}
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if m.Match(rel, entry.IsDir()) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		file := File{Path: rel}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			file.Link = filepath.ToSlash(link)
+			file.SHA256 = hashBytes([]byte(file.Link))
+		case info.Mode().IsRegular():
+			file.Size = info.Size()
+			file.executable = info.Mode()&0111 != 0
+			file.SHA256, err = hashFile(path)
+			if err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect project files: %w", err)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// Manifest renders the sha256sum listing of files.
+func Manifest(files []File) []byte {
+	var out strings.Builder
+	for _, file := range files {
+		fmt.Fprintf(&out, "%s  %s\n", file.SHA256, file.Path)
+	}
+	return []byte(out.String())
+}
+
+// Write writes files of directory as a gzipped tarball to w, preceded by the
+// manifest. Everything that varies between machines and checkouts is left
+// out: modification times are zeroed, owners are dropped and modes are
+// normalized to 0644 or 0755, so the same sources give the same bytes.
+func Write(w io.Writer, directory string, files []File) error {
+	gz, err := gzip.NewWriterLevel(w, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(gz)
+
+	manifest := Manifest(files)
+	if err := tw.WriteHeader(header(ManifestName, int64(len(manifest)), 0644)); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestName, err)
+	}
+	if _, err := tw.Write(manifest); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestName, err)
+	}
+
+	for _, file := range files {
+		if file.Link != "" {
+			h := header(file.Path, 0, 0777)
+			h.Typeflag = tar.TypeSymlink
+			h.Linkname = file.Link
+			if err := tw.WriteHeader(h); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file.Path, err)
+			}
+			continue
+		}
+
+		mode := int64(0644)
+		if file.executable {
+			mode = 0755
+		}
+		if err := tw.WriteHeader(header(file.Path, file.Size, mode)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		if err := copyFile(tw, filepath.Join(directory, filepath.FromSlash(file.Path)), file.Size); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Create writes the bundle of the project in directory to output.
+func Create(directory string, output string) (*Bundle, error) {
+	m, err := LoadIgnore(directory)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(directory, output); err == nil && !strings.HasPrefix(rel, "..") {
+		if err := m.Add("/" + filepath.ToSlash(rel)); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := Collect(directory, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(output), err)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	if err := Write(io.MultiWriter(f, hash, counter), directory, files); err != nil {
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return &Bundle{
+		Path:   output,
+		Files:  files,
+		Size:   counter.n,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+func header(name string, size int64, mode int64) *tar.Header {
+	return &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     mode,
+		ModTime:  time.Unix(0, 0),
+		Format:   tar.FormatPAX,
+	}
+}
+
+// copyFile copies exactly size bytes, so that a file that changes while the
+// bundle is written fails instead of corrupting the archive.
+func copyFile(w io.Writer, path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := io.Copy(w, io.LimitReader(f, size))
+	if err != nil {
+		return err
+	}
+	if n != size {
+		return fmt.Errorf("file changed while it was being read")
+	}
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
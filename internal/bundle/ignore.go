@@ -0,0 +1,197 @@
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const IgnoreFileName = ".squadignore"
+
+// DefaultIgnores are left out of every bundle, before the rules of .squadignore.
+// A .squadignore can bring them back with a negated pattern.
+var DefaultIgnores = []string{
+	".git/",
+	".squad/",
+	".venv/",
+	"venv/",
+	"node_modules/",
+	"__pycache__/",
+	"*.py[cod]",
+	".pytest_cache/",
+	".mypy_cache/",
+	".ruff_cache/",
+	".next/",
+	".env",
+	".env.*",
+	"!.env.example",
+	".DS_Store",
+}
+
+// rule is one pattern of an ignore file.
+type rule struct {
+	pattern  *regexp.Regexp
+	negate   bool
+	dirOnly  bool
+	original string
+}
+
+// Matcher decides which paths are left out of a bundle, with gitignore syntax.
+type Matcher struct {
+	rules []rule
+}
+
+// LoadIgnore returns the default rules followed by the rules of the
+// .squadignore in directory, if there is one.
+func LoadIgnore(directory string) (*Matcher, error) {
+	m, err := ParseIgnore(strings.Join(DefaultIgnores, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(directory, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFileName, err)
+	}
+	custom, err := ParseIgnore(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", IgnoreFileName, err)
+	}
+	m.rules = append(m.rules, custom.rules...)
+	return m, nil
+}
+
+// ParseIgnore parses the lines of an ignore file.
+func ParseIgnore(content string) (*Matcher, error) {
+	m := &Matcher{}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := parseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		m.rules = append(m.rules, r)
+	}
+	return m, nil
+}
+
+// Add appends a rule, e.g. for the bundle being written inside the project.
+func (m *Matcher) Add(pattern string) error {
+	r, err := parseRule(pattern)
+	if err != nil {
+		return err
+	}
+	m.rules = append(m.rules, r)
+	return nil
+}
+
+// Match reports whether the slash-separated path, relative to the project
+// directory, is ignored. The last matching rule wins.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if r.pattern.MatchString(path) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+func parseRule(line string) (rule, error) {
+	r := rule{original: line}
+	switch {
+	case strings.HasPrefix(line, "!"):
+		r.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return r, fmt.Errorf("empty pattern %q", r.original)
+	}
+
+	// Patterns without a slash match at any depth; the others are
+	// relative to the project directory.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	segments := strings.Split(line, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			if last {
+				expr.WriteString(".*")
+			} else {
+				expr.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+		if err := writeSegment(&expr, segment); err != nil {
+			return r, fmt.Errorf("invalid pattern %q: %w", r.original, err)
+		}
+		if !last {
+			expr.WriteString("/")
+		}
+	}
+	expr.WriteString("$")
+
+	pattern, err := regexp.Compile(expr.String())
+	if err != nil {
+		return r, fmt.Errorf("invalid pattern %q: %w", r.original, err)
+	}
+	r.pattern = pattern
+	return r, nil
+}
+
+// writeSegment translates the wildcards of one path segment.
+func writeSegment(expr *strings.Builder, segment string) error {
+	for i := 0; i < len(segment); i++ {
+		switch c := segment[i]; c {
+		case '*':
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '\\':
+			if i+1 < len(segment) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(segment[i : i+1]))
+			}
+		case '[':
+			end := strings.IndexByte(segment[i+1:], ']')
+			if end < 0 {
+				return fmt.Errorf("unterminated character class")
+			}
+			class := segment[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return nil
+}
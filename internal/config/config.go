@@ -0,0 +1,113 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+const FileName = "squadbase.yml"
+
+type Config struct {
+	Version    string     `yaml:"version"`
+	Build      Build      `yaml:"build"`
+	Deployment Deployment `yaml:"deployment"`
+	Traffic    *Traffic   `yaml:"traffic,omitempty"`
+	Env        []EnvVar   `yaml:"env,omitempty"`
+	Services   []Service  `yaml:"services,omitempty"`
+}
+
+type Build struct {
+	UseCustomDockerfile bool     `yaml:"use_custom_dockerfile,omitempty"`
+	Runtime             string   `yaml:"runtime,omitempty"`
+	Framework           string   `yaml:"framework,omitempty"`
+	PackageManager      string   `yaml:"package_manager,omitempty"`
+	Entrypoint          string   `yaml:"entrypoint,omitempty"`
+	Context             string   `yaml:"context,omitempty"`
+	BuildArgs           []string `yaml:"build_args,omitempty"`
+	// RuntimeArgs names the build args that are also set as environment
+	// variables of the app. They end up in the image config, so secrets do
+	// not belong here.
+	RuntimeArgs []string `yaml:"runtime_args,omitempty"`
+}
+
+type Deployment struct {
+	Provider      string  `yaml:"provider,omitempty"`
+	AWS           *AWS    `yaml:"aws,omitempty"`
+	GCP           *GCP    `yaml:"gcp,omitempty"`
+	Azure         *Azure  `yaml:"azure,omitempty"`
+	Targets       Targets `yaml:"targets,omitempty"`
+	DefaultTarget string  `yaml:"default_target,omitempty"`
+}
+
+type AWS struct {
+	Preset                 string `yaml:"preset,omitempty"`
+	Region                 string `yaml:"region,omitempty"`
+	Memory                 int    `yaml:"memory,omitempty"`
+	Timeout                int    `yaml:"timeout,omitempty"`
+	ProvisionedConcurrency int    `yaml:"provisioned_concurrency,omitempty"`
+	EphemeralStorage       string `yaml:"ephemeral_storage,omitempty"`
+}
+
+type GCP struct {
+	Preset           string  `yaml:"preset,omitempty"`
+	Region           string  `yaml:"region,omitempty"`
+	Memory           int     `yaml:"memory,omitempty"`
+	CPU              float64 `yaml:"cpu,omitempty"`
+	Concurrency      int     `yaml:"concurrency,omitempty"`
+	Timeout          int     `yaml:"timeout,omitempty"`
+	MinInstances     int     `yaml:"min_instances,omitempty"`
+	EphemeralStorage string  `yaml:"ephemeral_storage,omitempty"`
+}
+
+type Azure struct {
+	Preset      string  `yaml:"preset,omitempty"`
+	Region      string  `yaml:"region,omitempty"`
+	CPU         float64 `yaml:"cpu,omitempty"`
+	Memory      int     `yaml:"memory,omitempty"`
+	MinReplicas int     `yaml:"min_replicas,omitempty"`
+	MaxReplicas int     `yaml:"max_replicas,omitempty"`
+	Concurrency int     `yaml:"concurrency,omitempty"`
+}
+
+// Path returns the location of squadbase.yml inside the given project directory.
+func Path(directory string) string {
+	return filepath.Join(directory, FileName)
+}
+
+// Exists reports whether the given project directory already contains a squadbase.yml.
+func Exists(directory string) bool {
+	info, err := os.Stat(Path(directory))
+	return err == nil && !info.IsDir()
+}
+
+func Load(directory string) (*Config, error) {
+	data, err := os.ReadFile(Path(directory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	return &cfg, nil
+}
+
+var runtimePattern = regexp.MustCompile(`^(python|nodejs)(\d+(?:\.\d+)?)$`)
+
+// ParseRuntime splits a runtime such as "python3.10" or "nodejs18" into its
+// language and version parts. Unrecognized runtimes yield empty strings.
+func ParseRuntime(runtime string) (language string, version string) {
+	matches := runtimePattern.FindStringSubmatch(runtime)
+	if len(matches) != 3 {
+		return "", ""
+	}
+	return matches[1], matches[2]
+}
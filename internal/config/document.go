@@ -0,0 +1,238 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/diff"
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a squadbase.yml kept as a YAML node tree, so that values can be
+// changed in place without losing comments, key order or unknown keys.
+type Document struct {
+	original []byte
+	root     yaml.Node
+}
+
+func ReadDocument(directory string) (*Document, error) {
+	data, err := os.ReadFile(Path(directory))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", FileName, err)
+	}
+	return ParseDocument(data)
+}
+
+func ParseDocument(data []byte) (*Document, error) {
+	doc := &Document{original: data}
+	if err := yaml.Unmarshal(data, &doc.root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if doc.root.Kind == 0 {
+		doc.root = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	if doc.mapping() == nil {
+		return nil, fmt.Errorf("failed to parse %s: top level must be a mapping", FileName)
+	}
+	return doc, nil
+}
+
+// Original returns the bytes the document was parsed from.
+func (d *Document) Original() []byte {
+	return d.original
+}
+
+// Decode unmarshals the current state of the document into a Config.
+func (d *Document) Decode() (*Config, error) {
+	var cfg Config
+	if err := d.root.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	return &cfg, nil
+}
+
+// Lookup returns the node at the given key path, or nil if any key is missing.
+func (d *Document) Lookup(path ...string) *yaml.Node {
+	node := d.mapping()
+	for _, key := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		node = mappingValue(node, key)
+	}
+	return node
+}
+
+// GetString returns the scalar value at the given key path, or an empty
+// string when the key does not exist or is not a scalar.
+func (d *Document) GetString(path ...string) string {
+	node := d.Lookup(path...)
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+// SetString sets the scalar value at the given key path, creating missing
+// mappings along the way. Existing nodes keep their style and comments.
+func (d *Document) SetString(value string, path ...string) {
+	node := d.ensure(path...)
+	if node.Kind != yaml.ScalarNode {
+		*node = yaml.Node{Kind: yaml.ScalarNode}
+	}
+	node.Tag = ""
+	node.Value = value
+	if node.Style == 0 && needsQuoting(value) {
+		node.Style = yaml.SingleQuotedStyle
+	}
+}
+
+// SetValue encodes value as YAML and stores it at the given key path,
+// creating missing mappings along the way.
+func (d *Document) SetValue(value any, path ...string) error {
+	var encoded yaml.Node
+	if err := encoded.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", strings.Join(path, "."), err)
+	}
+	d.SetNode(&encoded, path...)
+	return nil
+}
+
+// SetNode replaces the value at the given key path with the given node.
+func (d *Document) SetNode(value *yaml.Node, path ...string) {
+	node := d.ensure(path...)
+	headComment := node.HeadComment
+	lineComment := node.LineComment
+	*node = *value
+	if node.HeadComment == "" {
+		node.HeadComment = headComment
+	}
+	if node.LineComment == "" {
+		node.LineComment = lineComment
+	}
+}
+
+// Delete removes the key at the given path. It reports whether a key was removed.
+func (d *Document) Delete(path ...string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	parent := d.Lookup(path[:len(path)-1]...)
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return false
+	}
+	key := path[len(path)-1]
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == key {
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Bytes renders the document with the indentation used by generated
+// squadbase.yml files. Blank lines from the original file are kept.
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(4)
+	if err := encoder.Encode(&d.root); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", FileName, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", FileName, err)
+	}
+	return restoreBlankLines(d.original, buf.Bytes()), nil
+}
+
+// Diff returns a unified diff between the original file and the current
+// document, or an empty string when nothing changed.
+func (d *Document) Diff(name string) (string, error) {
+	updated, err := d.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return diff.Unified(string(d.original), string(updated), name, name), nil
+}
+
+func (d *Document) WriteFile(directory string) error {
+	data, err := d.Bytes()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(Path(directory), data, 0644)
+}
+
+func (d *Document) mapping() *yaml.Node {
+	if d.root.Kind != yaml.DocumentNode || len(d.root.Content) == 0 {
+		return nil
+	}
+	node := d.root.Content[0]
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	return node
+}
+
+func (d *Document) ensure(path ...string) *yaml.Node {
+	node := d.mapping()
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		next := mappingValue(node, key)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+				next,
+			)
+		}
+		node = next
+	}
+	return node
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func needsQuoting(value string) bool {
+	var decoded any
+	if err := yaml.Unmarshal([]byte(value), &decoded); err != nil {
+		return true
+	}
+	s, ok := decoded.(string)
+	return !ok || s != value
+}
+
+// restoreBlankLines puts back blank lines that the YAML encoder drops when
+// re-rendering a file, so that edits only show up as the lines they touch.
+func restoreBlankLines(original, encoded []byte) []byte {
+	if len(original) == 0 {
+		return encoded
+	}
+	var out strings.Builder
+	for _, op := range diff.Lines(string(original), string(encoded)) {
+		switch op.Kind {
+		case diff.Equal, diff.Insert:
+			out.WriteString(op.Line + "\n")
+		case diff.Delete:
+			if strings.TrimSpace(op.Line) == "" {
+				out.WriteString(op.Line + "\n")
+			}
+		}
+	}
+	return []byte(out.String())
+}
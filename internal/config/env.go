@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvExampleFileName is the file documenting the declared environment
+// variables, safe to commit next to the .env file it describes.
+const EnvExampleFileName = ".env.example"
+
+// EnvVar declares an environment variable the app reads at runtime. Local
+// values come from the environment and the project's .env file.
+type EnvVar struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Required variables must have a value before the app is run.
+	Required bool `yaml:"required,omitempty" json:"required"`
+	// Secret values are masked in output.
+	Secret bool `yaml:"secret,omitempty" json:"secret"`
+}
+
+// EnvVar returns the declaration of the named variable, or nil.
+func (c *Config) EnvVar(name string) *EnvVar {
+	for i := range c.Env {
+		if c.Env[i].Name == name {
+			return &c.Env[i]
+		}
+	}
+	return nil
+}
+
+// MissingEnv returns the names of the required variables without a value
+// in values, in declaration order.
+func MissingEnv(vars []EnvVar, values map[string]string) []string {
+	missing := []string{}
+	for _, v := range vars {
+		if v.Required && values[v.Name] == "" {
+			missing = append(missing, v.Name)
+		}
+	}
+	return missing
+}
+
+// LikelySecret guesses from its name whether a variable holds a secret.
+func LikelySecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, word := range []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "PRIVATE", "DSN", "DATABASE_URL"} {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// envExampleHeader starts every generated .env.example, so that files
+// written by hand are recognized and left alone.
+const envExampleHeader = "# Environment variables declared in squadbase.yml.\n"
+
+// EnvExample renders a .env.example listing every declared variable with
+// its description. Values are left empty for the developer to fill in.
+func EnvExample(vars []EnvVar) string {
+	var content strings.Builder
+	content.WriteString(envExampleHeader)
+	content.WriteString("# Copy this file to .env and fill in the values, or use squad env set NAME=VALUE.\n")
+	for _, v := range vars {
+		content.WriteString("\n")
+		if v.Description != "" {
+			fmt.Fprintf(&content, "# %s\n", v.Description)
+		}
+		var notes []string
+		if v.Required {
+			notes = append(notes, "required")
+		}
+		if v.Secret {
+			notes = append(notes, "secret")
+		}
+		if len(notes) > 0 {
+			fmt.Fprintf(&content, "# (%s)\n", strings.Join(notes, ", "))
+		}
+		fmt.Fprintf(&content, "%s=\n", v.Name)
+	}
+	return content.String()
+}
+
+// WriteEnvExample writes the .env.example of the declared variables into
+// directory and reports whether it did. A .env.example that was not
+// generated by EnvExample is kept as it is.
+func WriteEnvExample(directory string, vars []EnvVar) (bool, error) {
+	path := filepath.Join(directory, EnvExampleFileName)
+	existing, err := os.ReadFile(path)
+	if err == nil && !strings.HasPrefix(string(existing), envExampleHeader) {
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(EnvExample(vars)), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", EnvExampleFileName, err)
+	}
+	return true, nil
+}
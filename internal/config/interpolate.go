@@ -0,0 +1,209 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceEnvironment = "environment"
+	SourceDotEnv      = ".env"
+	SourceDefault     = "default"
+)
+
+// Variables resolves names used in ${VAR} expressions. Values from the
+// process environment take precedence over values from the .env file.
+type Variables struct {
+	Environment func(string) (string, bool)
+	DotEnv      map[string]string
+}
+
+// NewVariables returns Variables backed by the process environment and the given .env values.
+func NewVariables(dotEnv map[string]string) Variables {
+	return Variables{Environment: os.LookupEnv, DotEnv: dotEnv}
+}
+
+func (v Variables) lookup(name string) (string, string, bool) {
+	if v.Environment != nil {
+		if value, ok := v.Environment(name); ok {
+			return value, SourceEnvironment, true
+		}
+	}
+	if value, ok := v.DotEnv[name]; ok {
+		return value, SourceDotEnv, true
+	}
+	return "", "", false
+}
+
+// Resolution records where the value of one ${VAR} expression came from.
+type Resolution struct {
+	Path     string
+	Line     int
+	Variable string
+	Value    string
+	Source   string
+
+	node *yaml.Node
+}
+
+// InterpolationError lists every required variable that could not be resolved.
+type InterpolationError struct {
+	Missing []Resolution
+}
+
+func (e *InterpolationError) Error() string {
+	lines := []string{fmt.Sprintf("%d required variable(s) in %s are not set (checked the environment and %s):",
+		len(e.Missing), FileName, SourceDotEnv)}
+	for _, m := range e.Missing {
+		lines = append(lines, fmt.Sprintf("  line %d, %s: ${%s}", m.Line, m.Path, m.Variable))
+	}
+	lines = append(lines, "Set them, or give a default with ${NAME:-default}.")
+	return strings.Join(lines, "\n")
+}
+
+// Interpolate replaces ${VAR} and ${VAR:-default} expressions in every value
+// of the document. $$ produces a literal $. It returns one Resolution per
+// expression, and an *InterpolationError when required variables are missing.
+func (d *Document) Interpolate(vars Variables) ([]Resolution, error) {
+	resolutions := []Resolution{}
+	missing := []Resolution{}
+
+	var walk func(node *yaml.Node, path string) error
+	walk = func(node *yaml.Node, path string) error {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if err := walk(node.Content[i+1], joinPath(path, node.Content[i].Value)); err != nil {
+					return err
+				}
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				if err := walk(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		case yaml.ScalarNode:
+			if !strings.Contains(node.Value, "$") {
+				return nil
+			}
+			value, found, err := expand(node.Value, vars)
+			if err != nil {
+				return fmt.Errorf("line %d, %s: %w", node.Line, path, err)
+			}
+			for _, r := range found {
+				r.Path = path
+				r.Line = node.Line
+				r.node = node
+				if r.Source == "" {
+					missing = append(missing, r)
+				} else {
+					resolutions = append(resolutions, r)
+				}
+			}
+			node.Value = value
+			if node.Style == 0 || node.Style == yaml.TaggedStyle {
+				node.Tag = ""
+			}
+		}
+		return nil
+	}
+
+	if err := walk(d.mapping(), ""); err != nil {
+		return nil, fmt.Errorf("failed to interpolate %s: %w", FileName, err)
+	}
+	if len(missing) > 0 {
+		return resolutions, &InterpolationError{Missing: missing}
+	}
+	return resolutions, nil
+}
+
+// AnnotateSources adds a line comment to every interpolated value naming the
+// variables it was built from and where their values came from.
+func (d *Document) AnnotateSources(resolutions []Resolution) {
+	notes := map[*yaml.Node][]string{}
+	order := []*yaml.Node{}
+	for _, r := range resolutions {
+		if _, ok := notes[r.node]; !ok {
+			order = append(order, r.node)
+		}
+		notes[r.node] = append(notes[r.node], fmt.Sprintf("${%s} from %s", r.Variable, r.Source))
+	}
+	for _, node := range order {
+		note := strings.Join(notes[node], ", ")
+		if node.LineComment != "" {
+			note = strings.TrimPrefix(node.LineComment, "# ") + "; " + note
+		}
+		node.LineComment = "# " + note
+	}
+}
+
+// expand substitutes the expressions in value. Resolutions for variables that
+// are required but unset are returned with an empty Source.
+func expand(value string, vars Variables) (string, []Resolution, error) {
+	var out strings.Builder
+	found := []Resolution{}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' {
+			out.WriteByte(value[i])
+			continue
+		}
+		if i+1 < len(value) && value[i+1] == '$' {
+			out.WriteByte('$')
+			i++
+			continue
+		}
+		if i+1 >= len(value) || value[i+1] != '{' {
+			out.WriteByte('$')
+			continue
+		}
+
+		end := strings.IndexByte(value[i:], '}')
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated expression %q", value[i:])
+		}
+		expression := value[i+2 : i+end]
+		name, fallback, hasDefault := strings.Cut(expression, ":-")
+		if !isVariableName(name) {
+			return "", nil, fmt.Errorf("invalid variable name in ${%s}", expression)
+		}
+
+		resolution := Resolution{Variable: name}
+		if resolved, source, ok := vars.lookup(name); ok && (resolved != "" || !hasDefault) {
+			resolution.Value, resolution.Source = resolved, source
+		} else if hasDefault {
+			resolution.Value, resolution.Source = fallback, SourceDefault
+		}
+		out.WriteString(resolution.Value)
+		found = append(found, resolution)
+		i += end
+	}
+
+	return out.String(), found, nil
+}
+
+func isVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var envNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// OverlayFileName returns the name of the overlay file for an environment,
+// e.g. squadbase.staging.yml for "staging".
+func OverlayFileName(env string) string {
+	return strings.TrimSuffix(FileName, ".yml") + "." + env + ".yml"
+}
+
+// OverlayPath returns the location of an environment overlay inside the given project directory.
+func OverlayPath(directory string, env string) string {
+	return filepath.Join(directory, OverlayFileName(env))
+}
+
+// Environments lists the environments that have an overlay file in the given directory.
+func Environments(directory string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(directory, strings.TrimSuffix(FileName, ".yml")+".*.yml"))
+	if err != nil {
+		return nil, err
+	}
+
+	envs := []string{}
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), strings.TrimSuffix(FileName, ".yml")+"."), ".yml")
+		if envNamePattern.MatchString(name) {
+			envs = append(envs, name)
+		}
+	}
+	sort.Strings(envs)
+	return envs, nil
+}
+
+// ReadMergedDocument reads squadbase.yml and, when env is not empty, deep-merges
+// the matching overlay file onto it. Mappings are merged key by key, while
+// scalars and sequences in the overlay replace the base value. A null value
+// in the overlay removes the key from the result.
+func ReadMergedDocument(directory string, env string) (*Document, error) {
+	doc, err := ReadDocument(directory)
+	if err != nil {
+		return nil, err
+	}
+	if env == "" {
+		return doc, nil
+	}
+
+	if !envNamePattern.MatchString(env) {
+		return nil, fmt.Errorf("invalid environment name %q: use lowercase letters, digits, '-' and '_'", env)
+	}
+
+	data, err := os.ReadFile(OverlayPath(directory, env))
+	if os.IsNotExist(err) {
+		available, _ := Environments(directory)
+		if len(available) == 0 {
+			return nil, fmt.Errorf("no overlay found for environment %q: expected %s", env, OverlayFileName(env))
+		}
+		return nil, fmt.Errorf("no overlay found for environment %q: expected %s (available: %s)",
+			env, OverlayFileName(env), strings.Join(available, ", "))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", OverlayFileName(env), err)
+	}
+
+	var overlay yaml.Node
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", OverlayFileName(env), err)
+	}
+	if overlay.Kind == 0 {
+		return doc, nil
+	}
+	if len(overlay.Content) == 0 || overlay.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("failed to parse %s: top level must be a mapping", OverlayFileName(env))
+	}
+
+	mergeMappings(doc.mapping(), overlay.Content[0])
+	return doc, nil
+}
+
+func mergeMappings(base *yaml.Node, overlay *yaml.Node) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key := overlay.Content[i]
+		value := overlay.Content[i+1]
+
+		index := -1
+		for j := 0; j+1 < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				index = j
+				break
+			}
+		}
+
+		switch {
+		case value.Tag == "!!null":
+			if index >= 0 {
+				base.Content = append(base.Content[:index], base.Content[index+2:]...)
+			}
+		case index < 0:
+			base.Content = append(base.Content, key, value)
+		case value.Kind == yaml.MappingNode && base.Content[index+1].Kind == yaml.MappingNode:
+			mergeMappings(base.Content[index+1], value)
+		default:
+			lineComment := base.Content[index+1].LineComment
+			base.Content[index+1] = value
+			if value.LineComment == "" {
+				value.LineComment = lineComment
+			}
+		}
+	}
+}
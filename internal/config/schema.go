@@ -0,0 +1,105 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the squadbase.yml schema version written by this CLI.
+const CurrentVersion = "1"
+
+var (
+	ErrOutdatedVersion    = errors.New("outdated squadbase.yml version")
+	ErrUnsupportedVersion = errors.New("unsupported squadbase.yml version")
+)
+
+// Migration upgrades a squadbase.yml document from one schema version to the next.
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Apply       func(doc *Document) error
+}
+
+// migrations is ordered from the oldest schema to the newest. A file without
+// a version field is treated as the pre-versioning schema "".
+var migrations = []Migration{
+	{
+		From:        "",
+		To:          "1",
+		Description: "Add the schema version field",
+		Apply: func(doc *Document) error {
+			return nil
+		},
+	},
+}
+
+// CheckVersion reports whether a squadbase.yml with the given schema version
+// can be used as is. It returns an error wrapping ErrOutdatedVersion when
+// the file can be upgraded with squad migrate, and ErrUnsupportedVersion when
+// the version is unknown to this CLI.
+func CheckVersion(version string) error {
+	if version == CurrentVersion {
+		return nil
+	}
+	for _, m := range migrations {
+		if m.From == version {
+			if version == "" {
+				return fmt.Errorf("%w: %s has no version field (current: %s)", ErrOutdatedVersion, FileName, CurrentVersion)
+			}
+			return fmt.Errorf("%w: %s uses version %s (current: %s)", ErrOutdatedVersion, FileName, version, CurrentVersion)
+		}
+	}
+	if newer, err := strconv.Atoi(version); err == nil {
+		current, _ := strconv.Atoi(CurrentVersion)
+		if newer > current {
+			return fmt.Errorf("%w: %s uses version %s, but this CLI only understands up to version %s; please upgrade squad",
+				ErrUnsupportedVersion, FileName, version, CurrentVersion)
+		}
+	}
+	return fmt.Errorf("%w: %s uses unknown version %q", ErrUnsupportedVersion, FileName, version)
+}
+
+// Migrate upgrades the document in place to CurrentVersion and returns the
+// migrations that were applied, in order.
+func Migrate(doc *Document) ([]Migration, error) {
+	version := doc.GetString("version")
+	if err := CheckVersion(version); err != nil && !errors.Is(err, ErrOutdatedVersion) {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, m := range migrations {
+		if m.From != version {
+			continue
+		}
+		if err := m.Apply(doc); err != nil {
+			return applied, fmt.Errorf("failed to migrate %s from version %q to %q: %w", FileName, m.From, m.To, err)
+		}
+		setVersion(doc, m.To)
+		applied = append(applied, m)
+		version = m.To
+	}
+
+	return applied, nil
+}
+
+// setVersion writes the version field, adding it as the first key of the
+// file when it is missing.
+func setVersion(doc *Document, version string) {
+	if node := doc.Lookup("version"); node != nil {
+		node.Value = version
+		node.Tag = ""
+		node.Style = yaml.SingleQuotedStyle
+		return
+	}
+
+	mapping := doc.mapping()
+	mapping.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+		{Kind: yaml.ScalarNode, Value: version, Style: yaml.SingleQuotedStyle},
+	}, mapping.Content...)
+}
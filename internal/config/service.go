@@ -0,0 +1,22 @@
+package config
+
+// Service is a backing service, such as a database, that the app uses.
+// Locally it runs from the compose.yaml generated by squad service.
+type Service struct {
+	Name string `yaml:"name"`
+	// Type selects the service definition, for example postgres or redis.
+	Type    string `yaml:"type"`
+	Version string `yaml:"version,omitempty"`
+	// Port is the local port the service is published on.
+	Port int `yaml:"port,omitempty"`
+}
+
+// Service returns the service with the given name, or nil.
+func (c *Config) Service(name string) *Service {
+	for i := range c.Services {
+		if c.Services[i].Name == name {
+			return &c.Services[i]
+		}
+	}
+	return nil
+}
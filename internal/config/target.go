@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTargetName is the name of the deployment described directly in the
+// deployment section, and of that deployment once it is moved to
+// deployment.targets.
+const DefaultTargetName = "default"
+
+var targetNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Target is a named deployment: a provider together with its settings.
+type Target struct {
+	Name string
+	Deployment
+
+	inline bool
+}
+
+// Prefix returns the key path of the target's block in squadbase.yml, as
+// used in validation messages.
+func (t *Target) Prefix() string {
+	if t.inline {
+		return "deployment"
+	}
+	return "deployment.targets." + t.Name
+}
+
+// Targets are the entries of deployment.targets, in file order.
+type Targets []Target
+
+func (t *Targets) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: deployment.targets must be a mapping of target names to deployments", node.Line)
+	}
+	targets := Targets{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		target := Target{Name: node.Content[i].Value}
+		if err := node.Content[i+1].Decode(&target.Deployment); err != nil {
+			return err
+		}
+		if len(target.Targets) > 0 {
+			return fmt.Errorf("line %d: deployment.targets.%s cannot contain targets", node.Content[i].Line, target.Name)
+		}
+		targets = append(targets, target)
+	}
+	*t = targets
+	return nil
+}
+
+// ValidTargetName reports whether name can be used as a deployment target name.
+func ValidTargetName(name string) bool {
+	return targetNamePattern.MatchString(name)
+}
+
+// Targets returns every deployment target in the file. A file without
+// deployment.targets has a single target named DefaultTargetName.
+func (c *Config) Targets() []Target {
+	if len(c.Deployment.Targets) > 0 {
+		return c.Deployment.Targets
+	}
+	inline := c.Deployment
+	inline.Targets = nil
+	inline.DefaultTarget = ""
+	return []Target{{Name: DefaultTargetName, Deployment: inline, inline: true}}
+}
+
+// TargetNames returns the names of every deployment target in the file.
+func (c *Config) TargetNames() []string {
+	names := []string{}
+	for _, t := range c.Targets() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Target returns the deployment target with the given name. An empty name
+// selects deployment.default_target, or the first target when it is not set.
+func (c *Config) Target(name string) (*Target, error) {
+	targets := c.Targets()
+	if name == "" {
+		name = c.Deployment.DefaultTarget
+	}
+	if name == "" {
+		return &targets[0], nil
+	}
+	for i := range targets {
+		if targets[i].Name == name {
+			return &targets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown deployment target %q (available: %s)", name, strings.Join(c.TargetNames(), ", "))
+}
+
+// TargetNames returns the names listed in deployment.targets, in file order.
+// It returns nil when the file describes a single deployment.
+func (d *Document) TargetNames() []string {
+	targets := d.Lookup("deployment", "targets")
+	if targets == nil || targets.Kind != yaml.MappingNode {
+		return nil
+	}
+	names := []string{}
+	for i := 0; i+1 < len(targets.Content); i += 2 {
+		names = append(names, targets.Content[i].Value)
+	}
+	return names
+}
+
+// TargetPath returns the key path of the named target's block: the
+// deployment section itself when the file has no deployment.targets.
+func (d *Document) TargetPath(name string) []string {
+	if d.TargetNames() == nil {
+		return []string{"deployment"}
+	}
+	return []string{"deployment", "targets", name}
+}
+
+// TargetPaths returns the key path of every target block in the document.
+func (d *Document) TargetPaths() [][]string {
+	names := d.TargetNames()
+	if names == nil {
+		return [][]string{{"deployment"}}
+	}
+	paths := [][]string{}
+	for _, name := range names {
+		paths = append(paths, []string{"deployment", "targets", name})
+	}
+	return paths
+}
+
+// MoveToTargets turns a single deployment into the first entry of
+// deployment.targets under the given name, keeping its settings and
+// comments. It does nothing when the file already lists targets.
+func (d *Document) MoveToTargets(name string) {
+	if d.TargetNames() != nil {
+		return
+	}
+	deployment := d.ensure("deployment")
+	if deployment.Kind != yaml.MappingNode {
+		*deployment = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	target := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	kept := []*yaml.Node{}
+	for i := 0; i+1 < len(deployment.Content); i += 2 {
+		key, value := deployment.Content[i], deployment.Content[i+1]
+		if key.Value == "default_target" {
+			kept = append(kept, key, value)
+			continue
+		}
+		target.Content = append(target.Content, key, value)
+	}
+	target.FootComment = deployment.FootComment
+	deployment.FootComment = ""
+
+	deployment.Content = append(kept,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "targets"},
+		&yaml.Node{
+			Kind: yaml.MappingNode,
+			Tag:  "!!map",
+			Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+				target,
+			},
+		},
+	)
+}
@@ -0,0 +1,34 @@
+package config
+
+// Revisions that traffic.split can refer to without knowing their names.
+const (
+	// RevisionLatest is the revision created by the deployment.
+	RevisionLatest = "latest"
+	// RevisionPrevious is the revision that served traffic before the deployment.
+	RevisionPrevious = "previous"
+)
+
+// Traffic describes how requests are routed between revisions during a
+// rollout.
+type Traffic struct {
+	Split []TrafficSplit `yaml:"split,omitempty"`
+	// Tag exposes the latest revision at its own preview URL.
+	Tag string `yaml:"tag,omitempty"`
+	// RollbackThreshold is the error rate of the latest revision, in
+	// percent, above which the rollout is rolled back.
+	RollbackThreshold float64 `yaml:"rollback_threshold,omitempty"`
+}
+
+type TrafficSplit struct {
+	Revision string `yaml:"revision"`
+	Percent  int    `yaml:"percent"`
+}
+
+// Splits returns the traffic split to apply. Without a split, every request
+// goes to the latest revision.
+func (t *Traffic) Splits() []TrafficSplit {
+	if t == nil || len(t.Split) == 0 {
+		return []TrafficSplit{{Revision: RevisionLatest, Percent: 100}}
+	}
+	return t.Split
+}
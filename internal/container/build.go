@@ -0,0 +1,147 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/export"
+)
+
+// DefaultPlatform is the platform images are built for on Squadbase.
+const DefaultPlatform = "linux/amd64"
+
+// BuildOptions describe an image build.
+type BuildOptions struct {
+	Dockerfile string
+	Context    string
+	Tags       []string
+	Platform   string
+	BuildArgs  []string
+	NoCache    bool
+	// Generated is set when Dockerfile was generated from the build
+	// settings rather than written by the user.
+	Generated bool
+}
+
+// NewBuildOptions resolves the Dockerfile, context and build args of the
+// build settings of the project in directory. Without use_custom_dockerfile,
+// a Dockerfile is generated like squad dockerfile does and written to
+// scratch, along with a .dockerignore when the context has none.
+func NewBuildOptions(directory string, build config.Build, scratch string) (*BuildOptions, error) {
+	opts := &BuildOptions{
+		Context:   filepath.Join(directory, build.Context),
+		Platform:  DefaultPlatform,
+		BuildArgs: build.BuildArgs,
+	}
+	if info, err := os.Stat(opts.Context); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("build context %s is not a directory", opts.Context)
+	}
+
+	if build.UseCustomDockerfile {
+		opts.Dockerfile = filepath.Join(opts.Context, export.DockerfileName)
+		if _, err := os.Stat(opts.Dockerfile); err != nil {
+			return nil, fmt.Errorf("build.use_custom_dockerfile is set but %s does not exist: run 'squad dockerfile' to generate one", opts.Dockerfile)
+		}
+		return opts, nil
+	}
+
+	files, err := export.Dockerfile(build)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := file.Name
+		if name == export.DockerignoreName {
+			if _, err := os.Stat(filepath.Join(opts.Context, name)); err == nil {
+				continue
+			}
+			// BuildKit reads <Dockerfile>.dockerignore next to the Dockerfile.
+			name = export.DockerfileName + export.DockerignoreName
+		}
+		if err := os.WriteFile(filepath.Join(scratch, name), file.Content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write generated %s: %w", file.Name, err)
+		}
+	}
+	opts.Dockerfile = filepath.Join(scratch, export.DockerfileName)
+	opts.Generated = true
+	return opts, nil
+}
+
+// Args returns the arguments of the engine's build command.
+func (o *BuildOptions) Args() []string {
+	args := []string{"build", "--file", o.Dockerfile}
+	if o.Platform != "" {
+		args = append(args, "--platform", o.Platform)
+	}
+	for _, tag := range o.Tags {
+		args = append(args, "--tag", tag)
+	}
+	for _, arg := range o.BuildArgs {
+		args = append(args, "--build-arg", arg)
+	}
+	if o.NoCache {
+		args = append(args, "--no-cache")
+	}
+	return append(args, o.Context)
+}
+
+// BuildError is returned when the engine fails to build the image. Summary
+// holds the lines of the output that explain the failure.
+type BuildError struct {
+	Engine   string
+	ExitCode int
+	Summary  []string
+	Err      error
+}
+
+func (e *BuildError) Error() string {
+	return fmt.Sprintf("%s build failed: %v", e.Engine, e.Err)
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// Build runs the build with engine, streaming its output to stdout and stderr.
+func Build(ctx context.Context, engine Engine, opts *BuildOptions, stdout io.Writer, stderr io.Writer) error {
+	recorder := newOutputRecorder(200)
+	err := engine.Run(ctx, opts.Args(), recorder.tee(stdout), recorder.tee(stderr))
+	if err == nil {
+		return nil
+	}
+	return &BuildError{
+		Engine:   engine.Name(),
+		ExitCode: ExitCode(err),
+		Summary:  summarizeFailure(recorder.Lines(), 10),
+		Err:      err,
+	}
+}
+
+var errorLinePattern = regexp.MustCompile(`(?i)(^|\s|#\d+ )(error|failed|fatal)\b|ERR!`)
+
+// summarizeFailure picks the lines that report errors from the end of the
+// output, or the last lines when none do.
+func summarizeFailure(lines []string, max int) []string {
+	summary := []string{}
+	seen := map[string]bool{}
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if errorLinePattern.MatchString(trimmed) && !seen[trimmed] {
+			seen[trimmed] = true
+			summary = append(summary, trimmed)
+		}
+	}
+	if len(summary) == 0 {
+		summary = lines
+	}
+	if len(summary) > max {
+		summary = summary[len(summary)-max:]
+	}
+	return summary
+}
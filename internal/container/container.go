@@ -0,0 +1,135 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Engines lists the supported container engine CLIs in order of preference.
+var Engines = []string{"docker", "podman"}
+
+// Engine runs commands of a container engine CLI such as docker or podman.
+type Engine interface {
+	Name() string
+	Run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error
+}
+
+type cliEngine struct {
+	name string
+	path string
+}
+
+func (e *cliEngine) Name() string {
+	return e.name
+}
+
+func (e *cliEngine) Run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, e.path, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// Detect returns the engine with the given name, or the first supported
+// engine found on PATH when name is empty.
+func Detect(name string) (Engine, error) {
+	if name != "" {
+		supported := false
+		for _, engine := range Engines {
+			supported = supported || engine == name
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported container engine %q: use %s", name, strings.Join(Engines, " or "))
+		}
+		path, err := exec.LookPath(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not installed or not on PATH", name)
+		}
+		return &cliEngine{name: name, path: path}, nil
+	}
+
+	for _, engine := range Engines {
+		if path, err := exec.LookPath(engine); err == nil {
+			return &cliEngine{name: engine, path: path}, nil
+		}
+	}
+	return nil, fmt.Errorf("no container engine found: install %s", strings.Join(Engines, " or "))
+}
+
+// ExitCode returns the exit code of a failed engine command, or -1 when the
+// command did not exit on its own.
+func ExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// outputRecorder passes engine output through while keeping the last lines,
+// so that a failure can be summarized after the output has scrolled by.
+type outputRecorder struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial bytes.Buffer
+}
+
+func newOutputRecorder(max int) *outputRecorder {
+	return &outputRecorder{max: max}
+}
+
+// tee returns a writer that writes to w and records what was written.
+func (r *outputRecorder) tee(w io.Writer) io.Writer {
+	return writerFunc(func(p []byte) (int, error) {
+		r.record(p)
+		return w.Write(p)
+	})
+}
+
+func (r *outputRecorder) record(p []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.partial.Write(p)
+	for {
+		line, err := r.partial.ReadString('\n')
+		if err != nil {
+			r.partial.WriteString(line)
+			return
+		}
+		r.add(strings.TrimRight(line, "\r\n"))
+	}
+}
+
+func (r *outputRecorder) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	r.lines = append(r.lines, line)
+	if len(r.lines) > r.max {
+		r.lines = r.lines[len(r.lines)-r.max:]
+	}
+}
+
+// Lines returns the recorded lines, including an unterminated last line.
+func (r *outputRecorder) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.partial.Len() > 0 {
+		r.add(r.partial.String())
+		r.partial.Reset()
+	}
+	return append([]string{}, r.lines...)
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
@@ -0,0 +1,52 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"time"
+)
+
+// TimeoutProxy forwards requests to the app and cuts off the ones that run
+// longer than Timeout with a 504, like the platform's request timeout does.
+// Upgraded connections such as WebSockets are cut off the same way.
+type TimeoutProxy struct {
+	Target  *url.URL
+	Timeout time.Duration
+	// OnTimeout is called for every request that was cut off.
+	OnTimeout func(r *http.Request)
+
+	proxy *httputil.ReverseProxy
+}
+
+func NewTimeoutProxy(target *url.URL, timeout time.Duration, onTimeout func(r *http.Request)) *TimeoutProxy {
+	p := &TimeoutProxy{Target: target, Timeout: timeout, OnTimeout: onTimeout}
+	p.proxy = httputil.NewSingleHostReverseProxy(target)
+	p.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	return p
+}
+
+func (p *TimeoutProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.Timeout <= 0 {
+		p.proxy.ServeHTTP(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), p.Timeout)
+	defer cancel()
+	p.proxy.ServeHTTP(w, r.WithContext(ctx))
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && p.OnTimeout != nil {
+		p.OnTimeout(r)
+	}
+}
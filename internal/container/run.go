@@ -0,0 +1,206 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RunOptions describe a container started with resource limits like the
+// ones the platform applies.
+type RunOptions struct {
+	Image string
+	Name  string
+	// Port is the port the app listens on inside the container. It is
+	// published on HostPort of the loopback interface.
+	Port     int
+	HostPort int
+	MemoryMB int
+	CPU      float64
+	Env      map[string]string
+}
+
+// Args returns the arguments of the engine's run command, which reads the
+// environment from envFile. The container is started in the background so
+// that its state can be inspected after it exits.
+func (o *RunOptions) Args(envFile string) []string {
+	args := []string{"run", "--detach"}
+	if o.Name != "" {
+		args = append(args, "--name", o.Name)
+	}
+	if o.MemoryMB > 0 {
+		memory := fmt.Sprintf("%dm", o.MemoryMB)
+		args = append(args, "--memory", memory, "--memory-swap", memory)
+	}
+	if o.CPU > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(o.CPU, 'f', -1, 64))
+	}
+	args = append(args, "--publish", fmt.Sprintf("127.0.0.1:%d:%d", o.HostPort, o.Port))
+	args = append(args, "--env-file", envFile)
+	return append(args, o.Image)
+}
+
+// EnvFile returns the --env-file content with the environment of the
+// container. Values stay off the command line, where ps and shell tracing
+// would show them.
+func (o *RunOptions) EnvFile() (string, error) {
+	names := make([]string, 0, len(o.Env))
+	for name := range o.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var content strings.Builder
+	for _, name := range names {
+		if strings.ContainsAny(o.Env[name], "\r\n") {
+			return "", fmt.Errorf("the value of %s spans several lines, which an env file cannot hold", name)
+		}
+		fmt.Fprintf(&content, "%s=%s\n", name, o.Env[name])
+	}
+	if _, ok := o.Env["PORT"]; !ok {
+		fmt.Fprintf(&content, "PORT=%d\n", o.Port)
+	}
+	return content.String(), nil
+}
+
+// State is the state of a container after it exited.
+type State struct {
+	ExitCode  int
+	OOMKilled bool
+}
+
+// Start starts the container and returns its ID.
+func Start(ctx context.Context, engine Engine, opts *RunOptions) (string, error) {
+	env, err := opts.EnvFile()
+	if err != nil {
+		return "", fmt.Errorf("failed to start container: %w", err)
+	}
+	// The engine reads the file when it creates the container, so it is
+	// only needed until run returns.
+	envFile, err := os.CreateTemp("", "squad-run-*.env")
+	if err != nil {
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+	defer os.Remove(envFile.Name())
+	_, err = envFile.WriteString(env)
+	if closeErr := envFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := engine.Run(ctx, opts.Args(envFile.Name()), &stdout, &stderr); err != nil {
+		return "", fmt.Errorf("failed to start container: %s", commandError(err, &stderr))
+	}
+	id := strings.TrimSpace(stdout.String())
+	if id == "" {
+		return "", fmt.Errorf("failed to start container: %s printed no container ID", engine.Name())
+	}
+	return id, nil
+}
+
+// Logs streams the output of the container until it exits.
+func Logs(ctx context.Context, engine Engine, id string, stdout io.Writer, stderr io.Writer) error {
+	return engine.Run(ctx, []string{"logs", "--follow", id}, stdout, stderr)
+}
+
+// Wait blocks until the container exits and returns its state.
+func Wait(ctx context.Context, engine Engine, id string) (*State, error) {
+	var stderr bytes.Buffer
+	if err := engine.Run(ctx, []string{"wait", id}, io.Discard, &stderr); err != nil {
+		return nil, fmt.Errorf("failed to wait for container: %s", commandError(err, &stderr))
+	}
+	return Inspect(ctx, engine, id)
+}
+
+// Inspect returns the state of the container.
+func Inspect(ctx context.Context, engine Engine, id string) (*State, error) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"inspect", "--format", "{{.State.ExitCode}} {{.State.OOMKilled}}", id}
+	if err := engine.Run(ctx, args, &stdout, &stderr); err != nil {
+		return nil, fmt.Errorf("failed to inspect container: %s", commandError(err, &stderr))
+	}
+
+	fields := strings.Fields(stdout.String())
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("failed to inspect container: unexpected output %q", stdout.String())
+	}
+	exitCode, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container: unexpected exit code %q", fields[0])
+	}
+	return &State{ExitCode: exitCode, OOMKilled: fields[1] == "true"}, nil
+}
+
+// Stop stops the container, killing it after the grace period.
+func Stop(ctx context.Context, engine Engine, id string, grace time.Duration) error {
+	var stderr bytes.Buffer
+	args := []string{"stop", "--time", strconv.Itoa(int(grace.Seconds())), id}
+	if err := engine.Run(ctx, args, io.Discard, &stderr); err != nil {
+		return fmt.Errorf("failed to stop container: %s", commandError(err, &stderr))
+	}
+	return nil
+}
+
+// Remove deletes the container.
+func Remove(ctx context.Context, engine Engine, id string) error {
+	var stderr bytes.Buffer
+	if err := engine.Run(ctx, []string{"rm", "--force", id}, io.Discard, &stderr); err != nil {
+		return fmt.Errorf("failed to remove container: %s", commandError(err, &stderr))
+	}
+	return nil
+}
+
+// WaitHealthy polls url until it answers with a status below 500. It gives
+// up when timeout passes or when exited is closed.
+func WaitHealthy(ctx context.Context, url string, interval time.Duration, timeout time.Duration, exited <-chan struct{}) error {
+	client := &http.Client{Timeout: interval}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastErr := fmt.Errorf("no response")
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return nil
+			}
+			lastErr = fmt.Errorf("status %s", resp.Status)
+		} else {
+			lastErr = err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-exited:
+			return fmt.Errorf("the container exited before it became healthy")
+		case <-deadline.C:
+			return fmt.Errorf("%s did not become healthy within %s: %v", url, timeout, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+func commandError(err error, stderr *bytes.Buffer) string {
+	if message := strings.TrimSpace(stderr.String()); message != "" {
+		return message
+	}
+	return err.Error()
+}
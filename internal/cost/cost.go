@@ -0,0 +1,189 @@
+package cost
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/provider"
+)
+
+//go:embed data/prices.json
+var pricesJSON []byte
+
+const (
+	BillingPerRequest  = "per_request"
+	BillingPerInstance = "per_instance"
+)
+
+// Prices are the unit prices of one provider.
+type Prices struct {
+	Service            string  `json:"service"`
+	Basis              string  `json:"basis"`
+	Billing            string  `json:"billing"`
+	IdleLabel          string  `json:"idle_label"`
+	RequestPerMillion  float64 `json:"request_per_million"`
+	ActiveVCPUSecond   float64 `json:"active_vcpu_second"`
+	ActiveGiBSecond    float64 `json:"active_gib_second"`
+	IdleVCPUSecond     float64 `json:"idle_vcpu_second"`
+	IdleGiBSecond      float64 `json:"idle_gib_second"`
+	EphemeralGiBSecond float64 `json:"ephemeral_gib_second"`
+	FreeEphemeralMB    int     `json:"free_ephemeral_mb"`
+	MinimumDurationMS  int     `json:"minimum_duration_ms"`
+}
+
+// PriceTable is the versioned price list shipped with the CLI.
+type PriceTable struct {
+	Version       string            `json:"version"`
+	Currency      string            `json:"currency"`
+	HoursPerMonth float64           `json:"hours_per_month"`
+	Providers     map[string]Prices `json:"providers"`
+}
+
+var (
+	priceTable     *PriceTable
+	priceTableErr  error
+	priceTableOnce sync.Once
+)
+
+func LoadPriceTable() (*PriceTable, error) {
+	priceTableOnce.Do(func() {
+		var table PriceTable
+		if err := json.Unmarshal(pricesJSON, &table); err != nil {
+			priceTableErr = fmt.Errorf("failed to parse price table: %w", err)
+			return
+		}
+		priceTable = &table
+	})
+	return priceTable, priceTableErr
+}
+
+// DefaultAvgDuration is the request duration assumed when none is given.
+const DefaultAvgDuration = 300 * time.Millisecond
+
+// Usage describes the expected traffic of the app.
+type Usage struct {
+	RequestsPerMonth int64
+	AvgDuration      time.Duration
+}
+
+type Item struct {
+	Name   string  `json:"name"`
+	Detail string  `json:"detail"`
+	Cost   float64 `json:"cost"`
+}
+
+type Estimate struct {
+	Provider     string             `json:"provider"`
+	Service      string             `json:"service"`
+	PriceVersion string             `json:"price_version"`
+	Currency     string             `json:"currency"`
+	Basis        string             `json:"basis"`
+	Resources    provider.Resources `json:"resources"`
+	Items        []Item             `json:"items"`
+	Total        float64            `json:"total"`
+}
+
+// Calculate estimates the monthly cost of running a deployment with the
+// given resources and usage on the named provider. Requests are assumed to be
+// spread evenly, so that each instance serves up to its concurrency limit.
+func Calculate(providerName string, r provider.Resources, usage Usage) (*Estimate, error) {
+	table, err := LoadPriceTable()
+	if err != nil {
+		return nil, err
+	}
+	prices, ok := table.Providers[providerName]
+	if !ok {
+		return nil, fmt.Errorf("no prices for provider %q in price table %s", providerName, table.Version)
+	}
+	if usage.RequestsPerMonth < 0 || usage.AvgDuration < 0 {
+		return nil, fmt.Errorf("requests and duration must not be negative")
+	}
+
+	duration := billedDuration(usage.AvgDuration, prices.MinimumDurationMS)
+	memoryGiB := float64(r.MemoryMB) / 1024
+	requests := float64(usage.RequestsPerMonth)
+
+	concurrency := 1.0
+	if prices.Billing == BillingPerInstance && r.Concurrency > 1 {
+		concurrency = float64(r.Concurrency)
+	}
+	activeSeconds := requests * duration.Seconds() / concurrency
+
+	estimate := &Estimate{
+		Provider:     providerName,
+		Service:      prices.Service,
+		PriceVersion: table.Version,
+		Currency:     table.Currency,
+		Basis:        prices.Basis,
+		Resources:    r,
+	}
+
+	compute := Item{
+		Name: "Compute",
+		Detail: fmt.Sprintf("%.0f instance-seconds × (%s vCPU, %s GiB)",
+			activeSeconds, formatAmount(r.CPU), formatAmount(memoryGiB)),
+		Cost: activeSeconds * (r.CPU*prices.ActiveVCPUSecond + memoryGiB*prices.ActiveGiBSecond),
+	}
+	if prices.Billing == BillingPerRequest {
+		compute.Detail = fmt.Sprintf("%.0f GB-seconds (%d MB × %s per request)",
+			activeSeconds*memoryGiB, r.MemoryMB, duration)
+	}
+	estimate.Items = append(estimate.Items, compute)
+
+	estimate.Items = append(estimate.Items, Item{
+		Name:   "Requests",
+		Detail: fmt.Sprintf("%s requests", formatCount(usage.RequestsPerMonth)),
+		Cost:   requests / 1e6 * prices.RequestPerMillion,
+	})
+
+	if extra := r.EphemeralStorageMB - prices.FreeEphemeralMB; extra > 0 && prices.EphemeralGiBSecond > 0 {
+		estimate.Items = append(estimate.Items, Item{
+			Name:   "Ephemeral storage",
+			Detail: fmt.Sprintf("%d MB above the included %d MB", extra, prices.FreeEphemeralMB),
+			Cost:   activeSeconds * float64(extra) / 1024 * prices.EphemeralGiBSecond,
+		})
+	}
+
+	monthSeconds := table.HoursPerMonth * 3600
+	idle := Item{
+		Name:   prices.IdleLabel,
+		Detail: fmt.Sprintf("%d always-on instance(s)", r.MinInstances),
+	}
+	if r.MinInstances > 0 {
+		idle.Cost = float64(r.MinInstances) * monthSeconds * (r.CPU*prices.IdleVCPUSecond + memoryGiB*prices.IdleGiBSecond)
+	}
+	estimate.Items = append(estimate.Items, idle)
+
+	for _, item := range estimate.Items {
+		estimate.Total += item.Cost
+	}
+	return estimate, nil
+}
+
+func billedDuration(duration time.Duration, minimumMS int) time.Duration {
+	if minimumMS <= 0 {
+		return duration
+	}
+	step := time.Duration(minimumMS) * time.Millisecond
+	return time.Duration(math.Ceil(float64(duration)/float64(step))) * step
+}
+
+func formatAmount(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
+
+func formatCount(count int64) string {
+	switch {
+	case count >= 1e9:
+		return fmt.Sprintf("%.1fB", float64(count)/1e9)
+	case count >= 1e6:
+		return fmt.Sprintf("%.1fM", float64(count)/1e6)
+	case count >= 1e3:
+		return fmt.Sprintf("%.1fK", float64(count)/1e3)
+	}
+	return fmt.Sprintf("%d", count)
+}
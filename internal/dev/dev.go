@@ -0,0 +1,85 @@
+package dev
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/export"
+)
+
+// Plan is how the dev server of a project is started.
+type Plan struct {
+	// Command starts the framework's dev server.
+	Command []string
+	// Install brings the installed dependencies in line with the manifests.
+	Install [][]string
+	// Manifests are the dependency files whose changes restart the server.
+	Manifests []string
+}
+
+var pythonManifests = []string{"pyproject.toml", "poetry.lock", "uv.lock", "requirements.txt"}
+
+var nodeManifests = []string{"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml"}
+
+// NewPlan returns the dev server plan of the build settings of the project
+// in directory.
+func NewPlan(directory string, build config.Build) (*Plan, error) {
+	switch build.Framework {
+	case "streamlit", "morph":
+		args := []string{"morph", "serve"}
+		if build.Framework == "streamlit" {
+			args = []string{"streamlit", "run", export.StreamlitScript(build.Entrypoint)}
+		}
+		command, install, err := pythonCommand(directory, build.PackageManager, args)
+		if err != nil {
+			return nil, err
+		}
+		plan := &Plan{Command: command, Install: [][]string{install}, Manifests: pythonManifests}
+		if build.Framework == "morph" {
+			plan.Install = append(plan.Install, []string{"npm", "install"})
+			plan.Manifests = append(append([]string{}, pythonManifests...), "package.json", "package-lock.json")
+		}
+		return plan, nil
+	case "nextjs":
+		var command []string
+		switch build.PackageManager {
+		case "npm":
+			command = []string{"npm", "run", "dev"}
+		case "yarn", "pnpm":
+			command = []string{build.PackageManager, "dev"}
+		default:
+			return nil, fmt.Errorf("package manager %q is not supported for nextjs: use npm, yarn or pnpm", build.PackageManager)
+		}
+		return &Plan{
+			Command:   command,
+			Install:   [][]string{{build.PackageManager, "install"}},
+			Manifests: nodeManifests,
+		}, nil
+	}
+	return nil, fmt.Errorf("framework %q is not supported: use streamlit, morph or nextjs", build.Framework)
+}
+
+// pythonCommand runs args in the environment of the package manager. With
+// pip, the tools of the project's .venv are used when it has one.
+func pythonCommand(directory string, packageManager string, args []string) ([]string, []string, error) {
+	switch packageManager {
+	case "poetry":
+		return append([]string{"poetry", "run"}, args...), []string{"poetry", "install", "--no-root"}, nil
+	case "uv":
+		return append([]string{"uv", "run"}, args...), []string{"uv", "sync"}, nil
+	case "pip":
+		command := append([]string{venvTool(directory, args[0])}, args[1:]...)
+		return command, []string{venvTool(directory, "pip"), "install", "-r", "requirements.txt"}, nil
+	}
+	return nil, nil, fmt.Errorf("package manager %q is not supported for %s: use poetry, uv or pip", packageManager, args[0])
+}
+
+func venvTool(directory string, tool string) string {
+	path := filepath.Join(directory, ".venv", "bin", tool)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	return tool
+}
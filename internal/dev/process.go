@@ -0,0 +1,77 @@
+package dev
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Process is a running dev server. It runs in its own process group, so that
+// signals reach the package manager and the server it starts exactly once.
+type Process struct {
+	cmd  *exec.Cmd
+	done chan struct{}
+	err  error
+}
+
+// Start runs args in directory with env as its environment.
+func Start(directory string, args []string, env []string, stdout io.Writer, stderr io.Writer) (*Process, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = directory
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &Process{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		p.err = cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// Done is closed when the process has exited.
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Err returns the result of the process once Done is closed.
+func (p *Process) Err() error {
+	return p.err
+}
+
+// Signal sends sig to the process group.
+func (p *Process) Signal(sig os.Signal) error {
+	return signalProcessGroup(p.cmd.Process, sig)
+}
+
+// Stop sends sig to the process group and kills it when it has not exited
+// after grace.
+func (p *Process) Stop(sig os.Signal, grace time.Duration) {
+	if err := p.Signal(sig); err != nil {
+		killProcessGroup(p.cmd.Process)
+	}
+	select {
+	case <-p.done:
+	case <-time.After(grace):
+		killProcessGroup(p.cmd.Process)
+		<-p.done
+	}
+}
+
+// Environ returns the environment of the current process with the values of
+// dotEnv added. Variables that are already set take precedence over .env.
+func Environ(dotEnv map[string]string) []string {
+	env := os.Environ()
+	for name, value := range dotEnv {
+		if _, ok := os.LookupEnv(name); !ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
@@ -0,0 +1,18 @@
+//go:build windows
+
+package dev
+
+import (
+	"os"
+	"os/exec"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func signalProcessGroup(process *os.Process, sig os.Signal) error {
+	return process.Signal(sig)
+}
+
+func killProcessGroup(process *os.Process) {
+	_ = process.Kill()
+}
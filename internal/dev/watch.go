@@ -0,0 +1,45 @@
+package dev
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileState is what a Snapshot records about a file. A missing file has
+// the zero state.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Snapshot is the state of the watched files at one point in time.
+type Snapshot map[string]fileState
+
+// TakeSnapshot records the modification time and size of the named files
+// in directory.
+func TakeSnapshot(directory string, names []string) Snapshot {
+	snapshot := Snapshot{}
+	for _, name := range names {
+		info, err := os.Stat(filepath.Join(directory, name))
+		if err != nil {
+			snapshot[name] = fileState{}
+			continue
+		}
+		snapshot[name] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+	return snapshot
+}
+
+// Changed returns the files that were created, modified or removed since
+// the snapshot was taken, in the order they were named.
+func (s Snapshot) Changed(directory string, names []string) []string {
+	current := TakeSnapshot(directory, names)
+	changed := []string{}
+	for _, name := range names {
+		if current[name] != s[name] {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,150 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type OpKind int
+
+const (
+	Equal OpKind = iota
+	Delete
+	Insert
+)
+
+type Op struct {
+	Kind OpKind
+	Line string
+}
+
+// Lines computes a line-based edit script that turns a into b using the
+// longest common subsequence of their lines.
+func Lines(a, b string) []Op {
+	aLines := splitLines(a)
+	bLines := splitLines(b)
+
+	n, m := len(aLines), len(bLines)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if aLines[i] == bLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]Op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case aLines[i] == bLines[j]:
+			ops = append(ops, Op{Kind: Equal, Line: aLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{Kind: Delete, Line: aLines[i]})
+			i++
+		default:
+			ops = append(ops, Op{Kind: Insert, Line: bLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Op{Kind: Delete, Line: aLines[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Op{Kind: Insert, Line: bLines[j]})
+	}
+
+	return ops
+}
+
+// HasChanges reports whether the edit script contains any insertions or deletions.
+func HasChanges(ops []Op) bool {
+	for _, op := range ops {
+		if op.Kind != Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// Unified renders the difference between a and b in unified diff format with
+// three lines of context. An empty string is returned when a and b are equal.
+func Unified(a, b, fromName, toName string) string {
+	ops := Lines(a, b)
+	if !HasChanges(ops) {
+		return ""
+	}
+
+	const context = 3
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	type hunk struct{ start, end int }
+	var hunks []hunk
+	for idx, op := range ops {
+		if op.Kind == Equal {
+			continue
+		}
+		start := max(idx-context, 0)
+		end := min(idx+context+1, len(ops))
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1].end {
+			hunks[len(hunks)-1].end = end
+		} else {
+			hunks = append(hunks, hunk{start: start, end: end})
+		}
+	}
+
+	for _, h := range hunks {
+		aStart, bStart := 1, 1
+		for _, op := range ops[:h.start] {
+			if op.Kind != Insert {
+				aStart++
+			}
+			if op.Kind != Delete {
+				bStart++
+			}
+		}
+
+		aCount, bCount := 0, 0
+		for _, op := range ops[h.start:h.end] {
+			if op.Kind != Insert {
+				aCount++
+			}
+			if op.Kind != Delete {
+				bCount++
+			}
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", aStart, aCount, bStart, bCount)
+		for _, op := range ops[h.start:h.end] {
+			switch op.Kind {
+			case Equal:
+				out.WriteString(" " + op.Line + "\n")
+			case Delete:
+				out.WriteString("-" + op.Line + "\n")
+			case Insert:
+				out.WriteString("+" + op.Line + "\n")
+			}
+		}
+	}
+
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,202 @@
+package dotenv
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const FileName = ".env"
+
+// Path returns the location of the .env file inside the given project directory.
+func Path(directory string) string {
+	return filepath.Join(directory, FileName)
+}
+
+// Read parses the .env file at path. A missing file yields an empty map.
+func Read(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	values, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return values, nil
+}
+
+// Parse reads KEY=VALUE lines. Blank lines and lines starting with # are
+// ignored, an optional "export " prefix is allowed, and values may be wrapped
+// in single or double quotes. Double-quoted values support \n, \t, \" and \\.
+func Parse(content string) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		key, value, ok, err := parseLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		if ok {
+			values[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func parseLine(line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	key, value, found := strings.Cut(line, "=")
+	if !found {
+		return "", "", false, fmt.Errorf("expected KEY=VALUE, got %q", line)
+	}
+	key = strings.TrimSpace(key)
+	if !IsValidKey(key) {
+		return "", "", false, fmt.Errorf("invalid variable name %q", key)
+	}
+
+	value = strings.TrimSpace(value)
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		value = unescape(value[1 : len(value)-1])
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		value = value[1 : len(value)-1]
+	default:
+		if index := strings.Index(value, " #"); index >= 0 {
+			value = strings.TrimSpace(value[:index])
+		}
+	}
+
+	return key, value, true, nil
+}
+
+// IsValidKey reports whether key can be used as a variable name: letters,
+// digits and underscores, not starting with a digit.
+func IsValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func unescape(value string) string {
+	replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`)
+	return replacer.Replace(value)
+}
+
+// Quote formats value for the right-hand side of a .env line, in double
+// quotes when Parse would not read it back unchanged otherwise.
+func Quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\"'#\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// Set writes key=value to the .env file at path, replacing the first line
+// that sets key, dropping any later ones, or appending one. Comments and the order of the other lines are
+// kept. A new file is created readable only by the current user, since .env
+// files hold secrets.
+func Set(path string, key string, value string) error {
+	if !IsValidKey(key) {
+		return fmt.Errorf("invalid variable name %q", key)
+	}
+	lines, mode, err := readLines(path)
+	if err != nil {
+		return err
+	}
+
+	line := key + "=" + Quote(value)
+	kept := lines[:0]
+	replaced := false
+	for _, l := range lines {
+		if k, _, ok, _ := parseLine(l); ok && k == key {
+			if !replaced {
+				kept = append(kept, line)
+				replaced = true
+			}
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if !replaced {
+		kept = append(kept, line)
+	}
+	return writeLines(path, kept, mode)
+}
+
+// Unset removes the lines that set key from the .env file at path and
+// reports whether there were any.
+func Unset(path string, key string) (bool, error) {
+	lines, mode, err := readLines(path)
+	if err != nil {
+		return false, err
+	}
+	kept := lines[:0]
+	removed := false
+	for _, l := range lines {
+		if k, _, ok, _ := parseLine(l); ok && k == key {
+			removed = true
+			continue
+		}
+		kept = append(kept, l)
+	}
+	if !removed {
+		return false, nil
+	}
+	return true, writeLines(path, kept, mode)
+}
+
+func readLines(path string) ([]string, os.FileMode, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, 0600, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return nil, info.Mode().Perm(), nil
+	}
+	return strings.Split(content, "\n"), info.Mode().Perm(), nil
+}
+
+func writeLines(path string, lines []string, mode os.FileMode) error {
+	content := ""
+	for _, l := range lines {
+		content += l + "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
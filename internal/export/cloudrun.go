@@ -0,0 +1,141 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/provider"
+)
+
+type knativeService struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   objectMeta         `yaml:"metadata"`
+	Spec       knativeServiceSpec `yaml:"spec"`
+}
+
+type knativeServiceSpec struct {
+	Template knativeRevisionTemplate `yaml:"template"`
+	Traffic  []knativeTrafficTarget  `yaml:"traffic"`
+}
+
+type knativeRevisionTemplate struct {
+	Metadata objectMeta          `yaml:"metadata"`
+	Spec     knativeRevisionSpec `yaml:"spec"`
+}
+
+type knativeRevisionSpec struct {
+	ContainerConcurrency int         `yaml:"containerConcurrency"`
+	TimeoutSeconds       int         `yaml:"timeoutSeconds"`
+	Containers           []container `yaml:"containers"`
+	Volumes              []volume    `yaml:"volumes,omitempty"`
+}
+
+type knativeTrafficTarget struct {
+	LatestRevision bool   `yaml:"latestRevision,omitempty"`
+	RevisionName   string `yaml:"revisionName,omitempty"`
+	Percent        int    `yaml:"percent"`
+	Tag            string `yaml:"tag,omitempty"`
+}
+
+// scratchVolume is the in-memory volume that backs an explicit
+// ephemeral_storage on Cloud Run, mounted at /tmp.
+const scratchVolume = "scratch"
+
+// CloudRunService generates a Knative service.yaml that
+// `gcloud run services replace` accepts.
+func CloudRunService(app *App) ([]File, error) {
+	if app.Target.Provider != "gcp" {
+		return nil, fmt.Errorf("cloudrun export needs a gcp deployment target, %q uses %s", app.Target.Name, app.Target.Provider)
+	}
+	if usesRevision(app.Traffic, config.RevisionPrevious) && app.PreviousRevision == "" {
+		return nil, fmt.Errorf("traffic.split routes to the previous revision: pass its name with --previous-revision")
+	}
+	r := app.Resources
+
+	image := app.Image
+	if image == "" {
+		image = fmt.Sprintf("%s-docker.pkg.dev/PROJECT_ID/squadbase/%s:latest", r.Region, app.Name)
+	}
+
+	templateAnnotations := map[string]string{
+		"autoscaling.knative.dev/minScale": strconv.Itoa(r.MinInstances),
+	}
+	if r.MaxInstances > 0 {
+		templateAnnotations["autoscaling.knative.dev/maxScale"] = strconv.Itoa(r.MaxInstances)
+	}
+
+	if app.Build.Runtime != "" {
+		templateAnnotations["squadbase.com/runtime"] = app.Build.Runtime
+	}
+
+	// Label keys may only contain lowercase letters, digits, '_' and '-'.
+	labels := map[string]string{"cloud.googleapis.com/location": r.Region}
+	if app.Build.Framework != "" {
+		labels["squadbase-framework"] = app.Build.Framework
+	}
+
+	appContainer := container{
+		Image: image,
+		Ports: []containerPort{{Name: "http1", ContainerPort: ContainerPort(app.Build.Framework)}},
+		Resources: resourceRequirements{Limits: map[string]string{
+			"cpu":    cpuQuantity(r.CPU),
+			"memory": memoryQuantity(r.MemoryMB),
+		}},
+	}
+	spec := knativeRevisionSpec{
+		ContainerConcurrency: r.Concurrency,
+		TimeoutSeconds:       r.TimeoutSeconds,
+	}
+	// The in-memory /tmp counts against the memory limit, so it is only
+	// added when ephemeral_storage is set rather than for the default.
+	if gcp := app.Target.Deployment.GCP; gcp != nil && gcp.EphemeralStorage != "" && r.EphemeralStorageMB > 0 {
+		appContainer.VolumeMounts = []volumeMount{{Name: scratchVolume, MountPath: "/tmp"}}
+		spec.Volumes = []volume{{
+			Name:     scratchVolume,
+			EmptyDir: &emptyDirSpec{Medium: "Memory", SizeLimit: memoryQuantity(r.EphemeralStorageMB)},
+		}}
+	}
+	spec.Containers = []container{appContainer}
+
+	service := knativeService{
+		APIVersion: "serving.knative.dev/v1",
+		Kind:       "Service",
+		Metadata: objectMeta{
+			Name:        app.Name,
+			Labels:      labels,
+			Annotations: map[string]string{"run.googleapis.com/ingress": "all"},
+		},
+		Spec: knativeServiceSpec{
+			Template: knativeRevisionTemplate{
+				Metadata: objectMeta{Annotations: templateAnnotations},
+				Spec:     spec,
+			},
+		},
+	}
+
+	for _, target := range provider.CloudRunTrafficTargets(app.Traffic) {
+		traffic := knativeTrafficTarget{
+			LatestRevision: target.LatestRevision,
+			RevisionName:   target.RevisionName,
+			Percent:        target.Percent,
+			Tag:            target.Tag,
+		}
+		if traffic.RevisionName == config.RevisionPrevious {
+			traffic.RevisionName = app.PreviousRevision
+		}
+		service.Spec.Traffic = append(service.Spec.Traffic, traffic)
+	}
+
+	header := fmt.Sprintf("Generated by squad export cloudrun from %s (target %q).\nApply with: gcloud run services replace service.yaml --region %s",
+		config.FileName, app.Target.Name, r.Region)
+	if app.Image == "" {
+		header += "\nReplace PROJECT_ID in the image, or pass --image."
+	}
+	content, err := marshalManifests(header, service)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Name: "service.yaml", Content: content}}, nil
+}
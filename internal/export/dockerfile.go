@@ -0,0 +1,367 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+)
+
+const (
+	DockerfileName   = "Dockerfile"
+	DockerignoreName = ".dockerignore"
+)
+
+// pythonInstall holds the steps that install the dependencies of a Python
+// package manager into /app/.venv.
+type pythonInstall struct {
+	setup    []string
+	files    string
+	commands []string
+}
+
+var pythonInstalls = map[string]pythonInstall{
+	"poetry": {
+		setup: []string{
+			"RUN pip install --no-cache-dir poetry",
+			"ENV POETRY_VIRTUALENVS_IN_PROJECT=true \\",
+			"    POETRY_NO_INTERACTION=1",
+		},
+		files: "pyproject.toml poetry.lock*",
+		commands: []string{
+			"RUN --mount=type=cache,target=/root/.cache/pypoetry \\",
+			"    poetry install --only main --no-root",
+		},
+	},
+	"uv": {
+		setup: []string{
+			"COPY --from=ghcr.io/astral-sh/uv:latest /uv /bin/uv",
+			"ENV UV_COMPILE_BYTECODE=1 \\",
+			"    UV_LINK_MODE=copy",
+		},
+		files: "pyproject.toml uv.lock*",
+		commands: []string{
+			"RUN --mount=type=cache,target=/root/.cache/uv \\",
+			"    uv sync --no-dev --no-install-project",
+		},
+	},
+	"pip": {
+		files: "requirements.txt",
+		commands: []string{
+			"RUN python -m venv /app/.venv",
+			"RUN --mount=type=cache,target=/root/.cache/pip \\",
+			"    /app/.venv/bin/pip install -r requirements.txt",
+		},
+	},
+}
+
+// nodeInstall holds the steps of a Node.js package manager.
+type nodeInstall struct {
+	setup   []string
+	files   string
+	cache   string
+	install string
+	prune   string
+	run     string
+}
+
+var nodeInstalls = map[string]nodeInstall{
+	"npm": {
+		files:   "package.json package-lock.json*",
+		cache:   "/root/.npm",
+		install: "npm ci",
+		prune:   "npm prune --omit=dev",
+		run:     "npm run",
+	},
+	"yarn": {
+		files:   "package.json yarn.lock*",
+		cache:   "/usr/local/share/.cache/yarn",
+		install: "yarn install --frozen-lockfile",
+		prune:   "yarn install --frozen-lockfile --production --ignore-scripts --prefer-offline",
+		run:     "yarn",
+	},
+	"pnpm": {
+		setup:   []string{"RUN corepack enable pnpm"},
+		files:   "package.json pnpm-lock.yaml*",
+		cache:   "/root/.local/share/pnpm/store",
+		install: "pnpm install --frozen-lockfile",
+		prune:   "pnpm prune --prod",
+		run:     "pnpm run",
+	},
+}
+
+var dockerignore = []string{
+	"# Generated by squad dockerfile",
+	".git",
+	".gitignore",
+	".env",
+	".env.*",
+	"!.env.example",
+	"Dockerfile",
+	".dockerignore",
+	"squadbase.*.yml",
+	"*.log",
+	".DS_Store",
+}
+
+var dockerignoreByLanguage = map[string][]string{
+	"python": {
+		".venv",
+		"venv",
+		"__pycache__",
+		"*.py[cod]",
+		".pytest_cache",
+		".mypy_cache",
+		".ruff_cache",
+	},
+	"nodejs": {
+		"node_modules",
+		".next",
+		"out",
+		"coverage",
+		"npm-debug.log*",
+		"yarn-error.log*",
+		".pnpm-debug.log*",
+	},
+}
+
+// Dockerfile generates a multi-stage Dockerfile and a .dockerignore for the
+// framework, runtime and package manager of the build settings. Dependencies
+// are installed in their own layer before the sources are copied, so that
+// code changes do not invalidate the dependency cache.
+func Dockerfile(build config.Build) ([]File, error) {
+	language, version := config.ParseRuntime(build.Runtime)
+	if language == "" {
+		return nil, fmt.Errorf("build.runtime %q is not supported: use e.g. python3.11 or nodejs20", build.Runtime)
+	}
+
+	buildArgs, err := parseBuildArgs(build.BuildArgs)
+	if err != nil {
+		return nil, err
+	}
+	argNames := []string{}
+	for _, arg := range buildArgs {
+		argNames = append(argNames, arg.name)
+	}
+	runtimeArgs := []string{}
+	for _, name := range build.RuntimeArgs {
+		found := false
+		for _, arg := range argNames {
+			found = found || arg == name
+		}
+		if !found {
+			return nil, fmt.Errorf("build.runtime_args lists %q, which is not in build.build_args", name)
+		}
+		runtimeArgs = append(runtimeArgs, name)
+	}
+
+	var lines []string
+	switch build.Framework {
+	case "streamlit", "morph":
+		if language != "python" {
+			return nil, fmt.Errorf("%s needs a python runtime, got %s", build.Framework, build.Runtime)
+		}
+		install, ok := pythonInstalls[build.PackageManager]
+		if !ok {
+			return nil, fmt.Errorf("package manager %q is not supported for %s: use poetry, uv or pip", build.PackageManager, build.Framework)
+		}
+		lines = pythonDockerfile(build, version, install, runtimeArgs)
+	case "nextjs":
+		if language != "nodejs" {
+			return nil, fmt.Errorf("nextjs needs a nodejs runtime, got %s", build.Runtime)
+		}
+		install, ok := nodeInstalls[build.PackageManager]
+		if !ok {
+			return nil, fmt.Errorf("package manager %q is not supported for nextjs: use npm, yarn or pnpm", build.PackageManager)
+		}
+		lines = nextjsDockerfile(build, version, install, argNames, runtimeArgs)
+	default:
+		return nil, fmt.Errorf("framework %q is not supported: use streamlit, morph or nextjs", build.Framework)
+	}
+
+	header := []string{
+		"# syntax=docker/dockerfile:1",
+		fmt.Sprintf("# Generated by squad dockerfile for %s (%s, %s).", build.Framework, build.Runtime, build.PackageManager),
+		"# Set build.use_custom_dockerfile to true in squadbase.yml to build with this file.",
+	}
+	if len(runtimeArgs) > 0 {
+		header = append(header, "# Build args listed in build.runtime_args are also exposed to the app at runtime.")
+	}
+
+	ignore := append(append([]string{}, dockerignore...), dockerignoreByLanguage[language]...)
+	return []File{
+		{Name: DockerfileName, Content: []byte(strings.Join(append(header, lines...), "\n") + "\n")},
+		{Name: DockerignoreName, Content: []byte(strings.Join(ignore, "\n") + "\n")},
+	}, nil
+}
+
+func pythonDockerfile(build config.Build, version string, install pythonInstall, runtimeArgs []string) []string {
+	base := fmt.Sprintf("python:%s-slim", version)
+	port := ContainerPort(build.Framework)
+
+	var lines []string
+	if build.Framework == "morph" {
+		lines = append(lines,
+			"",
+			"# Install the morph frontend dependencies",
+			"FROM node:20-slim AS frontend",
+			"WORKDIR /app",
+			"COPY package.json package-lock.json* ./",
+			"RUN --mount=type=cache,target=/root/.npm \\",
+			"    if [ -f package-lock.json ]; then npm ci; else npm install; fi",
+		)
+	}
+
+	lines = append(lines,
+		"",
+		"# Install dependencies into /app/.venv",
+		fmt.Sprintf("FROM %s AS deps", base),
+		"WORKDIR /app",
+		"ENV PIP_DISABLE_PIP_VERSION_CHECK=1",
+	)
+	lines = append(lines, install.setup...)
+	lines = append(lines, fmt.Sprintf("COPY %s ./", install.files))
+	lines = append(lines, install.commands...)
+
+	lines = append(lines,
+		"",
+		"# Run the app",
+		fmt.Sprintf("FROM %s AS runtime", base),
+		"WORKDIR /app",
+		"ENV PYTHONDONTWRITEBYTECODE=1 \\",
+		"    PYTHONUNBUFFERED=1 \\",
+		"    PATH=\"/app/.venv/bin:$PATH\"",
+	)
+	if build.Framework == "morph" {
+		lines = append(lines,
+			"COPY --from=frontend /usr/local/bin/node /usr/local/bin/node",
+			"COPY --from=frontend /usr/local/lib/node_modules /usr/local/lib/node_modules",
+			"RUN ln -s ../lib/node_modules/npm/bin/npm-cli.js /usr/local/bin/npm \\",
+			"    && ln -s ../lib/node_modules/npm/bin/npx-cli.js /usr/local/bin/npx",
+		)
+	}
+	lines = append(lines, argLines(runtimeArgs, true)...)
+	lines = append(lines, "COPY --from=deps /app/.venv /app/.venv")
+	if build.Framework == "morph" {
+		lines = append(lines, "COPY --from=frontend /app/node_modules ./node_modules")
+	}
+	lines = append(lines, "COPY . .")
+
+	var cmd []string
+	if build.Framework == "morph" {
+		lines = append(lines, entrypointWorkdir(build.Entrypoint)...)
+		cmd = []string{"morph", "serve", "--port", fmt.Sprint(port)}
+	} else {
+		cmd = []string{"streamlit", "run", StreamlitScript(build.Entrypoint),
+			fmt.Sprintf("--server.port=%d", port), "--server.address=0.0.0.0", "--server.headless=true"}
+	}
+
+	return append(lines,
+		fmt.Sprintf("EXPOSE %d", port),
+		"CMD "+execForm(cmd),
+	)
+}
+
+func nextjsDockerfile(build config.Build, version string, install nodeInstall, argNames []string, runtimeArgs []string) []string {
+	base := fmt.Sprintf("node:%s-slim", version)
+	port := ContainerPort(build.Framework)
+	cacheMount := fmt.Sprintf("RUN --mount=type=cache,target=%s \\", install.cache)
+
+	lines := []string{
+		"",
+		"# Install dependencies",
+		fmt.Sprintf("FROM %s AS deps", base),
+		"WORKDIR /app",
+	}
+	lines = append(lines, install.setup...)
+	lines = append(lines,
+		fmt.Sprintf("COPY %s ./", install.files),
+		cacheMount,
+		"    "+install.install,
+	)
+
+	lines = append(lines,
+		"",
+		"# Build the app and drop development dependencies",
+		"FROM deps AS builder",
+		"ENV NEXT_TELEMETRY_DISABLED=1",
+	)
+	lines = append(lines, argLines(argNames, false)...)
+	lines = append(lines,
+		"COPY . .",
+		fmt.Sprintf("RUN %s build", install.run),
+		cacheMount,
+		"    "+install.prune,
+	)
+
+	lines = append(lines,
+		"",
+		"# Run the app",
+		fmt.Sprintf("FROM %s AS runtime", base),
+		"WORKDIR /app",
+		"ENV NODE_ENV=production \\",
+		"    NEXT_TELEMETRY_DISABLED=1 \\",
+		fmt.Sprintf("    PORT=%d \\", port),
+		"    HOSTNAME=0.0.0.0",
+	)
+	lines = append(lines, argLines(runtimeArgs, true)...)
+	lines = append(lines, "COPY --from=builder --chown=node:node /app ./")
+	lines = append(lines, entrypointWorkdir(build.Entrypoint)...)
+	return append(lines,
+		"USER node",
+		fmt.Sprintf("EXPOSE %d", port),
+		// The path is absolute because the entrypoint may change WORKDIR.
+		"CMD "+execForm([]string{"/app/node_modules/.bin/next", "start", "-p", fmt.Sprint(port)}),
+	)
+}
+
+// argLines declares the build args of a stage, and exposes them as
+// environment variables when env is set. Only build.runtime_args are
+// exposed, since ENV values are stored in the image config.
+func argLines(names []string, env bool) []string {
+	lines := []string{}
+	for _, name := range names {
+		lines = append(lines, "ARG "+name)
+	}
+	if env && len(names) > 0 {
+		for i, name := range names {
+			line := fmt.Sprintf("    %s=${%s}", name, name)
+			if i == 0 {
+				line = "ENV " + strings.TrimPrefix(line, "    ")
+			}
+			if i < len(names)-1 {
+				line += " \\"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// StreamlitScript returns the script streamlit runs: build.entrypoint when it
+// names a Python file, main.py otherwise.
+func StreamlitScript(entrypoint string) string {
+	if strings.HasSuffix(entrypoint, ".py") {
+		return path.Clean(entrypoint)
+	}
+	return "main.py"
+}
+
+// entrypointWorkdir switches to the app directory when build.entrypoint
+// points below the build context.
+func entrypointWorkdir(entrypoint string) []string {
+	dir := path.Clean(entrypoint)
+	if entrypoint == "" || dir == "." {
+		return nil
+	}
+	return []string{"WORKDIR " + path.Join("/app", dir)}
+}
+
+func execForm(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = fmt.Sprintf("%q", arg)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
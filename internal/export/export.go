@@ -0,0 +1,106 @@
+package export
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/provider"
+)
+
+// File is a generated file, named relative to the output directory.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// App is everything an exporter needs to know about the deployment.
+type App struct {
+	// Name is the app's name, usable as a function or service name.
+	Name      string
+	Build     config.Build
+	Target    *config.Target
+	Resources provider.Resources
+	Traffic   *config.Traffic
+	// Image is the container image to run. Exporters that need one use a
+	// placeholder when it is empty.
+	Image string
+	// PreviousRevision names the revision that traffic.split calls
+	// "previous", for formats that cannot refer to it symbolically.
+	PreviousRevision string
+	// ProjectPath is the project directory relative to the output
+	// directory, for formats that refer to the build context.
+	ProjectPath string
+}
+
+// NewApp collects the deployment of the selected target of cfg.
+func NewApp(name string, cfg *config.Config, target *config.Target) (*App, error) {
+	p, err := provider.Get(target.Provider)
+	if err != nil {
+		return nil, err
+	}
+	return &App{
+		Name:      name,
+		Build:     cfg.Build,
+		Target:    target,
+		Resources: p.Resources(&target.Deployment),
+		Traffic:   cfg.Traffic,
+	}, nil
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// AppName derives an app name from the project directory that is a valid
+// Lambda function, Cloud Run service and Kubernetes object name.
+func AppName(directory string) string {
+	abs, err := filepath.Abs(directory)
+	if err != nil {
+		abs = directory
+	}
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(filepath.Base(abs)), "-")
+	name = strings.Trim(name, "-")
+	if len(name) > 49 {
+		name = strings.TrimRight(name[:49], "-")
+	}
+	if name == "" || name[0] < 'a' || name[0] > 'z' {
+		name = "app-" + name
+	}
+	return strings.TrimRight(name, "-")
+}
+
+// ContainerPort returns the port the app's framework listens on.
+func ContainerPort(framework string) int {
+	switch framework {
+	case "streamlit":
+		return 8501
+	case "nextjs":
+		return 3000
+	}
+	return 8080
+}
+
+// HealthPath returns the HTTP health check endpoint of the framework, or an
+// empty string when it has none.
+func HealthPath(framework string) string {
+	if framework == "streamlit" {
+		return "/_stcore/health"
+	}
+	return ""
+}
+
+// WriteFiles writes the files into directory, creating it when needed.
+func WriteFiles(directory string, files []File) error {
+	for _, file := range files {
+		path := filepath.Join(directory, file.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, file.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// hclBlock is a Terraform block. Its body is rendered the way terraform fmt
+// lays it out, so that generated files do not change when formatted.
+type hclBlock struct {
+	Type   string
+	Labels []string
+	Body   []any // hclAttr, *hclBlock or hclBlank
+}
+
+// hclAttr is an attribute. Value is an expression string or an hclObject.
+type hclAttr struct {
+	Name  string
+	Value any
+}
+
+// hclObject is an object expression written over several lines.
+type hclObject []hclAttr
+
+// hclBlank separates groups of attributes and blocks.
+type hclBlank struct{}
+
+func block(blockType string, labels ...string) *hclBlock {
+	return &hclBlock{Type: blockType, Labels: labels}
+}
+
+func (b *hclBlock) attr(name string, value any) *hclBlock {
+	b.Body = append(b.Body, hclAttr{Name: name, Value: value})
+	return b
+}
+
+func (b *hclBlock) blank() *hclBlock {
+	b.Body = append(b.Body, hclBlank{})
+	return b
+}
+
+func (b *hclBlock) add(child *hclBlock) *hclBlock {
+	b.Body = append(b.Body, child)
+	return b
+}
+
+// hclString quotes s as an HCL string literal.
+func hclString(s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "${", "$${", "%{", "%%{").Replace(s)
+	return `"` + escaped + `"`
+}
+
+func hclNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// renderHCL renders blocks separated by blank lines, with a header comment.
+func renderHCL(header string, blocks ...*hclBlock) []byte {
+	var out strings.Builder
+	for _, line := range strings.Split(header, "\n") {
+		fmt.Fprintf(&out, "# %s\n", line)
+	}
+	for _, b := range blocks {
+		out.WriteString("\n")
+		b.render(&out, 0)
+	}
+	return []byte(out.String())
+}
+
+func (b *hclBlock) render(out *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	out.WriteString(indent + b.Type)
+	for _, label := range b.Labels {
+		out.WriteString(" " + strconv.Quote(label))
+	}
+	if len(b.Body) == 0 {
+		out.WriteString(" {}\n")
+		return
+	}
+	out.WriteString(" {\n")
+	renderBody(out, b.Body, depth+1)
+	out.WriteString(indent + "}\n")
+}
+
+func renderBody(out *strings.Builder, body []any, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for i := 0; i < len(body); i++ {
+		switch item := body[i].(type) {
+		case hclBlank:
+			out.WriteString("\n")
+		case *hclBlock:
+			item.render(out, depth)
+		case hclAttr:
+			// terraform fmt aligns the equals signs of consecutive attributes
+			// written on a single line each. An attribute whose value spans
+			// several lines is not aligned and ends the group.
+			end := i + 1
+			width := len(item.Name)
+			if _, multiline := item.Value.(hclObject); !multiline {
+				for end < len(body) {
+					attr, ok := body[end].(hclAttr)
+					if !ok {
+						break
+					}
+					if _, multiline := attr.Value.(hclObject); multiline {
+						break
+					}
+					width = max(width, len(attr.Name))
+					end++
+				}
+			}
+			for _, a := range body[i:end] {
+				attr := a.(hclAttr)
+				fmt.Fprintf(out, "%s%-*s = ", indent, width, attr.Name)
+				renderValue(out, attr.Value, depth)
+			}
+			i = end - 1
+		}
+	}
+}
+
+func renderValue(out *strings.Builder, value any, depth int) {
+	switch v := value.(type) {
+	case hclObject:
+		if len(v) == 0 {
+			out.WriteString("{}\n")
+			return
+		}
+		out.WriteString("{\n")
+		body := make([]any, 0, len(v))
+		for _, attr := range v {
+			body = append(body, attr)
+		}
+		renderBody(out, body, depth+1)
+		out.WriteString(strings.Repeat("  ", depth) + "}\n")
+	default:
+		fmt.Fprintf(out, "%v\n", v)
+	}
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+//go:embed all:helm/templates
+var helmFiles embed.FS
+
+// helmTemplates returns the chart templates. They read everything specific
+// to the app from values.yaml, so they are the same for every app.
+func helmTemplates() ([]File, error) {
+	files := []File{}
+	err := fs.WalkDir(helmFiles, "helm", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := helmFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		rel, _ := strings.CutPrefix(name, "helm/")
+		files = append(files, File{Name: rel, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart templates: %w", err)
+	}
+	return files, nil
+}
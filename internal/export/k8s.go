@@ -0,0 +1,337 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+)
+
+type k8sDeployment struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   objectMeta        `yaml:"metadata"`
+	Spec       k8sDeploymentSpec `yaml:"spec"`
+}
+
+type k8sDeploymentSpec struct {
+	Replicas int             `yaml:"replicas"`
+	Selector labelSelector   `yaml:"selector"`
+	Template podTemplateSpec `yaml:"template"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+type podTemplateSpec struct {
+	Metadata objectMeta `yaml:"metadata"`
+	Spec     podSpec    `yaml:"spec"`
+}
+
+type podSpec struct {
+	Containers []container `yaml:"containers"`
+}
+
+type k8sService struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   objectMeta     `yaml:"metadata"`
+	Spec       k8sServiceSpec `yaml:"spec"`
+}
+
+type k8sServiceSpec struct {
+	Type     string            `yaml:"type"`
+	Selector map[string]string `yaml:"selector"`
+	Ports    []servicePort     `yaml:"ports"`
+}
+
+type servicePort struct {
+	Name       string `yaml:"name"`
+	Port       int    `yaml:"port"`
+	TargetPort string `yaml:"targetPort"`
+}
+
+type k8sHPA struct {
+	APIVersion string     `yaml:"apiVersion"`
+	Kind       string     `yaml:"kind"`
+	Metadata   objectMeta `yaml:"metadata"`
+	Spec       hpaSpec    `yaml:"spec"`
+}
+
+type hpaSpec struct {
+	ScaleTargetRef scaleTargetRef `yaml:"scaleTargetRef"`
+	MinReplicas    int            `yaml:"minReplicas"`
+	MaxReplicas    int            `yaml:"maxReplicas"`
+	Metrics        []hpaMetric    `yaml:"metrics"`
+}
+
+type scaleTargetRef struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+}
+
+type hpaMetric struct {
+	Type     string             `yaml:"type"`
+	Resource *hpaResourceMetric `yaml:"resource,omitempty"`
+}
+
+type hpaResourceMetric struct {
+	Name   string    `yaml:"name"`
+	Target hpaTarget `yaml:"target"`
+}
+
+type hpaTarget struct {
+	Type               string `yaml:"type"`
+	AverageUtilization int    `yaml:"averageUtilization"`
+}
+
+const (
+	// defaultMaxReplicas caps autoscaling when the target does not set a maximum.
+	defaultMaxReplicas = 10
+	// targetCPUUtilization is the average CPU utilization the HPA scales to.
+	targetCPUUtilization = 70
+	servicePortNumber    = 80
+)
+
+// k8sSettings are the values shared by the plain manifests and the Helm chart.
+type k8sSettings struct {
+	Image       string
+	Port        int
+	Resources   resourceRequirements
+	Probe       *probe
+	MinReplicas int
+	MaxReplicas int
+	Concurrency int
+}
+
+func newK8sSettings(app *App) k8sSettings {
+	r := app.Resources
+
+	quantities := map[string]string{
+		"cpu":    cpuQuantity(r.CPU),
+		"memory": memoryQuantity(r.MemoryMB),
+	}
+	if r.EphemeralStorageMB > 0 {
+		quantities["ephemeral-storage"] = memoryQuantity(r.EphemeralStorageMB)
+	}
+	limits := map[string]string{}
+	for name, quantity := range quantities {
+		limits[name] = quantity
+	}
+
+	settings := k8sSettings{
+		Image:       app.Image,
+		Port:        ContainerPort(app.Build.Framework),
+		Resources:   resourceRequirements{Requests: quantities, Limits: limits},
+		MinReplicas: max(r.MinInstances, 1),
+		MaxReplicas: r.MaxInstances,
+		Concurrency: r.Concurrency,
+	}
+	if settings.Image == "" {
+		settings.Image = fmt.Sprintf("REGISTRY/%s:latest", app.Name)
+	}
+	if settings.MaxReplicas == 0 {
+		settings.MaxReplicas = max(defaultMaxReplicas, settings.MinReplicas)
+	}
+
+	settings.Probe = &probe{TCPSocket: &tcpSocketAction{Port: settings.Port}, PeriodSeconds: 10, FailureThreshold: 3}
+	if path := HealthPath(app.Build.Framework); path != "" {
+		settings.Probe = &probe{HTTPGet: &httpGetAction{Path: path, Port: settings.Port}, PeriodSeconds: 10, FailureThreshold: 3}
+	}
+	return settings
+}
+
+func k8sHeader(app *App, format string) string {
+	return fmt.Sprintf("Generated by squad export %s from %s (target %q).\nRe-run the command after changing %s instead of editing this file.",
+		format, config.FileName, app.Target.Name, config.FileName)
+}
+
+// KubernetesManifests generates a Deployment, a Service and a
+// HorizontalPodAutoscaler sized like the deployment target.
+func KubernetesManifests(app *App) ([]File, error) {
+	s := newK8sSettings(app)
+	labels := map[string]string{"app.kubernetes.io/name": app.Name}
+	meta := objectMeta{Name: app.Name, Labels: labels}
+	header := k8sHeader(app, "k8s")
+
+	deployment := k8sDeployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   meta,
+		Spec: k8sDeploymentSpec{
+			Replicas: s.MinReplicas,
+			Selector: labelSelector{MatchLabels: labels},
+			Template: podTemplateSpec{
+				Metadata: objectMeta{Labels: labels},
+				Spec: podSpec{Containers: []container{{
+					Name:           "app",
+					Image:          s.Image,
+					Ports:          []containerPort{{Name: "http", ContainerPort: s.Port}},
+					Resources:      s.Resources,
+					ReadinessProbe: s.Probe,
+				}}},
+			},
+		},
+	}
+
+	service := k8sService{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   meta,
+		Spec: k8sServiceSpec{
+			Type:     "ClusterIP",
+			Selector: labels,
+			Ports:    []servicePort{{Name: "http", Port: servicePortNumber, TargetPort: "http"}},
+		},
+	}
+
+	hpa := k8sHPA{
+		APIVersion: "autoscaling/v2",
+		Kind:       "HorizontalPodAutoscaler",
+		Metadata:   meta,
+		Spec: hpaSpec{
+			ScaleTargetRef: scaleTargetRef{APIVersion: "apps/v1", Kind: "Deployment", Name: app.Name},
+			MinReplicas:    s.MinReplicas,
+			MaxReplicas:    s.MaxReplicas,
+			Metrics: []hpaMetric{{
+				Type: "Resource",
+				Resource: &hpaResourceMetric{
+					Name:   "cpu",
+					Target: hpaTarget{Type: "Utilization", AverageUtilization: targetCPUUtilization},
+				},
+			}},
+		},
+	}
+
+	// The concurrency limit needs a per-pod metric that only a metrics
+	// adapter serves, so it is left as a stanza to uncomment.
+	hpaFooter := fmt.Sprintf(`
+To also scale on in-flight requests, add this entry to spec.metrics once a metrics
+adapter serves a per-pod metric, and replace the metric name with that metric:
+  - type: Pods
+    pods:
+      metric:
+        name: http_requests_in_flight
+      target:
+        type: AverageValue
+        averageValue: "%d"`, s.Concurrency)
+
+	files := []File{}
+	for _, manifest := range []struct {
+		name   string
+		object any
+		footer string
+	}{
+		{"deployment.yaml", deployment, ""},
+		{"service.yaml", service, ""},
+		{"hpa.yaml", hpa, hpaFooter},
+	} {
+		content, err := marshalManifests(header, manifest.object)
+		if err != nil {
+			return nil, err
+		}
+		if manifest.footer != "" {
+			for _, line := range strings.Split(manifest.footer, "\n") {
+				content = append(content, []byte(strings.TrimRight("# "+line, " ")+"\n")...)
+			}
+		}
+		files = append(files, File{Name: manifest.name, Content: content})
+	}
+	return files, nil
+}
+
+// splitImage splits an image reference into repository and tag.
+func splitImage(image string) (string, string) {
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, "latest"
+}
+
+// HelmChart generates a Helm chart whose values.yaml carries the settings
+// of the deployment target. The concurrency limit can drive autoscaling
+// through a per-pod metric once autoscaling.concurrency.metric is set to a
+// metric served by a metrics adapter.
+func HelmChart(app *App) ([]File, error) {
+	s := newK8sSettings(app)
+	repository, tag := splitImage(s.Image)
+
+	chart := yamlMap(
+		"apiVersion", "v2",
+		"name", app.Name,
+		"description", fmt.Sprintf("%s, deployed with Squadbase (target %s)", app.Name, app.Target.Name),
+		"type", "application",
+		"version", "0.1.0",
+		"appVersion", tag,
+	)
+
+	probe := yamlMap("periodSeconds", s.Probe.PeriodSeconds, "failureThreshold", s.Probe.FailureThreshold)
+	if s.Probe.HTTPGet != nil {
+		probe.Content = append(yamlMap("httpGet", yamlMap("path", s.Probe.HTTPGet.Path, "port", "http")).Content, probe.Content...)
+	} else {
+		probe.Content = append(yamlMap("tcpSocket", yamlMap("port", "http")).Content, probe.Content...)
+	}
+
+	quantities := func(values map[string]string) any {
+		node := yamlMap()
+		for _, name := range []string{"cpu", "memory", "ephemeral-storage"} {
+			if value, ok := values[name]; ok {
+				appendPairs(node, name, value)
+			}
+		}
+		return node
+	}
+
+	values := yamlMap(
+		"image", yamlMap(
+			"repository", repository,
+			"tag", tag,
+			"pullPolicy", "IfNotPresent",
+		),
+		"containerPort", s.Port,
+		"replicaCount", s.MinReplicas,
+		"service", yamlMap(
+			"type", "ClusterIP",
+			"port", servicePortNumber,
+		),
+		"resources", yamlMap(
+			"requests", quantities(s.Resources.Requests),
+			"limits", quantities(s.Resources.Limits),
+		),
+		"readinessProbe", probe,
+		"autoscaling", yamlMap(
+			"enabled", true,
+			"minReplicas", s.MinReplicas,
+			"maxReplicas", s.MaxReplicas,
+			"targetCPUUtilizationPercentage", targetCPUUtilization,
+			"concurrency", yamlMap(
+				"metric", yamlComment("", "per-pod in-flight requests metric served by a metrics adapter; empty disables it"),
+				"target", strconv.Itoa(s.Concurrency),
+			),
+		),
+		"env", yamlMap(),
+	)
+
+	chartContent, err := marshalManifests(k8sHeader(app, "k8s --helm"), chart)
+	if err != nil {
+		return nil, err
+	}
+	valuesContent, err := marshalManifests(k8sHeader(app, "k8s --helm"), values)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []File{
+		{Name: "Chart.yaml", Content: chartContent},
+		{Name: "values.yaml", Content: valuesContent},
+	}
+	templates, err := helmTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return append(files, templates...), nil
+}
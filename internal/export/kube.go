@@ -0,0 +1,162 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The types below cover the parts of the Kubernetes object model that the
+// Knative and Kubernetes exports write. Fields are declared in the order
+// kubectl and gcloud print them.
+
+type objectMeta struct {
+	Name        string            `yaml:"name,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type container struct {
+	Name           string               `yaml:"name,omitempty"`
+	Image          string               `yaml:"image"`
+	Ports          []containerPort      `yaml:"ports,omitempty"`
+	Env            []envVar             `yaml:"env,omitempty"`
+	Resources      resourceRequirements `yaml:"resources"`
+	VolumeMounts   []volumeMount        `yaml:"volumeMounts,omitempty"`
+	StartupProbe   *probe               `yaml:"startupProbe,omitempty"`
+	ReadinessProbe *probe               `yaml:"readinessProbe,omitempty"`
+	LivenessProbe  *probe               `yaml:"livenessProbe,omitempty"`
+}
+
+type containerPort struct {
+	Name          string `yaml:"name,omitempty"`
+	ContainerPort int    `yaml:"containerPort"`
+}
+
+type envVar struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type resourceRequirements struct {
+	Requests map[string]string `yaml:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty"`
+}
+
+type volumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+}
+
+type volume struct {
+	Name     string        `yaml:"name"`
+	EmptyDir *emptyDirSpec `yaml:"emptyDir,omitempty"`
+}
+
+type emptyDirSpec struct {
+	Medium    string `yaml:"medium,omitempty"`
+	SizeLimit string `yaml:"sizeLimit,omitempty"`
+}
+
+type probe struct {
+	HTTPGet          *httpGetAction   `yaml:"httpGet,omitempty"`
+	TCPSocket        *tcpSocketAction `yaml:"tcpSocket,omitempty"`
+	PeriodSeconds    int              `yaml:"periodSeconds,omitempty"`
+	FailureThreshold int              `yaml:"failureThreshold,omitempty"`
+}
+
+type httpGetAction struct {
+	Path string `yaml:"path"`
+	Port int    `yaml:"port"`
+}
+
+type tcpSocketAction struct {
+	Port int `yaml:"port"`
+}
+
+// marshalManifests renders objects as a YAML stream with a header comment.
+func marshalManifests(header string, objects ...any) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, line := range bytes.Split([]byte(header), []byte("\n")) {
+		fmt.Fprintf(&buf, "# %s\n", line)
+	}
+	for i, object := range objects {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(object); err != nil {
+			return nil, fmt.Errorf("failed to render manifest: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to render manifest: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// cpuQuantity formats a CPU amount as a Kubernetes quantity: whole CPUs as
+// a number and fractions in millicores, as Cloud Run also accepts them.
+func cpuQuantity(cpu float64) string {
+	if cpu == math.Trunc(cpu) {
+		return strconv.FormatFloat(cpu, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%dm", int(math.Round(cpu*1000)))
+}
+
+// memoryQuantity formats a size in MiB as a Kubernetes quantity.
+func memoryQuantity(mb int) string {
+	return fmt.Sprintf("%dMi", mb)
+}
+
+// yamlMap builds a mapping node from alternating keys and values. Values
+// are nodes, strings, ints or bools.
+func yamlMap(pairs ...any) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	appendPairs(node, pairs...)
+	return node
+}
+
+func appendPairs(node *yaml.Node, pairs ...any) {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		node.Content = append(node.Content, yamlValue(pairs[i]), yamlValue(pairs[i+1]))
+	}
+}
+
+func yamlSeq(values ...any) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+	for _, value := range values {
+		node.Content = append(node.Content, yamlValue(value))
+	}
+	return node
+}
+
+// yamlTagged builds a scalar with a CloudFormation short-form tag such as !Ref.
+func yamlTagged(tag string, value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: value}
+}
+
+// yamlComment builds a string scalar with a line comment.
+func yamlComment(value string, comment string) *yaml.Node {
+	node := yamlValue(value)
+	node.LineComment = "# " + comment
+	return node
+}
+
+func yamlValue(value any) *yaml.Node {
+	switch v := value.(type) {
+	case *yaml.Node:
+		return v
+	case int:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(v)}
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(v)}
+	}
+	node := &yaml.Node{}
+	node.SetString(fmt.Sprint(value))
+	return node
+}
@@ -0,0 +1,173 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/provider"
+)
+
+// SAMTemplate generates an AWS SAM template.yaml with an image function
+// configured like the aws deployment target.
+func SAMTemplate(app *App) ([]File, error) {
+	if app.Target.Provider != "aws" {
+		return nil, fmt.Errorf("sam export needs an aws deployment target, %q uses %s", app.Target.Name, app.Target.Provider)
+	}
+	r := app.Resources
+
+	buildArgs, err := parseBuildArgs(app.Build.BuildArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := yamlMap(
+		"Region", yamlMap(
+			"Type", "String",
+			"Default", r.Region,
+			"Description", "Region of the ECR repository that holds the image",
+		),
+		"ImageRepository", yamlMap(
+			"Type", "String",
+			"Default", app.Name,
+			"Description", "ECR repository of the image",
+		),
+		"ImageTag", yamlMap(
+			"Type", "String",
+			"Default", "latest",
+			"Description", "Tag of the image to deploy",
+		),
+		"FunctionUrlAuthType", yamlMap(
+			"Type", "String",
+			"Default", "AWS_IAM",
+			"AllowedValues", yamlSeq("AWS_IAM", "NONE"),
+			"Description", "Authorization of the function URL: AWS_IAM, or NONE for public access",
+		),
+	)
+	// Build args become parameters without a default, so that their values,
+	// possibly resolved from .env, never land in the checked-in template.
+	// Only the ones named in build.runtime_args reach the function.
+	runtimeArgs := map[string]bool{}
+	for _, name := range app.Build.RuntimeArgs {
+		runtimeArgs[name] = true
+	}
+	variables := yamlMap()
+	dockerBuildArgs := yamlMap()
+	for _, arg := range buildArgs {
+		name := buildArgParameter(arg.name)
+		description := fmt.Sprintf("Build arg %s", arg.name)
+		if runtimeArgs[arg.name] {
+			description += ", also passed to the function as an environment variable"
+			appendPairs(variables, arg.name, yamlTagged("!Ref", name))
+		}
+		parameter := yamlMap("Type", "String", "Description", description)
+		if config.LikelySecret(arg.name) {
+			appendPairs(parameter, "NoEcho", true)
+		}
+		appendPairs(parameters, name, parameter)
+		appendPairs(dockerBuildArgs, arg.name, yamlTagged("!Ref", name))
+	}
+
+	properties := yamlMap(
+		"FunctionName", app.Name,
+		"PackageType", "Image",
+		"ImageUri", yamlTagged("!Sub", "${AWS::AccountId}.dkr.ecr.${Region}.amazonaws.com/${ImageRepository}:${ImageTag}"),
+		"MemorySize", r.MemoryMB,
+		"Timeout", r.TimeoutSeconds,
+		"EphemeralStorage", yamlMap("Size", r.EphemeralStorageMB),
+		"AutoPublishAlias", provider.LambdaLiveAlias,
+	)
+	if r.MinInstances > 0 {
+		appendPairs(properties, "ProvisionedConcurrencyConfig", yamlMap("ProvisionedConcurrentExecutions", r.MinInstances))
+	}
+	appendPairs(properties, "FunctionUrlConfig", yamlMap("AuthType", yamlTagged("!Ref", "FunctionUrlAuthType")))
+	if len(variables.Content) > 0 {
+		appendPairs(properties, "Environment", yamlMap("Variables", variables))
+	}
+
+	contextDir := path.Join(app.ProjectPath, app.Build.Context)
+	if contextDir == "" {
+		contextDir = "."
+	}
+	metadata := yamlMap(
+		"DockerContext", contextDir,
+		"Dockerfile", "Dockerfile",
+		"DockerTag", "latest",
+	)
+	if len(dockerBuildArgs.Content) > 0 {
+		appendPairs(metadata, "DockerBuildArgs", dockerBuildArgs)
+	}
+
+	template := yamlMap(
+		"AWSTemplateFormatVersion", "2010-09-09",
+		"Transform", "AWS::Serverless-2016-10-31",
+		"Description", fmt.Sprintf("%s, deployed with Squadbase (target %s)", app.Name, app.Target.Name),
+		"Parameters", parameters,
+		"Resources", yamlMap(
+			"AppFunction", yamlMap(
+				"Type", "AWS::Serverless::Function",
+				"Properties", properties,
+				"Metadata", metadata,
+			),
+		),
+		"Outputs", yamlMap(
+			"FunctionArn", yamlMap(
+				"Description", "ARN of the Lambda function",
+				"Value", yamlTagged("!GetAtt", "AppFunction.Arn"),
+			),
+			"FunctionUrl", yamlMap(
+				"Description", "Function URL of the live alias",
+				"Value", yamlTagged("!GetAtt", "AppFunctionUrl.FunctionUrl"),
+			),
+		),
+	)
+
+	// The function is reached through its function URL; there is no Api
+	// event, so sam local start-api has no routes to serve.
+	header := fmt.Sprintf("Generated by squad export sam from %s (target %q).\nBuild and deploy with: sam build && sam deploy --guided\n"+
+		"The image must handle Lambda invocations, for example with the AWS Lambda Web Adapter\n"+
+		"(COPY --from=public.ecr.aws/awsguru/aws-lambda-adapter:0.8.4 /lambda-adapter /opt/extensions/lambda-adapter).",
+		config.FileName, app.Target.Name)
+	if len(buildArgs) > 0 {
+		header += "\nBuild args are parameters without defaults; sam deploy --guided asks for their values."
+	}
+	if app.Traffic != nil {
+		header += "\nThe traffic block is applied by squad deploy; this template routes all traffic to the live alias."
+	}
+	content, err := marshalManifests(header, template)
+	if err != nil {
+		return nil, err
+	}
+	return []File{{Name: "template.yaml", Content: content}}, nil
+}
+
+type buildArg struct {
+	name  string
+	value string
+}
+
+func parseBuildArgs(args []string) ([]buildArg, error) {
+	parsed := []buildArg{}
+	for _, arg := range args {
+		name, value, ok := strings.Cut(arg, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid build arg %q: use NAME=value", arg)
+		}
+		parsed = append(parsed, buildArg{name: name, value: value})
+	}
+	return parsed, nil
+}
+
+// buildArgParameter turns a build arg such as API_BASE_URL into the
+// CloudFormation parameter name BuildArgApiBaseUrl.
+func buildArgParameter(name string) string {
+	var out strings.Builder
+	out.WriteString("BuildArg")
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		out.WriteString(strings.ToUpper(part[:1]) + strings.ToLower(part[1:]))
+	}
+	return out.String()
+}
@@ -0,0 +1,267 @@
+package export
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/provider"
+)
+
+// Terraform generates a Terraform module (main.tf, variables.tf and
+// outputs.tf) that deploys the app. The output only depends on the app, so
+// it can be checked in and diffed.
+func Terraform(app *App) ([]File, error) {
+	switch app.Target.Provider {
+	case "aws":
+		return lambdaTerraform(app), nil
+	case "gcp":
+		return cloudRunTerraform(app), nil
+	}
+	return nil, fmt.Errorf("terraform export is not supported for provider %q (supported: aws, gcp)", app.Target.Provider)
+}
+
+func terraformHeader(app *App, notes ...string) string {
+	header := fmt.Sprintf("Generated by squad export terraform from %s (target %q).\nRe-run the command after changing %s instead of editing this file.",
+		config.FileName, app.Target.Name, config.FileName)
+	for _, note := range notes {
+		header += "\n" + note
+	}
+	return header
+}
+
+func requiredProviders(name string, source string, version string) *hclBlock {
+	return block("terraform").
+		attr("required_version", hclString(">= 1.3")).
+		blank().
+		add(block("required_providers").
+			attr(name, hclObject{
+				{Name: "source", Value: hclString(source)},
+				{Name: "version", Value: hclString(version)},
+			}))
+}
+
+func variable(name string, description string, varType string, defaultValue string) *hclBlock {
+	v := block("variable", name).
+		attr("description", hclString(description)).
+		attr("type", varType)
+	if defaultValue != "" {
+		v.attr("default", defaultValue)
+	}
+	return v
+}
+
+func output(name string, description string, value string) *hclBlock {
+	return block("output", name).
+		attr("description", hclString(description)).
+		attr("value", value)
+}
+
+// usesRevision reports whether the traffic split routes to the given revision.
+func usesRevision(traffic *config.Traffic, revision string) bool {
+	return slices.ContainsFunc(traffic.Splits(), func(s config.TrafficSplit) bool {
+		return s.Revision == revision
+	})
+}
+
+func rollbackNote(app *App) []string {
+	if app.Traffic == nil || app.Traffic.RollbackThreshold == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("traffic.rollback_threshold (%s%% errors) is enforced by squad deploy, not by Terraform.",
+		hclNumber(app.Traffic.RollbackThreshold))}
+}
+
+func lambdaTerraform(app *App) []File {
+	r := app.Resources
+	notes := append([]string{fmt.Sprintf("Apply with an aws provider configured for region %s.", r.Region)}, rollbackNote(app)...)
+
+	lambdaVersion := func(revision string) string {
+		switch revision {
+		case config.RevisionLatest:
+			return "aws_lambda_function.app.version"
+		case config.RevisionPrevious:
+			return "var.previous_version"
+		}
+		return hclString(revision)
+	}
+
+	function := block("resource", "aws_lambda_function", "app").
+		attr("function_name", "var.function_name").
+		attr("role", "aws_iam_role.app.arn").
+		attr("package_type", hclString("Image")).
+		attr("image_uri", "var.image_uri").
+		attr("memory_size", r.MemoryMB).
+		attr("timeout", r.TimeoutSeconds).
+		attr("publish", "true").
+		blank().
+		add(block("ephemeral_storage").attr("size", r.EphemeralStorageMB)).
+		blank().
+		add(block("environment").attr("variables", "var.environment"))
+
+	blocks := []*hclBlock{
+		requiredProviders("aws", "hashicorp/aws", ">= 5.0"),
+		block("data", "aws_iam_policy_document", "assume_role").
+			add(block("statement").
+				attr("actions", `["sts:AssumeRole"]`).
+				blank().
+				add(block("principals").
+					attr("type", hclString("Service")).
+					attr("identifiers", `["lambda.amazonaws.com"]`))),
+		block("resource", "aws_iam_role", "app").
+			attr("name", `"${var.function_name}-role"`).
+			attr("assume_role_policy", "data.aws_iam_policy_document.assume_role.json"),
+		block("resource", "aws_iam_role_policy_attachment", "logs").
+			attr("role", "aws_iam_role.app.name").
+			attr("policy_arn", hclString("arn:aws:iam::aws:policy/service-role/AWSLambdaBasicExecutionRole")),
+		function,
+	}
+
+	for _, alias := range provider.LambdaAliases(app.Traffic) {
+		label := "live"
+		if alias.Name != provider.LambdaLiveAlias {
+			label = "tag"
+		}
+		b := block("resource", "aws_lambda_alias", label).
+			attr("name", hclString(alias.Name)).
+			attr("function_name", "aws_lambda_function.app.function_name").
+			attr("function_version", lambdaVersion(alias.FunctionVersion))
+		if len(alias.AdditionalVersionWeights) > 0 {
+			weights := hclObject{}
+			for _, split := range app.Traffic.Splits() {
+				if weight, ok := alias.AdditionalVersionWeights[split.Revision]; ok {
+					key := lambdaVersion(split.Revision)
+					if split.Revision == config.RevisionLatest || split.Revision == config.RevisionPrevious {
+						key = "(" + key + ")"
+					}
+					weights = append(weights, hclAttr{Name: key, Value: hclNumber(weight)})
+				}
+			}
+			b.blank().add(block("routing_config").attr("additional_version_weights", weights))
+		}
+		blocks = append(blocks, b)
+	}
+
+	if r.MinInstances > 0 {
+		blocks = append(blocks, block("resource", "aws_lambda_provisioned_concurrency_config", "live").
+			attr("function_name", "aws_lambda_alias.live.function_name").
+			attr("qualifier", "aws_lambda_alias.live.name").
+			attr("provisioned_concurrent_executions", r.MinInstances))
+	}
+
+	blocks = append(blocks, block("resource", "aws_lambda_function_url", "live").
+		attr("function_name", "aws_lambda_alias.live.function_name").
+		attr("qualifier", "aws_lambda_alias.live.name").
+		attr("authorization_type", "var.function_url_authorization_type"))
+
+	variables := []*hclBlock{
+		variable("image_uri", "URI of the container image in Amazon ECR, e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com/app:v1", "string", ""),
+		variable("function_name", "Name of the Lambda function", "string", hclString(app.Name)),
+		variable("environment", "Environment variables of the function", "map(string)", "{}"),
+		variable("function_url_authorization_type", "Authorization of the function URL: AWS_IAM or NONE for public access", "string", hclString("AWS_IAM")),
+	}
+	if usesRevision(app.Traffic, config.RevisionPrevious) {
+		variables = append(variables, variable("previous_version", "Lambda version that served traffic before this deployment", "string", ""))
+	}
+
+	outputs := []*hclBlock{
+		output("function_name", "Name of the Lambda function", "aws_lambda_function.app.function_name"),
+		output("function_arn", "ARN of the Lambda function", "aws_lambda_function.app.arn"),
+		output("live_alias_arn", "ARN of the alias that serves production traffic", "aws_lambda_alias.live.arn"),
+		output("url", "Function URL of the live alias", "aws_lambda_function_url.live.function_url"),
+	}
+
+	return []File{
+		{Name: "main.tf", Content: renderHCL(terraformHeader(app, notes...), blocks...)},
+		{Name: "variables.tf", Content: renderHCL(terraformHeader(app), variables...)},
+		{Name: "outputs.tf", Content: renderHCL(terraformHeader(app), outputs...)},
+	}
+}
+
+func cloudRunTerraform(app *App) []File {
+	r := app.Resources
+
+	containers := block("containers").
+		attr("image", "var.image").
+		blank().
+		add(block("ports").attr("container_port", ContainerPort(app.Build.Framework))).
+		blank().
+		add(block("resources").attr("limits", hclObject{
+			{Name: "cpu", Value: hclString(cpuQuantity(r.CPU))},
+			{Name: "memory", Value: hclString(fmt.Sprintf("%dMi", r.MemoryMB))},
+		})).
+		blank().
+		add(block("dynamic", "env").
+			attr("for_each", "var.environment").
+			add(block("content").
+				attr("name", "env.key").
+				attr("value", "env.value")))
+
+	service := block("resource", "google_cloud_run_v2_service", "app").
+		attr("name", "var.service_name").
+		attr("project", "var.project_id").
+		attr("location", "var.region").
+		attr("ingress", hclString("INGRESS_TRAFFIC_ALL")).
+		blank().
+		add(block("template").
+			attr("max_instance_request_concurrency", r.Concurrency).
+			attr("timeout", hclString(fmt.Sprintf("%ds", r.TimeoutSeconds))).
+			blank().
+			add(block("scaling").attr("min_instance_count", r.MinInstances)).
+			blank().
+			add(containers))
+
+	for _, target := range provider.CloudRunTrafficTargets(app.Traffic) {
+		traffic := block("traffic")
+		if target.LatestRevision {
+			traffic.attr("type", hclString("TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST"))
+		} else {
+			traffic.attr("type", hclString("TRAFFIC_TARGET_ALLOCATION_TYPE_REVISION"))
+			if target.RevisionName == config.RevisionPrevious {
+				traffic.attr("revision", "var.previous_revision")
+			} else {
+				traffic.attr("revision", hclString(target.RevisionName))
+			}
+		}
+		traffic.attr("percent", target.Percent)
+		if target.Tag != "" {
+			traffic.attr("tag", hclString(target.Tag))
+		}
+		service.blank().add(traffic)
+	}
+
+	blocks := []*hclBlock{
+		requiredProviders("google", "hashicorp/google", ">= 5.0"),
+		service,
+		block("resource", "google_cloud_run_v2_service_iam_member", "public").
+			attr("count", "var.allow_unauthenticated ? 1 : 0").
+			attr("project", "google_cloud_run_v2_service.app.project").
+			attr("location", "google_cloud_run_v2_service.app.location").
+			attr("name", "google_cloud_run_v2_service.app.name").
+			attr("role", hclString("roles/run.invoker")).
+			attr("member", hclString("allUsers")),
+	}
+
+	variables := []*hclBlock{
+		variable("project_id", "Google Cloud project to deploy to", "string", ""),
+		variable("image", "URI of the container image, e.g. us-docker.pkg.dev/my-project/apps/app:v1", "string", ""),
+		variable("service_name", "Name of the Cloud Run service", "string", hclString(app.Name)),
+		variable("region", "Region of the Cloud Run service", "string", hclString(r.Region)),
+		variable("environment", "Environment variables of the service", "map(string)", "{}"),
+		variable("allow_unauthenticated", "Allow public access to the service", "bool", "false"),
+	}
+	if usesRevision(app.Traffic, config.RevisionPrevious) {
+		variables = append(variables, variable("previous_revision", "Cloud Run revision that served traffic before this deployment", "string", ""))
+	}
+
+	outputs := []*hclBlock{
+		output("service_name", "Name of the Cloud Run service", "google_cloud_run_v2_service.app.name"),
+		output("uri", "URL of the Cloud Run service", "google_cloud_run_v2_service.app.uri"),
+	}
+
+	return []File{
+		{Name: "main.tf", Content: renderHCL(terraformHeader(app, rollbackNote(app)...), blocks...)},
+		{Name: "variables.tf", Content: renderHCL(terraformHeader(app), variables...)},
+		{Name: "outputs.tf", Content: renderHCL(terraformHeader(app), outputs...)},
+	}
+}
@@ -9,9 +9,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
 	"github.com/squadbase/squadbase/internal/templates"
 	"github.com/squadbase/squadbase/internal/ui"
 )
@@ -23,6 +28,7 @@ type Config struct {
 	AuthorName         string
 	AuthorEmail        string
 	DeploymentProvider string
+	DeploymentRegion   string
 }
 
 func CreateProject(projectName string, templateName string, config *Config) error {
@@ -98,10 +104,14 @@ func CreateProject(projectName string, templateName string, config *Config) erro
 		}
 	}
 
-	err = CreateSquadbaseYml(projectName, templateName, config.Version, config.PackageManager, config.DeploymentProvider)
+	env := DetectEnvVars(projectName)
+	err = CreateSquadbaseYml(projectName, templateName, config.Version, config.PackageManager, config.DeploymentProvider, config.DeploymentRegion, env)
 	if err != nil {
 		return fmt.Errorf("failed to create squadbase.yml: %w", err)
 	}
+	if err := createEnvExample(projectName, env); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -112,6 +122,8 @@ func CreateSquadbaseYml(
 	languageVersion string,
 	packageManager string,
 	deploymentProvider string,
+	deploymentRegion string,
+	env []config.EnvVar,
 ) error {
 	language := "python"
 	if templateName == "nextjs" {
@@ -130,7 +142,7 @@ func CreateSquadbaseYml(
 		}
 	}
 
-	content := fmt.Sprintf(`version: '1'
+	content := fmt.Sprintf(`version: '%s'
 # Build Settings
 build:
     # These settings are required when use_custom_dockerfile is false
@@ -142,37 +154,195 @@ build:
     # context: .
     # build_args:
     #   - ARG_NAME=value
-    #   - ANOTHER_ARG=value
+    #   - ANOTHER_ARG=${ANOTHER_ARG:-default} # resolved from the environment or .env
+    # runtime_args: # build args also set as environment variables of the app
+    #   - ARG_NAME
 
 # Deployment Settings
 deployment:
     provider: %s
-    # These settings are used only when you want to customize the deployment settings
-    # aws:
-    #     region: ap-northeast-1
-    #     memory: 1024
-    #     timeout: 30
-    #     provisioned_concurrency: 0
-    #     ephemeral_storage: 512MB
-    # gcp:
-    #     region: us-central1
-    #     memory: 1024
-    #     cpu: 1
-    #     concurrency: 80
-    #     timeout: 60
-    #     min_instances: 0
-    #     ephemeral_storage: 100Mi
-`,
+%s
+# Traffic Settings
+# Gradual rollouts, supported on aws (Lambda alias weights) and gcp (Cloud Run traffic)
+# traffic:
+#     split:
+#         - revision: latest # the revision created by the deployment
+#           percent: 10
+#         - revision: previous # the revision serving traffic before the deployment
+#           percent: 90
+#     tag: preview # serves the latest revision at its own preview URL
+#     rollback_threshold: 5 # roll back when the latest revision's error rate exceeds this percentage
+
+# Environment Variables
+# Declare the variables the app reads; set their local values in .env with squad env set NAME=VALUE
+%s`,
+		config.CurrentVersion,
 		language, languageVersion, comment,
 		templateName,
 		packageManager, packageManagerComment,
 		deploymentProvider,
+		deploymentSettings(deploymentProvider, deploymentRegion),
+		envSettings(env),
 	)
 
 	filePath := filepath.Join(projectPath, "squadbase.yml")
 	return os.WriteFile(filePath, []byte(content), 0644)
 }
 
+// deploymentSettings renders the provider blocks of the deployment section.
+// The selected provider gets a block with its region when one was chosen;
+// every other provider is written as a commented example of its defaults.
+func deploymentSettings(deploymentProvider string, deploymentRegion string) string {
+	var content strings.Builder
+
+	if deploymentRegion != "" {
+		fmt.Fprintf(&content, "    %s:\n", deploymentProvider)
+		fmt.Fprintf(&content, "        region: %s\n", deploymentRegion)
+		content.WriteString("        # These settings are used only when you want to customize the deployment settings\n")
+		if p, err := provider.Get(deploymentProvider); err == nil {
+			writeProviderSettings(&content, p, "        # ", false)
+		}
+		content.WriteString("    # Settings for the other providers, used when you change deployment.provider\n")
+	} else {
+		content.WriteString("    # These settings are used only when you want to customize the deployment settings\n")
+	}
+
+	for _, p := range provider.All() {
+		if deploymentRegion != "" && p.Name() == deploymentProvider {
+			continue
+		}
+		fmt.Fprintf(&content, "    # %s:\n", p.Name())
+		writeProviderSettings(&content, p, "    #     ", true)
+	}
+
+	return content.String()
+}
+
+// envSettings renders the env section with the declared variables, or a
+// commented example when there are none.
+func envSettings(env []config.EnvVar) string {
+	if len(env) == 0 {
+		return `# env:
+#     - name: OPENAI_API_KEY
+#       description: API key of the OpenAI API
+#       required: true
+#       secret: true
+`
+	}
+
+	var content strings.Builder
+	content.WriteString("env:\n")
+	for _, v := range env {
+		fmt.Fprintf(&content, "    - name: %s\n", v.Name)
+		if v.Description != "" {
+			fmt.Fprintf(&content, "      description: %s\n", v.Description)
+		}
+		if v.Required {
+			content.WriteString("      required: true\n")
+		}
+		if v.Secret {
+			content.WriteString("      secret: true\n")
+		}
+	}
+	return content.String()
+}
+
+// DetectEnvVars declares the variables listed in the .env.example or .env
+// file of directory, guessing from their names which ones are secret.
+func DetectEnvVars(directory string) []config.EnvVar {
+	names := map[string]bool{}
+	for _, name := range []string{config.EnvExampleFileName, dotenv.FileName} {
+		values, err := dotenv.Read(filepath.Join(directory, name))
+		if err != nil {
+			continue
+		}
+		for key := range values {
+			names[key] = true
+		}
+	}
+
+	env := []config.EnvVar{}
+	for name := range names {
+		env = append(env, config.EnvVar{Name: name, Secret: config.LikelySecret(name)})
+	}
+	slices.SortFunc(env, func(a, b config.EnvVar) int { return strings.Compare(a.Name, b.Name) })
+	return env
+}
+
+func createEnvExample(directory string, env []config.EnvVar) error {
+	_, err := config.WriteEnvExample(directory, env)
+	return err
+}
+
+func writeProviderSettings(content *strings.Builder, p provider.Provider, prefix string, withRegion bool) {
+	if catalog, err := resources.ForProvider(p.Name()); err == nil && len(catalog.Presets) > 0 {
+		fmt.Fprintf(content, "%spreset: %s # %s; values set below override the preset\n",
+			prefix, catalog.Presets[0].Name, strings.Join(catalog.PresetNames(), ", "))
+	}
+	for _, setting := range p.Defaults() {
+		if setting.Key == "region" && !withRegion {
+			continue
+		}
+		fmt.Fprintf(content, "%s%s: %v\n", prefix, setting.Key, setting.Value)
+	}
+}
+
+// MergeSquadbaseYml applies the settings chosen during init to an existing
+// squadbase.yml document. The provider and region are written to the named
+// deployment target (see SetDeploymentTarget). Comments and keys that init
+// does not manage are left as they are.
+func MergeSquadbaseYml(
+	doc *config.Document,
+	templateName string,
+	languageVersion string,
+	packageManager string,
+	deploymentTarget string,
+	deploymentProvider string,
+	deploymentRegion string,
+) {
+	language := "python"
+	if templateName == "nextjs" {
+		language = "nodejs"
+	}
+
+	if doc.GetString("version") == "" {
+		doc.SetString(config.CurrentVersion, "version")
+	}
+	doc.SetString(language+languageVersion, "build", "runtime")
+	doc.SetString(templateName, "build", "framework")
+	if packageManager != "" {
+		doc.SetString(packageManager, "build", "package_manager")
+	}
+	SetDeploymentTarget(doc, deploymentTarget, deploymentProvider, deploymentRegion)
+}
+
+// SetDeploymentTarget sets the provider and region of a deployment target.
+// An empty name or config.DefaultTargetName updates the single deployment of
+// a file without deployment.targets. Any other name adds or updates an entry
+// of deployment.targets, first moving a single deployment there as
+// config.DefaultTargetName.
+func SetDeploymentTarget(doc *config.Document, name string, deploymentProvider string, deploymentRegion string) {
+	if name == "" {
+		name = config.DefaultTargetName
+		if names := doc.TargetNames(); len(names) > 0 {
+			name = names[0]
+		}
+	}
+
+	path := doc.TargetPath(name)
+	if doc.TargetNames() == nil && name != config.DefaultTargetName {
+		if doc.GetString("deployment", "provider") != "" {
+			doc.MoveToTargets(config.DefaultTargetName)
+		}
+		path = []string{"deployment", "targets", name}
+	}
+
+	doc.SetString(deploymentProvider, append(slices.Clone(path), "provider")...)
+	if deploymentRegion != "" {
+		doc.SetString(deploymentRegion, append(slices.Clone(path), deploymentProvider, "region")...)
+	}
+}
+
 func InitializeGit(projectPath string) error {
 	currentDir, err := os.Getwd()
 	if err != nil {
@@ -241,6 +411,18 @@ func GetGitUserInfo() (string, string) {
 	return name, email
 }
 
+// GetGitCommit returns the HEAD commit of the git repository containing
+// directory and its author, or empty strings when there is none.
+func GetGitCommit(directory string) (string, string) {
+	logCmd := exec.Command("git", "-C", directory, "log", "-1", "--format=%H%n%an")
+	output, err := logCmd.Output()
+	if err != nil {
+		return "", ""
+	}
+	commit, author, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return commit, author
+}
+
 func GetCurrentPythonVersion() string {
 	pythonCmd := exec.Command("python3", "--version")
 	output, err := pythonCmd.Output()
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+type awsProvider struct{}
+
+func (p *awsProvider) Name() string {
+	return "aws"
+}
+
+func (p *awsProvider) Service() string {
+	return "AWS Lambda"
+}
+
+// SupportsFramework limits Lambda to morph apps; streamlit and nextjs need
+// long-lived connections that Lambda does not serve.
+func (p *awsProvider) SupportsFramework(framework string) bool {
+	return framework == "morph"
+}
+
+func (p *awsProvider) Defaults() []Setting {
+	return []Setting{
+		{Key: "region", Value: "ap-northeast-1"},
+		{Key: "memory", Value: 1024},
+		{Key: "timeout", Value: 30},
+		{Key: "provisioned_concurrency", Value: 0},
+		{Key: "ephemeral_storage", Value: "512MB"},
+	}
+}
+
+// lambdaMBPerVCPU is the memory size at which Lambda allocates one full vCPU.
+const lambdaMBPerVCPU = 1769
+
+func (p *awsProvider) Resources(deployment *config.Deployment) Resources {
+	r := Resources{
+		Region:             defaultString(p, "region"),
+		MemoryMB:           defaultInt(p, "memory"),
+		Concurrency:        1,
+		TimeoutSeconds:     defaultInt(p, "timeout"),
+		MinInstances:       defaultInt(p, "provisioned_concurrency"),
+		EphemeralStorageMB: defaultSize(p, "ephemeral_storage"),
+	}
+	if aws := deployment.AWS; aws != nil {
+		if aws.Region != "" {
+			r.Region = aws.Region
+		}
+		if aws.Memory != 0 {
+			r.MemoryMB = aws.Memory
+		}
+		if aws.Timeout != 0 {
+			r.TimeoutSeconds = aws.Timeout
+		}
+		r.MinInstances = aws.ProvisionedConcurrency
+		if size, err := resources.ParseSize(aws.EphemeralStorage); err == nil {
+			r.EphemeralStorageMB = size
+		}
+	}
+	r.CPU = float64(r.MemoryMB) / lambdaMBPerVCPU
+	return r
+}
+
+func (p *awsProvider) Validate(prefix string, deployment *config.Deployment) []resources.Problem {
+	aws := deployment.AWS
+	if aws == nil {
+		return nil
+	}
+
+	catalog, err := resources.ForProvider(p.Name())
+	if err != nil {
+		return []resources.Problem{{Path: prefix, Message: err.Error()}}
+	}
+
+	problems := []resources.Problem{}
+	problems = append(problems, catalog.CheckRegion(prefix, aws.Region)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "memory", float64(aws.Memory), aws.Memory != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "timeout", float64(aws.Timeout), aws.Timeout != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "provisioned_concurrency", float64(aws.ProvisionedConcurrency), true)...)
+	problems = append(problems, catalog.CheckSize(prefix, "ephemeral_storage", aws.EphemeralStorage)...)
+	return problems
+}
+
+// ValidateTraffic allows at most two versions in the split, since a Lambda
+// alias routes to one version plus one additional version.
+func (p *awsProvider) ValidateTraffic(traffic *config.Traffic) []resources.Problem {
+	problems := []resources.Problem{}
+	if len(traffic.Split) > 2 {
+		problems = append(problems, resources.Problem{
+			Path:    "traffic.split",
+			Message: fmt.Sprintf("AWS Lambda aliases route to at most 2 versions, got %d", len(traffic.Split)),
+		})
+	}
+	for i, split := range traffic.Split {
+		if split.Revision != "" && !isLambdaVersion(split.Revision) {
+			problems = append(problems, resources.Problem{
+				Path:    fmt.Sprintf("traffic.split[%d].revision", i),
+				Message: fmt.Sprintf("%q must be latest, previous or a published Lambda version number", split.Revision),
+			})
+		}
+	}
+	if traffic.Tag == LambdaLiveAlias {
+		problems = append(problems, resources.Problem{
+			Path:    "traffic.tag",
+			Message: fmt.Sprintf("%q is the alias that serves production traffic on AWS Lambda", traffic.Tag),
+		})
+	}
+	return problems
+}
+
+func (p *awsProvider) PromptRegion(current string) (string, error) {
+	return promptRegion(p, current)
+}
@@ -0,0 +1,108 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+type azureProvider struct{}
+
+func (p *azureProvider) Name() string {
+	return "azure"
+}
+
+func (p *azureProvider) Service() string {
+	return "Azure Container Apps"
+}
+
+func (p *azureProvider) SupportsFramework(framework string) bool {
+	return true
+}
+
+func (p *azureProvider) Defaults() []Setting {
+	return []Setting{
+		{Key: "region", Value: "eastus"},
+		{Key: "cpu", Value: 0.5},
+		{Key: "memory", Value: 1024},
+		{Key: "min_replicas", Value: 0},
+		{Key: "max_replicas", Value: 10},
+		{Key: "concurrency", Value: 10},
+	}
+}
+
+func (p *azureProvider) Resources(deployment *config.Deployment) Resources {
+	r := Resources{
+		Region:       defaultString(p, "region"),
+		MemoryMB:     defaultInt(p, "memory"),
+		CPU:          defaultFloat(p, "cpu"),
+		Concurrency:  defaultInt(p, "concurrency"),
+		MinInstances: defaultInt(p, "min_replicas"),
+		MaxInstances: defaultInt(p, "max_replicas"),
+	}
+	if azure := deployment.Azure; azure != nil {
+		if azure.Region != "" {
+			r.Region = azure.Region
+		}
+		if azure.Memory != 0 {
+			r.MemoryMB = azure.Memory
+		}
+		if azure.CPU != 0 {
+			r.CPU = azure.CPU
+		}
+		if azure.Concurrency != 0 {
+			r.Concurrency = azure.Concurrency
+		}
+		r.MinInstances = azure.MinReplicas
+		if azure.MaxReplicas != 0 {
+			r.MaxInstances = azure.MaxReplicas
+		}
+	}
+	return r
+}
+
+func (p *azureProvider) Validate(prefix string, deployment *config.Deployment) []resources.Problem {
+	azure := deployment.Azure
+	if azure == nil {
+		return nil
+	}
+
+	catalog, err := resources.ForProvider(p.Name())
+	if err != nil {
+		return []resources.Problem{{Path: prefix, Message: err.Error()}}
+	}
+
+	problems := []resources.Problem{}
+	problems = append(problems, catalog.CheckRegion(prefix, azure.Region)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "cpu", azure.CPU, azure.CPU != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "memory", float64(azure.Memory), azure.Memory != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "min_replicas", float64(azure.MinReplicas), true)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "max_replicas", float64(azure.MaxReplicas), azure.MaxReplicas != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "concurrency", float64(azure.Concurrency), azure.Concurrency != 0)...)
+	// The pairing applies to the values the platform uses, so unset
+	// settings are checked with their defaults.
+	r := p.Resources(deployment)
+	problems = append(problems, catalog.CheckCPUMemory(prefix, r.CPU, r.MemoryMB, r.Concurrency)...)
+
+	if azure.MaxReplicas != 0 && azure.MinReplicas > azure.MaxReplicas {
+		problems = append(problems, resources.Problem{
+			Path:    prefix + ".min_replicas",
+			Message: fmt.Sprintf("%d is greater than max_replicas (%d)", azure.MinReplicas, azure.MaxReplicas),
+		})
+	}
+	return problems
+}
+
+// ValidateTraffic rejects the traffic block: rollouts on Azure Container
+// Apps are not supported yet.
+func (p *azureProvider) ValidateTraffic(traffic *config.Traffic) []resources.Problem {
+	return []resources.Problem{{
+		Path:    "traffic",
+		Message: "traffic splitting is not supported for azure yet; remove the traffic block or deploy with aws or gcp",
+	}}
+}
+
+func (p *azureProvider) PromptRegion(current string) (string, error) {
+	return promptRegion(p, current)
+}
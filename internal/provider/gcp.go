@@ -0,0 +1,114 @@
+package provider
+
+import (
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+type gcpProvider struct{}
+
+func (p *gcpProvider) Name() string {
+	return "gcp"
+}
+
+func (p *gcpProvider) Service() string {
+	return "Cloud Run"
+}
+
+func (p *gcpProvider) SupportsFramework(framework string) bool {
+	return true
+}
+
+func (p *gcpProvider) Defaults() []Setting {
+	return []Setting{
+		{Key: "region", Value: "us-central1"},
+		{Key: "memory", Value: 1024},
+		{Key: "cpu", Value: 1},
+		{Key: "concurrency", Value: 80},
+		{Key: "timeout", Value: 60},
+		{Key: "min_instances", Value: 0},
+		{Key: "ephemeral_storage", Value: "100Mi"},
+	}
+}
+
+func (p *gcpProvider) Resources(deployment *config.Deployment) Resources {
+	r := Resources{
+		Region:             defaultString(p, "region"),
+		MemoryMB:           defaultInt(p, "memory"),
+		CPU:                defaultFloat(p, "cpu"),
+		Concurrency:        defaultInt(p, "concurrency"),
+		TimeoutSeconds:     defaultInt(p, "timeout"),
+		MinInstances:       defaultInt(p, "min_instances"),
+		EphemeralStorageMB: defaultSize(p, "ephemeral_storage"),
+	}
+	if gcp := deployment.GCP; gcp != nil {
+		if gcp.Region != "" {
+			r.Region = gcp.Region
+		}
+		if gcp.Memory != 0 {
+			r.MemoryMB = gcp.Memory
+		}
+		if gcp.CPU != 0 {
+			r.CPU = gcp.CPU
+		}
+		if gcp.Concurrency != 0 {
+			r.Concurrency = gcp.Concurrency
+		}
+		if gcp.Timeout != 0 {
+			r.TimeoutSeconds = gcp.Timeout
+		}
+		r.MinInstances = gcp.MinInstances
+		if size, err := resources.ParseSize(gcp.EphemeralStorage); err == nil {
+			r.EphemeralStorageMB = size
+		}
+	}
+	return r
+}
+
+func (p *gcpProvider) Validate(prefix string, deployment *config.Deployment) []resources.Problem {
+	gcp := deployment.GCP
+	if gcp == nil {
+		return nil
+	}
+
+	catalog, err := resources.ForProvider(p.Name())
+	if err != nil {
+		return []resources.Problem{{Path: prefix, Message: err.Error()}}
+	}
+
+	problems := []resources.Problem{}
+	problems = append(problems, catalog.CheckRegion(prefix, gcp.Region)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "memory", float64(gcp.Memory), gcp.Memory != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "cpu", gcp.CPU, gcp.CPU != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "concurrency", float64(gcp.Concurrency), gcp.Concurrency != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "timeout", float64(gcp.Timeout), gcp.Timeout != 0)...)
+	problems = append(problems, catalog.CheckLimit(prefix, "min_instances", float64(gcp.MinInstances), true)...)
+	problems = append(problems, catalog.CheckSize(prefix, "ephemeral_storage", gcp.EphemeralStorage)...)
+	// The pairing applies to the values the platform uses, so unset
+	// settings are checked with their defaults.
+	r := p.Resources(deployment)
+	problems = append(problems, catalog.CheckCPUMemory(prefix, r.CPU, r.MemoryMB, r.Concurrency)...)
+	return problems
+}
+
+func (p *gcpProvider) ValidateTraffic(traffic *config.Traffic) []resources.Problem {
+	problems := []resources.Problem{}
+	for i, split := range traffic.Split {
+		if split.Revision == "" || split.Revision == config.RevisionLatest || split.Revision == config.RevisionPrevious {
+			continue
+		}
+		if !trafficTagPattern.MatchString(split.Revision) {
+			problems = append(problems, resources.Problem{
+				Path:    fmt.Sprintf("traffic.split[%d].revision", i),
+				Message: fmt.Sprintf("%q must be latest, previous or a Cloud Run revision name", split.Revision),
+			})
+		}
+	}
+	return problems
+}
+
+func (p *gcpProvider) PromptRegion(current string) (string, error) {
+	return promptRegion(p, current)
+}
@@ -0,0 +1,266 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+// Setting is a key of a provider's settings block in squadbase.yml together
+// with its default value.
+type Setting struct {
+	Key   string
+	Value any
+}
+
+// Resources are the effective resource settings of a deployment, expressed
+// in the same units for every provider.
+type Resources struct {
+	Region             string  `json:"region"`
+	MemoryMB           int     `json:"memory_mb"`
+	CPU                float64 `json:"cpu"`
+	Concurrency        int     `json:"concurrency"`
+	TimeoutSeconds     int     `json:"timeout_seconds"`
+	MinInstances       int     `json:"min_instances"`
+	MaxInstances       int     `json:"max_instances"`
+	EphemeralStorageMB int     `json:"ephemeral_storage_mb"`
+}
+
+// Provider is a deployment target that can be selected with deployment.provider.
+type Provider interface {
+	// Name is the value written to deployment.provider and the key of the
+	// provider's settings block.
+	Name() string
+	// Service is the platform the app runs on, e.g. "AWS Lambda".
+	Service() string
+	// SupportsFramework reports whether apps built with the framework can be
+	// deployed with this provider.
+	SupportsFramework(framework string) bool
+	// Defaults returns the settings the platform applies when squadbase.yml
+	// does not set them, in the order they are written to squadbase.yml.
+	Defaults() []Setting
+	// Resources returns the effective resource settings of the deployment,
+	// with the provider's defaults filled in for everything it does not set.
+	Resources(deployment *config.Deployment) Resources
+	// Validate checks the provider's settings block of the deployment section.
+	Validate(prefix string, deployment *config.Deployment) []resources.Problem
+	// ValidateTraffic checks the traffic block against what the platform can
+	// route. Rules shared by every provider are checked by Validate.
+	ValidateTraffic(traffic *config.Traffic) []resources.Problem
+	// PromptRegion asks for the region to deploy to, preselecting current
+	// when it is a known region.
+	PromptRegion(current string) (string, error)
+}
+
+// registry lists the providers in the order they are offered. The first
+// provider that supports a framework is its default.
+var registry = []Provider{
+	&awsProvider{},
+	&gcpProvider{},
+	&azureProvider{},
+}
+
+func All() []Provider {
+	return slices.Clone(registry)
+}
+
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for _, p := range registry {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+func Get(name string) (Provider, error) {
+	for _, p := range registry {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown deployment provider %q (supported: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Options returns the names of the providers that can deploy the framework.
+func Options(framework string) []string {
+	options := []string{}
+	for _, p := range registry {
+		if p.SupportsFramework(framework) {
+			options = append(options, p.Name())
+		}
+	}
+	return options
+}
+
+// Default returns the provider offered first for the framework.
+func Default(framework string) string {
+	options := Options(framework)
+	if len(options) == 0 {
+		return ""
+	}
+	return options[0]
+}
+
+// Select asks which provider to deploy the framework with, preselecting
+// current when the framework supports it.
+func Select(framework string, current string) (Provider, error) {
+	options := Options(framework)
+	if len(options) == 0 {
+		return nil, fmt.Errorf("no deployment provider supports %s", framework)
+	}
+
+	defaultOption := Default(framework)
+	if slices.Contains(options, current) {
+		defaultOption = current
+	}
+
+	descriptions := map[string]string{}
+	for _, p := range registry {
+		descriptions[p.Name()] = p.Service()
+	}
+
+	var name string
+	prompt := &survey.Select{
+		Message: "Select deployment provider:",
+		Options: options,
+		Default: defaultOption,
+		Description: func(value string, index int) string {
+			return descriptions[value]
+		},
+	}
+	if err := survey.AskOne(prompt, &name); err != nil {
+		return nil, err
+	}
+	return Get(name)
+}
+
+// Validate checks every deployment target of cfg with its provider and
+// every provider settings block present in the target, and the traffic
+// block with the provider of every target. It returns a
+// *resources.ValidationError listing every problem.
+func Validate(cfg *config.Config) error {
+	if problems := Problems(cfg); len(problems) > 0 {
+		return &resources.ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Problems returns the problems Validate reports.
+func Problems(cfg *config.Config) []resources.Problem {
+	problems := []resources.Problem{}
+
+	deployment := cfg.Deployment
+	if len(deployment.Targets) > 0 && (deployment.Provider != "" || deployment.AWS != nil || deployment.GCP != nil || deployment.Azure != nil) {
+		problems = append(problems, resources.Problem{
+			Path:    "deployment.targets",
+			Message: "cannot be combined with deployment.provider or provider settings; move them into a target",
+		})
+	}
+	if deployment.DefaultTarget != "" {
+		if _, err := cfg.Target(deployment.DefaultTarget); err != nil {
+			problems = append(problems, resources.Problem{Path: "deployment.default_target", Message: err.Error()})
+		}
+	}
+
+	for _, target := range cfg.Targets() {
+		prefix := target.Prefix()
+		if !config.ValidTargetName(target.Name) {
+			problems = append(problems, resources.Problem{
+				Path:    prefix,
+				Message: "target names may only contain lowercase letters, digits, '-' and '_'",
+			})
+		}
+
+		if target.Provider == "" {
+			problems = append(problems, resources.Problem{Path: prefix + ".provider", Message: "is required"})
+		} else if _, err := Get(target.Provider); err != nil {
+			problems = append(problems, resources.Problem{Path: prefix + ".provider", Message: err.Error()})
+		}
+
+		for _, p := range registry {
+			problems = append(problems, p.Validate(prefix+"."+p.Name(), &target.Deployment)...)
+		}
+	}
+
+	problems = append(problems, validateTraffic(cfg.Traffic)...)
+	if cfg.Traffic != nil {
+		checked := map[string]bool{}
+		for _, target := range cfg.Targets() {
+			p, err := Get(target.Provider)
+			if err != nil || checked[p.Name()] {
+				continue
+			}
+			checked[p.Name()] = true
+			problems = append(problems, p.ValidateTraffic(cfg.Traffic)...)
+		}
+	}
+	return problems
+}
+
+// DefaultValue returns the default of the provider setting with the given key.
+func DefaultValue(p Provider, key string) any {
+	for _, s := range p.Defaults() {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+	return nil
+}
+
+func defaultInt(p Provider, key string) int {
+	switch value := DefaultValue(p, key).(type) {
+	case int:
+		return value
+	case float64:
+		return int(value)
+	}
+	return 0
+}
+
+func defaultFloat(p Provider, key string) float64 {
+	switch value := DefaultValue(p, key).(type) {
+	case int:
+		return float64(value)
+	case float64:
+		return value
+	}
+	return 0
+}
+
+func defaultString(p Provider, key string) string {
+	value, _ := DefaultValue(p, key).(string)
+	return value
+}
+
+func defaultSize(p Provider, key string) int {
+	size, _ := resources.ParseSize(defaultString(p, key))
+	return size
+}
+
+func promptRegion(p Provider, current string) (string, error) {
+	catalog, err := resources.ForProvider(p.Name())
+	if err != nil {
+		return "", err
+	}
+
+	defaultRegion := defaultString(p, "region")
+	if catalog.HasRegion(current) {
+		defaultRegion = current
+	}
+
+	var region string
+	prompt := &survey.Select{
+		Message:  fmt.Sprintf("Select %s region:", p.Service()),
+		Options:  catalog.Regions,
+		Default:  defaultRegion,
+		PageSize: 10,
+	}
+	if err := survey.AskOne(prompt, &region); err != nil {
+		return "", err
+	}
+	return region, nil
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+// LambdaLiveAlias is the alias that serves production traffic on Lambda.
+const LambdaLiveAlias = "live"
+
+var trafficTagPattern = regexp.MustCompile(`^[a-z]([a-z0-9-]*[a-z0-9])?$`)
+
+// validateTraffic checks the rules of the traffic block shared by every
+// provider.
+func validateTraffic(traffic *config.Traffic) []resources.Problem {
+	if traffic == nil {
+		return nil
+	}
+
+	problems := []resources.Problem{}
+	total := 0
+	seen := map[string]bool{}
+	for i, split := range traffic.Split {
+		prefix := fmt.Sprintf("traffic.split[%d]", i)
+		if split.Revision == "" {
+			problems = append(problems, resources.Problem{Path: prefix + ".revision", Message: "is required"})
+		} else if seen[split.Revision] {
+			problems = append(problems, resources.Problem{Path: prefix + ".revision", Message: fmt.Sprintf("%q is listed more than once", split.Revision)})
+		}
+		seen[split.Revision] = true
+
+		if split.Percent < 0 || split.Percent > 100 {
+			problems = append(problems, resources.Problem{Path: prefix + ".percent", Message: fmt.Sprintf("%d is outside 0-100", split.Percent)})
+		}
+		total += split.Percent
+	}
+	if len(traffic.Split) > 0 && total != 100 {
+		problems = append(problems, resources.Problem{Path: "traffic.split", Message: fmt.Sprintf("percentages add up to %d, expected 100", total)})
+	}
+
+	if traffic.Tag != "" && !trafficTagPattern.MatchString(traffic.Tag) {
+		problems = append(problems, resources.Problem{
+			Path:    "traffic.tag",
+			Message: fmt.Sprintf("%q must start with a letter and contain only lowercase letters, digits and '-'", traffic.Tag),
+		})
+	}
+	if traffic.RollbackThreshold < 0 || traffic.RollbackThreshold > 100 {
+		problems = append(problems, resources.Problem{
+			Path:    "traffic.rollback_threshold",
+			Message: fmt.Sprintf("%g is outside 0-100", traffic.RollbackThreshold),
+		})
+	}
+	return problems
+}
+
+// LambdaAlias is a Lambda alias and the weights it routes to each function
+// version. Versions are config.RevisionLatest, config.RevisionPrevious or a
+// published version number.
+type LambdaAlias struct {
+	Name string
+	// FunctionVersion receives the traffic not routed to additional versions.
+	FunctionVersion string
+	// AdditionalVersionWeights maps a version to its share of traffic (0-1).
+	AdditionalVersionWeights map[string]float64
+}
+
+// LambdaAliases maps the traffic block to Lambda aliases: the live alias
+// splits traffic between at most two versions, and the tag becomes an alias
+// that always points at the latest version.
+func LambdaAliases(traffic *config.Traffic) []LambdaAlias {
+	splits := traffic.Splits()
+
+	primary := splits[0]
+	for _, split := range splits[1:] {
+		if split.Percent > primary.Percent {
+			primary = split
+		}
+	}
+
+	live := LambdaAlias{Name: LambdaLiveAlias, FunctionVersion: primary.Revision}
+	for _, split := range splits {
+		if split.Revision == primary.Revision || split.Percent == 0 {
+			continue
+		}
+		if live.AdditionalVersionWeights == nil {
+			live.AdditionalVersionWeights = map[string]float64{}
+		}
+		live.AdditionalVersionWeights[split.Revision] = float64(split.Percent) / 100
+	}
+
+	aliases := []LambdaAlias{live}
+	if traffic != nil && traffic.Tag != "" {
+		aliases = append(aliases, LambdaAlias{Name: traffic.Tag, FunctionVersion: config.RevisionLatest})
+	}
+	return aliases
+}
+
+// CloudRunTraffic is an entry of the traffic list of a Cloud Run service.
+type CloudRunTraffic struct {
+	// LatestRevision routes to the newest ready revision instead of a named one.
+	LatestRevision bool
+	// RevisionName is a revision name or config.RevisionPrevious.
+	RevisionName string
+	Percent      int
+	Tag          string
+}
+
+// CloudRunTrafficTargets maps the traffic block to Cloud Run traffic
+// entries. The tag is attached to the latest revision, adding a 0% entry for
+// it when the split does not route to it.
+func CloudRunTrafficTargets(traffic *config.Traffic) []CloudRunTraffic {
+	targets := []CloudRunTraffic{}
+	tagged := false
+	for _, split := range traffic.Splits() {
+		target := CloudRunTraffic{Percent: split.Percent}
+		if split.Revision == config.RevisionLatest {
+			target.LatestRevision = true
+			if traffic != nil && traffic.Tag != "" {
+				target.Tag = traffic.Tag
+				tagged = true
+			}
+		} else {
+			target.RevisionName = split.Revision
+		}
+		targets = append(targets, target)
+	}
+	if traffic != nil && traffic.Tag != "" && !tagged {
+		targets = append(targets, CloudRunTraffic{LatestRevision: true, Tag: traffic.Tag})
+	}
+	return targets
+}
+
+func isLambdaVersion(revision string) bool {
+	if revision == config.RevisionLatest || revision == config.RevisionPrevious {
+		return true
+	}
+	version, err := strconv.Atoi(revision)
+	return err == nil && version > 0
+}
@@ -0,0 +1,196 @@
+package resources
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+)
+
+// Problem is a single rule violation in squadbase.yml.
+type Problem struct {
+	Path    string
+	Message string
+}
+
+// ValidationError lists every rule violation found in squadbase.yml.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{fmt.Sprintf("%s has %d invalid setting(s):", config.FileName, len(e.Problems))}
+	for _, p := range e.Problems {
+		lines = append(lines, fmt.Sprintf("  %s: %s", p.Path, p.Message))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ExpandPresets fills in the values of the preset named in each provider
+// block of every deployment target. Values set explicitly in the block win
+// over the preset.
+func ExpandPresets(doc *config.Document) error {
+	for _, target := range doc.TargetPaths() {
+		for _, provider := range Providers() {
+			if err := expandPreset(doc, append(slices.Clone(target), provider)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func expandPreset(doc *config.Document, block []string) error {
+	name := doc.GetString(append(slices.Clone(block), "preset")...)
+	if name == "" {
+		return nil
+	}
+
+	catalog, err := ForProvider(block[len(block)-1])
+	if err != nil {
+		return err
+	}
+	preset, err := catalog.Preset(name)
+	if err != nil {
+		return fmt.Errorf("%s.preset: %w", strings.Join(block, "."), err)
+	}
+
+	keys := make([]string, 0, len(preset.Values))
+	for key := range preset.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := append(slices.Clone(block), key)
+		if doc.Lookup(path...) != nil {
+			continue
+		}
+		value := preset.Values[key]
+		if number, ok := value.(float64); ok && number == math.Trunc(number) {
+			value = int(number)
+		}
+		if err := doc.SetValue(value, path...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckRegion reports an unknown region.
+func (c *Catalog) CheckRegion(prefix string, region string) []Problem {
+	if region == "" || c.HasRegion(region) {
+		return nil
+	}
+	return []Problem{{
+		Path:    prefix + ".region",
+		Message: fmt.Sprintf("%q is not a known %s region", region, c.Service),
+	}}
+}
+
+// CheckLimit reports a value outside the catalog's range for key. Values
+// that are not set are not checked.
+func (c *Catalog) CheckLimit(prefix string, key string, value float64, set bool) []Problem {
+	limit, ok := c.Limits[key]
+	if !set || !ok || limit.Contains(value) {
+		return nil
+	}
+	return []Problem{{
+		Path: prefix + "." + key,
+		Message: fmt.Sprintf("%s is outside the %s range of %s-%s %s",
+			formatNumber(value), c.Service, formatNumber(limit.Min), formatNumber(limit.Max), limit.Unit),
+	}}
+}
+
+// CheckSize reports a storage size that cannot be parsed or is outside the
+// catalog's range for key.
+func (c *Catalog) CheckSize(prefix string, key string, size string) []Problem {
+	if size == "" {
+		return nil
+	}
+	value, err := ParseSize(size)
+	if err != nil {
+		return []Problem{{Path: prefix + "." + key, Message: err.Error()}}
+	}
+	limit, ok := c.Limits[key]
+	if !ok || limit.Contains(float64(value)) {
+		return nil
+	}
+	return []Problem{{
+		Path: prefix + "." + key,
+		Message: fmt.Sprintf("%s is outside the %s range of %s-%s %s",
+			size, c.Service, formatNumber(limit.Min), formatNumber(limit.Max), limit.Unit),
+	}}
+}
+
+// CheckCPUMemory reports CPU values the platform does not offer and memory
+// sizes that cannot be combined with the configured CPU. The values are the
+// ones the platform uses, defaults included.
+func (c *Catalog) CheckCPUMemory(prefix string, cpu float64, memory int, concurrency int) []Problem {
+	if cpu == 0 || len(c.CPUMemory) == 0 {
+		return nil
+	}
+
+	problems := []Problem{}
+	if cpu < 1 && c.FractionalCPU != nil {
+		if memory > c.FractionalCPU.MaxMemory {
+			problems = append(problems, Problem{
+				Path: prefix + ".memory",
+				Message: fmt.Sprintf("%d MiB needs at least 1 vCPU; with %s vCPU the maximum is %d MiB",
+					memory, formatNumber(cpu), c.FractionalCPU.MaxMemory),
+			})
+		}
+		if concurrency > c.FractionalCPU.MaxConcurrency {
+			problems = append(problems, Problem{
+				Path:    prefix + ".concurrency",
+				Message: fmt.Sprintf("must be at most %d when cpu is below 1 (got %d)", c.FractionalCPU.MaxConcurrency, concurrency),
+			})
+		}
+		return problems
+	}
+
+	pairing := c.CPUPairing(cpu)
+	if pairing == nil {
+		allowed := []string{}
+		for _, p := range c.CPUMemory {
+			allowed = append(allowed, formatNumber(p.CPU))
+		}
+		message := fmt.Sprintf("%s is not a valid %s CPU value; use one of %s", formatNumber(cpu), c.Service, strings.Join(allowed, ", "))
+		if c.FractionalCPU != nil {
+			message = fmt.Sprintf("%s is not a valid %s CPU value; use a fraction below 1 or one of %s", formatNumber(cpu), c.Service, strings.Join(allowed, ", "))
+		}
+		return append(problems, Problem{Path: prefix + ".cpu", Message: message})
+	}
+
+	if memory != 0 && (memory < pairing.MinMemory || memory > pairing.MaxMemory) {
+		allowed := fmt.Sprintf("%d-%d MiB", pairing.MinMemory, pairing.MaxMemory)
+		if pairing.MinMemory == pairing.MaxMemory {
+			allowed = fmt.Sprintf("%d MiB", pairing.MinMemory)
+		}
+		problems = append(problems, Problem{
+			Path: prefix + ".memory",
+			Message: fmt.Sprintf("%d MiB is not allowed with %s vCPU on %s (allowed: %s)",
+				memory, formatNumber(cpu), c.Service, allowed),
+		})
+	}
+	return problems
+}
+
+// CPUPairing returns the memory range allowed with the given whole CPU value,
+// or nil when the platform does not offer it.
+func (c *Catalog) CPUPairing(cpu float64) *CPUMemory {
+	for i := range c.CPUMemory {
+		if c.CPUMemory[i].CPU == cpu {
+			return &c.CPUMemory[i]
+		}
+	}
+	return nil
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
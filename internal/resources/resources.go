@@ -0,0 +1,152 @@
+package resources
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed data/*.json
+var dataFS embed.FS
+
+type Range struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Unit string  `json:"unit"`
+}
+
+// Contains reports whether value lies within the range, bounds included.
+func (r Range) Contains(value float64) bool {
+	return value >= r.Min && value <= r.Max
+}
+
+type CPUMemory struct {
+	CPU       float64 `json:"cpu"`
+	MinMemory int     `json:"min_memory"`
+	MaxMemory int     `json:"max_memory"`
+}
+
+type FractionalCPU struct {
+	MaxMemory      int `json:"max_memory"`
+	MaxConcurrency int `json:"max_concurrency"`
+}
+
+type Preset struct {
+	Name   string         `json:"name"`
+	Values map[string]any `json:"values"`
+}
+
+// Catalog holds the resource rules and presets for one deployment provider.
+type Catalog struct {
+	Provider      string           `json:"provider"`
+	Service       string           `json:"service"`
+	Regions       []string         `json:"regions"`
+	Limits        map[string]Range `json:"limits"`
+	CPUMemory     []CPUMemory      `json:"cpu_memory,omitempty"`
+	FractionalCPU *FractionalCPU   `json:"fractional_cpu,omitempty"`
+	Presets       []Preset         `json:"presets"`
+}
+
+var (
+	catalogs     map[string]*Catalog
+	catalogsErr  error
+	catalogsOnce sync.Once
+)
+
+func loadCatalogs() {
+	catalogs = map[string]*Catalog{}
+	entries, err := dataFS.ReadDir("data")
+	if err != nil {
+		catalogsErr = fmt.Errorf("failed to read resource catalogs: %w", err)
+		return
+	}
+	for _, entry := range entries {
+		data, err := dataFS.ReadFile(path.Join("data", entry.Name()))
+		if err != nil {
+			catalogsErr = fmt.Errorf("failed to read resource catalog %s: %w", entry.Name(), err)
+			return
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			catalogsErr = fmt.Errorf("failed to parse resource catalog %s: %w", entry.Name(), err)
+			return
+		}
+		catalogs[catalog.Provider] = &catalog
+	}
+}
+
+// Providers returns the names of all providers with a resource catalog.
+func Providers() []string {
+	catalogsOnce.Do(loadCatalogs)
+	names := make([]string, 0, len(catalogs))
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForProvider returns the resource catalog for the given provider.
+func ForProvider(provider string) (*Catalog, error) {
+	catalogsOnce.Do(loadCatalogs)
+	if catalogsErr != nil {
+		return nil, catalogsErr
+	}
+	catalog, ok := catalogs[provider]
+	if !ok {
+		return nil, fmt.Errorf("no resource catalog for provider %q", provider)
+	}
+	return catalog, nil
+}
+
+// Preset returns the named preset of the catalog.
+func (c *Catalog) Preset(name string) (*Preset, error) {
+	for i := range c.Presets {
+		if c.Presets[i].Name == name {
+			return &c.Presets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown %s preset %q (available: %s)", c.Provider, name, strings.Join(c.PresetNames(), ", "))
+}
+
+// PresetNames returns the preset names in catalog order, smallest first.
+func (c *Catalog) PresetNames() []string {
+	names := make([]string, 0, len(c.Presets))
+	for _, preset := range c.Presets {
+		names = append(names, preset.Name)
+	}
+	return names
+}
+
+// HasRegion reports whether region is a known region of the provider.
+func (c *Catalog) HasRegion(region string) bool {
+	return slices.Contains(c.Regions, region)
+}
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(MB|Mi|GB|Gi|M|G)?$`)
+
+// ParseSize converts a storage size such as "512MB", "100Mi" or "2Gi" to
+// mebibytes. MB and Mi are treated alike, as both providers do in practice;
+// a bare number is taken to be in MB.
+func ParseSize(size string) (int, error) {
+	matches := sizePattern.FindStringSubmatch(strings.TrimSpace(size))
+	if matches == nil {
+		return 0, fmt.Errorf("invalid size %q: use a number followed by MB, Mi, GB or Gi", size)
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", size, err)
+	}
+	switch matches[2] {
+	case "GB", "Gi", "G":
+		value *= 1024
+	}
+	return int(value), nil
+}
@@ -0,0 +1,353 @@
+package secrets
+
+import (
+	"bufio"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Key prefixes. Recipients are public and can be shared; identities are
+// the matching private keys.
+const (
+	RecipientPrefix = "squad1"
+	IdentityPrefix  = "SQUAD-SECRET-KEY-1"
+)
+
+// Stanza types of the recipients of a secrets file.
+const (
+	StanzaX25519 = "x25519"
+	StanzaScrypt = "scrypt"
+)
+
+// DefaultWorkFactor is the scrypt cost, as log2(N), for passphrases.
+const DefaultWorkFactor = 16
+
+// maxWorkFactor bounds the cost a file may ask for, so that a crafted file
+// cannot make unlocking it take forever.
+const maxWorkFactor = 22
+
+// fileKeySize is the size of the random key the values are encrypted with.
+const fileKeySize = 32
+
+// ErrIncorrectIdentity is returned by Identity.Unwrap for stanzas that were
+// not made for the identity.
+var ErrIncorrectIdentity = errors.New("incorrect identity for recipient stanza")
+
+var keyEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Stanza is the file key wrapped for one recipient.
+type Stanza struct {
+	Type string `yaml:"type"`
+	// Recipient and Ephemeral are the public keys of an x25519 stanza.
+	Recipient string `yaml:"recipient,omitempty"`
+	Ephemeral string `yaml:"ephemeral,omitempty"`
+	// Salt and WorkFactor are the scrypt parameters of a passphrase stanza.
+	Salt       string `yaml:"salt,omitempty"`
+	WorkFactor int    `yaml:"work_factor,omitempty"`
+	// Key is the wrapped file key.
+	Key string `yaml:"key"`
+}
+
+// Recipient wraps the file key so that the matching identity can unwrap it.
+type Recipient interface {
+	Wrap(fileKey []byte) (*Stanza, error)
+}
+
+// Identity unwraps the file key from a stanza.
+type Identity interface {
+	Unwrap(stanza *Stanza) ([]byte, error)
+}
+
+// X25519Recipient is the public half of an X25519Identity.
+type X25519Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// X25519Identity is a private key, stored in an identity file.
+type X25519Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// GenerateX25519Identity returns a new random identity.
+func GenerateX25519Identity() (*X25519Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+// ParseX25519Identity reads an identity written by X25519Identity.String.
+func ParseX25519Identity(s string) (*X25519Identity, error) {
+	data, err := decodeKey(s, IdentityPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid identity: %w", err)
+	}
+	return &X25519Identity{key: key}, nil
+}
+
+func (i *X25519Identity) String() string {
+	return IdentityPrefix + keyEncoding.EncodeToString(i.key.Bytes())
+}
+
+// Recipient returns the public key to encrypt to this identity.
+func (i *X25519Identity) Recipient() *X25519Recipient {
+	return &X25519Recipient{key: i.key.PublicKey()}
+}
+
+// ParseX25519Recipient reads a recipient written by X25519Recipient.String.
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	data, err := decodeKey(s, RecipientPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", s, err)
+	}
+	return &X25519Recipient{key: key}, nil
+}
+
+func (r *X25519Recipient) String() string {
+	return RecipientPrefix + strings.ToLower(keyEncoding.EncodeToString(r.key.Bytes()))
+}
+
+func decodeKey(s string, prefix string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(strings.ToUpper(s), strings.ToUpper(prefix)) {
+		return nil, fmt.Errorf("expected it to start with %s", prefix)
+	}
+	data, err := keyEncoding.DecodeString(strings.ToUpper(s[len(prefix):]))
+	if err != nil || len(data) != 32 {
+		return nil, fmt.Errorf("malformed key")
+	}
+	return data, nil
+}
+
+// x25519WrapKey derives the key that wraps the file key from the shared
+// secret of the ephemeral and the recipient key.
+func x25519WrapKey(shared []byte, ephemeral []byte, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephemeral...), recipient...)
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte("squad-secrets/v1/x25519")), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *X25519Recipient) Wrap(fileKey []byte) (*Stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(r.key)
+	if err != nil {
+		return nil, err
+	}
+	wrapKey, err := x25519WrapKey(shared, ephemeral.PublicKey().Bytes(), r.key.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := wrap(wrapKey, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stanza{
+		Type:      StanzaX25519,
+		Recipient: r.String(),
+		Ephemeral: base64.RawStdEncoding.EncodeToString(ephemeral.PublicKey().Bytes()),
+		Key:       wrapped,
+	}, nil
+}
+
+func (i *X25519Identity) Unwrap(stanza *Stanza) ([]byte, error) {
+	if stanza.Type != StanzaX25519 || stanza.Recipient != i.Recipient().String() {
+		return nil, ErrIncorrectIdentity
+	}
+	ephemeralBytes, err := base64.RawStdEncoding.DecodeString(stanza.Ephemeral)
+	if err != nil {
+		return nil, fmt.Errorf("malformed x25519 stanza: %w", err)
+	}
+	ephemeral, err := ecdh.X25519().NewPublicKey(ephemeralBytes)
+	if err != nil {
+		return nil, fmt.Errorf("malformed x25519 stanza: %w", err)
+	}
+	shared, err := i.key.ECDH(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	wrapKey, err := x25519WrapKey(shared, ephemeralBytes, i.key.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return unwrap(wrapKey, stanza.Key)
+}
+
+// ScryptRecipient encrypts to a passphrase.
+type ScryptRecipient struct {
+	Passphrase string
+	// WorkFactor is log2 of the scrypt cost, DefaultWorkFactor when zero.
+	WorkFactor int
+}
+
+// ScryptIdentity decrypts with a passphrase.
+type ScryptIdentity struct {
+	Passphrase string
+}
+
+func scryptKey(passphrase string, salt []byte, workFactor int) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), append([]byte("squad-secrets/v1/scrypt"), salt...), 1<<workFactor, 8, 1, chacha20poly1305.KeySize)
+}
+
+func (r *ScryptRecipient) Wrap(fileKey []byte) (*Stanza, error) {
+	workFactor := r.WorkFactor
+	if workFactor == 0 {
+		workFactor = DefaultWorkFactor
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	wrapKey, err := scryptKey(r.Passphrase, salt, workFactor)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := wrap(wrapKey, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stanza{
+		Type:       StanzaScrypt,
+		Salt:       base64.RawStdEncoding.EncodeToString(salt),
+		WorkFactor: workFactor,
+		Key:        wrapped,
+	}, nil
+}
+
+func (i *ScryptIdentity) Unwrap(stanza *Stanza) ([]byte, error) {
+	if stanza.Type != StanzaScrypt {
+		return nil, ErrIncorrectIdentity
+	}
+	if stanza.WorkFactor <= 0 || stanza.WorkFactor > maxWorkFactor {
+		return nil, fmt.Errorf("scrypt work factor %d is out of range", stanza.WorkFactor)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(stanza.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("malformed scrypt stanza: %w", err)
+	}
+	wrapKey, err := scryptKey(i.Passphrase, salt, stanza.WorkFactor)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := unwrap(wrapKey, stanza.Key)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect passphrase")
+	}
+	return fileKey, nil
+}
+
+// wrap encrypts the file key. Every wrap key is used once, so a zero nonce
+// is safe.
+func wrap(wrapKey []byte, fileKey []byte) (string, error) {
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	return base64.RawStdEncoding.EncodeToString(aead.Seal(nil, nonce, fileKey, nil)), nil
+}
+
+func unwrap(wrapKey []byte, wrapped string) ([]byte, error) {
+	data, err := base64.RawStdEncoding.DecodeString(wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("malformed wrapped key: %w", err)
+	}
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	fileKey, err := aead.Open(nil, nonce, data, nil)
+	if err != nil || len(fileKey) != fileKeySize {
+		return nil, fmt.Errorf("failed to unwrap the file key")
+	}
+	return fileKey, nil
+}
+
+// DefaultIdentityPath returns the identity file in the user config
+// directory, for example ~/.config/squadbase/secrets-key.txt on Linux.
+func DefaultIdentityPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "squadbase", "secrets-key.txt"), nil
+}
+
+// ParseIdentities reads one identity per line. Blank lines and lines
+// starting with # are skipped.
+func ParseIdentities(r io.Reader) ([]*X25519Identity, error) {
+	identities := []*X25519Identity{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		identity, err := ParseX25519Identity(line)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, scanner.Err()
+}
+
+// ReadIdentityFile reads the identities of the file at path.
+func ReadIdentityFile(path string) ([]*X25519Identity, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read identity file: %w", err)
+	}
+	defer f.Close()
+	identities, err := ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// WriteIdentityFile writes a new identity file at path, readable only by
+// the current user. An existing file is never overwritten.
+func WriteIdentityFile(path string, identity *X25519Identity) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write identity file: %w", err)
+	}
+	content := fmt.Sprintf("# squad secrets identity\n# recipient: %s\n%s\n", identity.Recipient(), identity)
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write identity file: %w", err)
+	}
+	return f.Close()
+}
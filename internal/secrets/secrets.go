@@ -0,0 +1,312 @@
+package secrets
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the encrypted secrets file, kept next to squadbase.yml.
+const FileName = "secrets.enc.yml"
+
+// Version is the format version written to new files.
+const Version = 1
+
+const valuePrefix = "ENC[v1,"
+
+const fileHeader = `# Encrypted with squad secrets. Names are readable, values are encrypted one
+# by one. Edit with: squad secrets edit
+`
+
+// ErrNoIdentity is returned when none of the identities can unlock a file.
+var ErrNoIdentity = errors.New("no identity matches the recipients of the secrets file")
+
+// File is a secrets file as stored on disk.
+type File struct {
+	// Values maps every name to its encrypted value.
+	Values map[string]string `yaml:"values"`
+	Squad  Metadata          `yaml:"squad"`
+}
+
+// Metadata holds the wrapped file keys and the MAC of the values.
+type Metadata struct {
+	Version    int      `yaml:"version"`
+	Recipients []Stanza `yaml:"recipients"`
+	MAC        string   `yaml:"mac"`
+}
+
+// Path returns the secrets file of the project directory.
+func Path(directory string) string {
+	return filepath.Join(directory, FileName)
+}
+
+// Read parses the secrets file at path.
+func Read(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	if f.Squad.Version != Version {
+		return nil, fmt.Errorf("unsupported %s version %d", filepath.Base(path), f.Squad.Version)
+	}
+	if f.Values == nil {
+		f.Values = map[string]string{}
+	}
+	return &f, nil
+}
+
+// Write stores the file at path.
+func (f *File) Write(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append([]byte(fileHeader), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// HasPassphrase reports whether the file can be unlocked with a passphrase.
+func (f *File) HasPassphrase() bool {
+	for _, s := range f.Squad.Recipients {
+		if s.Type == StanzaScrypt {
+			return true
+		}
+	}
+	return false
+}
+
+// RecipientKeys returns the public keys the file is encrypted to.
+func (f *File) RecipientKeys() []string {
+	keys := []string{}
+	for _, s := range f.Squad.Recipients {
+		if s.Type == StanzaX25519 {
+			keys = append(keys, s.Recipient)
+		}
+	}
+	return keys
+}
+
+// Vault is an unlocked secrets file.
+type Vault struct {
+	file   *File
+	key    []byte
+	values map[string]string
+}
+
+// Create returns an empty vault encrypted to the recipients.
+func Create(recipients []Recipient) (*Vault, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	passphrases := 0
+	for _, r := range recipients {
+		if _, ok := r.(*ScryptRecipient); ok {
+			passphrases++
+		}
+	}
+	if passphrases > 1 {
+		return nil, fmt.Errorf("a secrets file can have only one passphrase")
+	}
+
+	key := make([]byte, fileKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	v := &Vault{
+		file:   &File{Values: map[string]string{}, Squad: Metadata{Version: Version}},
+		key:    key,
+		values: map[string]string{},
+	}
+	for _, r := range recipients {
+		if err := v.AddRecipient(r); err != nil {
+			return nil, err
+		}
+	}
+	v.seal()
+	return v, nil
+}
+
+// Open unlocks the file with the first identity that matches one of its
+// recipients and decrypts its values.
+func Open(f *File, identities []Identity) (*Vault, error) {
+	var key []byte
+	var lastErr error
+	for _, identity := range identities {
+		for i := range f.Squad.Recipients {
+			k, err := identity.Unwrap(&f.Squad.Recipients[i])
+			if errors.Is(err, ErrIncorrectIdentity) {
+				continue
+			}
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			key = k
+			break
+		}
+		if key != nil {
+			break
+		}
+	}
+	if key == nil {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, ErrNoIdentity
+	}
+
+	v := &Vault{file: f, key: key, values: map[string]string{}}
+	if !hmac.Equal([]byte(v.mac()), []byte(f.Squad.MAC)) {
+		return nil, fmt.Errorf("the values of %s were modified without its key", FileName)
+	}
+	for name, encrypted := range f.Values {
+		value, err := v.decrypt(name, encrypted)
+		if err != nil {
+			return nil, err
+		}
+		v.values[name] = value
+	}
+	return v, nil
+}
+
+// File returns the encrypted file of the vault.
+func (v *Vault) File() *File {
+	return v.file
+}
+
+// Values returns a copy of the decrypted values.
+func (v *Vault) Values() map[string]string {
+	values := make(map[string]string, len(v.values))
+	for name, value := range v.values {
+		values[name] = value
+	}
+	return values
+}
+
+// Set replaces the values of the vault. Values that did not change keep
+// their ciphertext, so that a diff of the file only shows the names whose
+// values changed.
+func (v *Vault) Set(values map[string]string) error {
+	encrypted := make(map[string]string, len(values))
+	for name, value := range values {
+		if old, ok := v.values[name]; ok && old == value {
+			encrypted[name] = v.file.Values[name]
+			continue
+		}
+		e, err := v.encrypt(name, value)
+		if err != nil {
+			return err
+		}
+		encrypted[name] = e
+	}
+	v.file.Values = encrypted
+	v.values = make(map[string]string, len(values))
+	for name, value := range values {
+		v.values[name] = value
+	}
+	v.seal()
+	return nil
+}
+
+// AddRecipient wraps the file key for one more recipient. Recipients that
+// can already unlock the file are skipped.
+func (v *Vault) AddRecipient(r Recipient) error {
+	if x, ok := r.(*X25519Recipient); ok {
+		for _, key := range v.file.RecipientKeys() {
+			if key == x.String() {
+				return nil
+			}
+		}
+	}
+	if _, ok := r.(*ScryptRecipient); ok && v.file.HasPassphrase() {
+		return fmt.Errorf("the secrets file already has a passphrase")
+	}
+	stanza, err := r.Wrap(v.key)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt the file key: %w", err)
+	}
+	v.file.Squad.Recipients = append(v.file.Squad.Recipients, *stanza)
+	return nil
+}
+
+func (v *Vault) subkey(info string) []byte {
+	key := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, v.key, nil, []byte(info)), key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// encrypt seals value under its name, so that values cannot be swapped
+// between names.
+func (v *Vault) encrypt(name string, value string) (string, error) {
+	aead, err := chacha20poly1305.NewX(v.subkey("squad-secrets/v1/values"))
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return valuePrefix + base64.StdEncoding.EncodeToString(sealed) + "]", nil
+}
+
+func (v *Vault) decrypt(name string, encrypted string) (string, error) {
+	if !strings.HasPrefix(encrypted, valuePrefix) || !strings.HasSuffix(encrypted, "]") {
+		return "", fmt.Errorf("value of %s is not encrypted", name)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(encrypted, valuePrefix), "]"))
+	if err != nil {
+		return "", fmt.Errorf("malformed value of %s: %w", name, err)
+	}
+	aead, err := chacha20poly1305.NewX(v.subkey("squad-secrets/v1/values"))
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("malformed value of %s", name)
+	}
+	value, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt the value of %s", name)
+	}
+	return string(value), nil
+}
+
+// mac authenticates the set of names and values, so that values cannot be
+// removed or restored from an older version of the file unnoticed.
+func (v *Vault) mac() string {
+	names := make([]string, 0, len(v.file.Values))
+	for name := range v.file.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := hmac.New(sha256.New, v.subkey("squad-secrets/v1/mac"))
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00%s\x00", name, v.file.Values[name])
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (v *Vault) seal() {
+	v.file.Squad.MAC = v.mac()
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFileName is the compose file that runs the services locally.
+const ComposeFileName = "compose.yaml"
+
+// composeHeader starts every generated compose.yaml, so that files written
+// by hand are recognized and left alone.
+const composeHeader = "# Generated by squad service from the services in squadbase.yml.\n"
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+	Volumes  map[string]struct{}       `yaml:"volumes,omitempty"`
+}
+
+type composeService struct {
+	Image       string            `yaml:"image"`
+	Restart     string            `yaml:"restart"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+	Ports       []string          `yaml:"ports"`
+	Volumes     []string          `yaml:"volumes,omitempty"`
+	Healthcheck composeHealth     `yaml:"healthcheck"`
+}
+
+type composeHealth struct {
+	Test     []string `yaml:"test,flow"`
+	Interval string   `yaml:"interval"`
+	Timeout  string   `yaml:"timeout"`
+	Retries  int      `yaml:"retries"`
+}
+
+// ComposePath returns the compose file of the project directory.
+func ComposePath(directory string) string {
+	return filepath.Join(directory, ComposeFileName)
+}
+
+// Compose renders a compose file running every service with a healthcheck
+// and a named volume for its data.
+func Compose(services []config.Service) (string, error) {
+	file := composeFile{Services: map[string]composeService{}, Volumes: map[string]struct{}{}}
+	for i := range services {
+		s := &services[i]
+		d, err := Get(s.Type)
+		if err != nil {
+			return "", err
+		}
+		volume := s.Name + "-data"
+		file.Services[s.Name] = composeService{
+			Image:       d.Image + ":" + d.Version(s),
+			Restart:     "unless-stopped",
+			Environment: d.Environment,
+			Ports:       []string{fmt.Sprintf("127.0.0.1:%d:%d", d.HostPort(s), d.Port)},
+			Volumes:     []string{volume + ":" + d.DataDir},
+			Healthcheck: composeHealth{Test: d.Healthcheck, Interval: "5s", Timeout: "5s", Retries: 10},
+		}
+		file.Volumes[volume] = struct{}{}
+	}
+
+	data, err := yaml.Marshal(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s: %w", ComposeFileName, err)
+	}
+	var content strings.Builder
+	content.WriteString(composeHeader)
+	content.WriteString("# Change the services with squad service add, then start them with squad service up.\n\n")
+	content.Write(data)
+	return content.String(), nil
+}
+
+// WriteCompose writes the compose file of the services into directory and
+// reports whether it did. A compose.yaml that was not generated by Compose
+// is kept as it is.
+func WriteCompose(directory string, services []config.Service) (bool, error) {
+	path := ComposePath(directory)
+	existing, err := os.ReadFile(path)
+	if err == nil && !strings.HasPrefix(string(existing), composeHeader) {
+		return false, nil
+	}
+	content, err := Compose(services)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", ComposeFileName, err)
+	}
+	return true, nil
+}
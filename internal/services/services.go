@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/squadbase/squadbase/internal/config"
+)
+
+// Local credentials of the services. They only guard containers published
+// on localhost for development.
+const (
+	localUser     = "squad"
+	localPassword = "squad"
+	localDatabase = "app"
+)
+
+// Definition describes how to run a type of service locally and how the app
+// connects to it.
+type Definition struct {
+	Type           string
+	Description    string
+	Image          string
+	DefaultVersion string
+	// Port is the port the service listens on inside its container.
+	Port int
+	// DataDir is the directory kept in a named volume.
+	DataDir     string
+	Environment map[string]string
+	Healthcheck []string
+	// URLEnv is the variable the connection URL is stored in.
+	URLEnv string
+	url    func(port int) string
+}
+
+var definitions = map[string]*Definition{
+	"postgres": {
+		Type:           "postgres",
+		Description:    "PostgreSQL database",
+		Image:          "postgres",
+		DefaultVersion: "16",
+		Port:           5432,
+		DataDir:        "/var/lib/postgresql/data",
+		Environment: map[string]string{
+			"POSTGRES_USER":     localUser,
+			"POSTGRES_PASSWORD": localPassword,
+			"POSTGRES_DB":       localDatabase,
+		},
+		Healthcheck: []string{"CMD-SHELL", fmt.Sprintf("pg_isready -U %s -d %s", localUser, localDatabase)},
+		URLEnv:      "DATABASE_URL",
+		url: func(port int) string {
+			return fmt.Sprintf("postgres://%s:%s@localhost:%d/%s?sslmode=disable", localUser, localPassword, port, localDatabase)
+		},
+	},
+	"redis": {
+		Type:           "redis",
+		Description:    "Redis",
+		Image:          "redis",
+		DefaultVersion: "7",
+		Port:           6379,
+		DataDir:        "/data",
+		Healthcheck:    []string{"CMD", "redis-cli", "ping"},
+		URLEnv:         "REDIS_URL",
+		url: func(port int) string {
+			return fmt.Sprintf("redis://localhost:%d/0", port)
+		},
+	},
+}
+
+// Get returns the definition of a service type.
+func Get(serviceType string) (*Definition, error) {
+	d, ok := definitions[serviceType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported service type %q: use %s", serviceType, strings.Join(Types(), " or "))
+	}
+	return d, nil
+}
+
+// Types returns the supported service types.
+func Types() []string {
+	types := make([]string, 0, len(definitions))
+	for t := range definitions {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Version returns the image version of s, or the default of its type.
+func (d *Definition) Version(s *config.Service) string {
+	if s.Version != "" {
+		return s.Version
+	}
+	return d.DefaultVersion
+}
+
+// HostPort returns the local port s is published on.
+func (d *Definition) HostPort(s *config.Service) int {
+	if s.Port != 0 {
+		return s.Port
+	}
+	return d.Port
+}
+
+// EnvName returns the variable holding the connection URL of s. A service
+// named after its type uses the plain name, such as DATABASE_URL; others
+// are prefixed with their name, such as ANALYTICS_DATABASE_URL.
+func (d *Definition) EnvName(s *config.Service) string {
+	if s.Name == s.Type {
+		return d.URLEnv
+	}
+	return strings.ToUpper(strings.ReplaceAll(s.Name, "-", "_")) + "_" + d.URLEnv
+}
+
+// URL returns the URL the app connects to s with locally.
+func (d *Definition) URL(s *config.Service) string {
+	return d.url(d.HostPort(s))
+}
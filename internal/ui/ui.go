@@ -29,6 +29,11 @@ var (
 			BorderForeground(lipgloss.Color("#f39c12")).
 			Padding(0, 1).
 			MarginTop(1)
+	errorBoxStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("#e74c3c")).
+			Padding(0, 1).
+			MarginTop(1)
 )
 
 func PrintTitle(subtitle string) {
@@ -78,6 +83,22 @@ func GetAccentText(text string) string {
 	return accentStyle.Render(text)
 }
 
+func GetInfoText(text string) string {
+	return infoStyle.Render(text)
+}
+
+func GetSuccessText(text string) string {
+	return successStyle.Render(text)
+}
+
+func GetWarningText(text string) string {
+	return warningStyle.Render(text)
+}
+
+func GetErrorText(text string) string {
+	return errorStyle.Render(text)
+}
+
 func PrintInfo(message string) {
 	fmt.Printf("%s %s\n", infoStyle.Render("ℹ"), message)
 }
@@ -128,6 +149,16 @@ func PrintWarningBox(title string, message string) {
 	fmt.Println(warningBoxStyle.Render(content.String()))
 }
 
+func PrintErrorBox(title string, message string) {
+	header := errorStyle.Copy().Underline(true).Render(title)
+
+	var content strings.Builder
+	content.WriteString(header + "\n\n")
+	content.WriteString(message)
+
+	fmt.Println(errorBoxStyle.Render(content.String()))
+}
+
 func ShowProgressBar(title string, total int) *pterm.ProgressbarPrinter {
 	pb, _ := pterm.DefaultProgressbar.
 		WithTitle(title).
@@ -145,3 +176,20 @@ func ShowSpinner(text string) *pterm.SpinnerPrinter {
 		Start()
 	return spinner
 }
+
+func PrintDiff(diffText string) {
+	for _, line := range strings.Split(strings.TrimSuffix(diffText, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Println(primaryStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Println(accentStyle.Render(line))
+		case strings.HasPrefix(line, "+"):
+			fmt.Println(secondaryStyle.Render(line))
+		case strings.HasPrefix(line, "-"):
+			fmt.Println(errorStyle.Render(line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
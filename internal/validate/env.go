@@ -0,0 +1,33 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+// reservedEnv are set by the platform on every deployment.
+var reservedEnv = map[string]bool{"PORT": true}
+
+// validateEnv checks the declared environment variables.
+func validateEnv(vars []config.EnvVar) []resources.Problem {
+	problems := []resources.Problem{}
+	seen := map[string]bool{}
+	for i, v := range vars {
+		prefix := fmt.Sprintf("env[%d].name", i)
+		switch {
+		case v.Name == "":
+			problems = append(problems, resources.Problem{Path: prefix, Message: "is required"})
+		case !dotenv.IsValidKey(v.Name):
+			problems = append(problems, resources.Problem{Path: prefix, Message: fmt.Sprintf("%q may only contain letters, digits and '_', and cannot start with a digit", v.Name)})
+		case reservedEnv[v.Name]:
+			problems = append(problems, resources.Problem{Path: prefix, Message: fmt.Sprintf("%s is set by the platform and cannot be declared", v.Name)})
+		case seen[v.Name]:
+			problems = append(problems, resources.Problem{Path: prefix, Message: fmt.Sprintf("%q is declared more than once", v.Name)})
+		}
+		seen[v.Name] = true
+	}
+	return problems
+}
@@ -0,0 +1,45 @@
+package validate
+
+import (
+	"fmt"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/resources"
+	"github.com/squadbase/squadbase/internal/services"
+)
+
+// validateServices checks the backing services.
+func validateServices(list []config.Service) []resources.Problem {
+	problems := []resources.Problem{}
+	names := map[string]bool{}
+	ports := map[int]string{}
+	for i := range list {
+		s := &list[i]
+		prefix := fmt.Sprintf("services[%d]", i)
+		switch {
+		case s.Name == "":
+			problems = append(problems, resources.Problem{Path: prefix + ".name", Message: "is required"})
+		case !config.ValidTargetName(s.Name):
+			problems = append(problems, resources.Problem{Path: prefix + ".name", Message: "service names may only contain lowercase letters, digits, '-' and '_'"})
+		case names[s.Name]:
+			problems = append(problems, resources.Problem{Path: prefix + ".name", Message: fmt.Sprintf("%q is used by more than one service", s.Name)})
+		}
+		names[s.Name] = true
+
+		d, err := services.Get(s.Type)
+		if err != nil {
+			problems = append(problems, resources.Problem{Path: prefix + ".type", Message: err.Error()})
+			continue
+		}
+		if s.Port < 0 || s.Port > 65535 {
+			problems = append(problems, resources.Problem{Path: prefix + ".port", Message: fmt.Sprintf("%d is not a valid port", s.Port)})
+			continue
+		}
+		port := d.HostPort(s)
+		if other, ok := ports[port]; ok {
+			problems = append(problems, resources.Problem{Path: prefix + ".port", Message: fmt.Sprintf("%d is already used by %s", port, other)})
+		}
+		ports[port] = s.Name
+	}
+	return problems
+}
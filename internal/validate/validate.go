@@ -0,0 +1,21 @@
+// Package validate checks a whole squadbase.yml: its deployment targets,
+// its environment variable declarations and its backing services.
+package validate
+
+import (
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+// Config checks cfg and returns a *resources.ValidationError listing every
+// problem found in it.
+func Config(cfg *config.Config) error {
+	problems := provider.Problems(cfg)
+	problems = append(problems, validateEnv(cfg.Env)...)
+	problems = append(problems, validateServices(cfg.Services)...)
+	if len(problems) > 0 {
+		return &resources.ValidationError{Problems: problems}
+	}
+	return nil
+}
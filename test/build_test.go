@@ -0,0 +1,128 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/container"
+)
+
+// fakeEngine records the commands it is asked to run and replays output.
+type fakeEngine struct {
+	calls  [][]string
+	stdout string
+	stderr string
+	err    error
+}
+
+func (e *fakeEngine) Name() string {
+	return "fake"
+}
+
+func (e *fakeEngine) Run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	e.calls = append(e.calls, args)
+	fmt.Fprint(stdout, e.stdout)
+	fmt.Fprint(stderr, e.stderr)
+	return e.err
+}
+
+func TestBuildGeneratesDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	scratch := t.TempDir()
+	build := config.Build{
+		Runtime:        "python3.11",
+		Framework:      "streamlit",
+		PackageManager: "pip",
+		Entrypoint:     "app.py",
+		BuildArgs:      []string{"API_URL=https://api.example.com"},
+	}
+
+	opts, err := container.NewBuildOptions(dir, build, scratch)
+	if err != nil {
+		t.Fatalf("Error resolving build options: %v", err)
+	}
+	if !opts.Generated || opts.Dockerfile != filepath.Join(scratch, "Dockerfile") {
+		t.Errorf("Expected a generated Dockerfile in the scratch directory, got %+v", opts)
+	}
+	dockerfile, err := os.ReadFile(opts.Dockerfile)
+	if err != nil || !strings.Contains(string(dockerfile), `"app.py"`) {
+		t.Errorf("Expected the generated Dockerfile to run app.py, got %v:\n%s", err, dockerfile)
+	}
+	if _, err := os.Stat(filepath.Join(scratch, "Dockerfile.dockerignore")); err != nil {
+		t.Errorf("Expected a Dockerfile-specific .dockerignore: %v", err)
+	}
+
+	opts.Tags = []string{"dashboard:dev", "registry.example.com/dashboard:dev"}
+	engine := &fakeEngine{stdout: "Successfully built\n"}
+	var stdout bytes.Buffer
+	if err := container.Build(context.Background(), engine, opts, &stdout, io.Discard); err != nil {
+		t.Fatalf("Error building: %v", err)
+	}
+
+	expected := []string{
+		"build", "--file", opts.Dockerfile,
+		"--platform", "linux/amd64",
+		"--tag", "dashboard:dev",
+		"--tag", "registry.example.com/dashboard:dev",
+		"--build-arg", "API_URL=https://api.example.com",
+		dir,
+	}
+	if len(engine.calls) != 1 || !slices.Equal(engine.calls[0], expected) {
+		t.Errorf("Unexpected build command:\n got %q\nwant %q", engine.calls, expected)
+	}
+	if stdout.String() != "Successfully built\n" {
+		t.Errorf("Expected output to be streamed, got %q", stdout.String())
+	}
+}
+
+func TestBuildCustomDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	build := config.Build{UseCustomDockerfile: true, Context: "app"}
+	if err := os.MkdirAll(filepath.Join(dir, "app"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := container.NewBuildOptions(dir, build, t.TempDir()); err == nil || !strings.Contains(err.Error(), "squad dockerfile") {
+		t.Errorf("Expected a missing custom Dockerfile to be reported, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "app", "Dockerfile"), []byte("FROM scratch\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := container.NewBuildOptions(dir, build, t.TempDir())
+	if err != nil {
+		t.Fatalf("Error resolving build options: %v", err)
+	}
+	if opts.Generated || opts.Dockerfile != filepath.Join(dir, "app", "Dockerfile") || opts.Context != filepath.Join(dir, "app") {
+		t.Errorf("Expected the custom Dockerfile in the context to be used, got %+v", opts)
+	}
+}
+
+func TestBuildFailureSummary(t *testing.T) {
+	engine := &fakeEngine{
+		stdout: "#1 [internal] load build definition from Dockerfile\n",
+		stderr: "#7 [deps 4/4] RUN pip install -r requirements.txt\n" +
+			"#7 1.204 ERROR: Could not find a version that satisfies the requirement streamlit==99\n" +
+			"#7 ERROR: process \"/bin/sh -c pip install\" did not complete successfully: exit code: 1\n" +
+			"------\n",
+		err: errors.New("exit status 1"),
+	}
+
+	err := container.Build(context.Background(), engine, &container.BuildOptions{Dockerfile: "Dockerfile", Context: "."}, io.Discard, io.Discard)
+	var buildErr *container.BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Expected a BuildError, got %v", err)
+	}
+	if len(buildErr.Summary) != 2 || !strings.Contains(buildErr.Summary[0], "streamlit==99") {
+		t.Errorf("Expected the summary to list the error lines, got %q", buildErr.Summary)
+	}
+}
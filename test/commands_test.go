@@ -16,6 +16,26 @@ func setupApp() *cli.App {
 		Commands: []*cli.Command{
 			cmd.InitCommand(),
 			cmd.CreateCommand(),
+			cmd.MigrateCommand(),
+			cmd.RenderCommand(),
+			cmd.CostCommand(),
+			cmd.ExportCommand(),
+			cmd.DockerfileCommand(),
+			cmd.BuildCommand(),
+			cmd.RunCommand(),
+			cmd.DevCommand(),
+			cmd.PackageCommand(),
+			cmd.DeployCommand(),
+			cmd.LoginCommand(),
+			cmd.LogoutCommand(),
+			cmd.WhoamiCommand(),
+			cmd.StatusCommand(),
+			cmd.LogsCommand(),
+			cmd.DeploymentsCommand(),
+			cmd.RollbackCommand(),
+			cmd.EnvCommand(),
+			cmd.SecretsCommand(),
+			cmd.ServiceCommand(),
 			cmd.HelpCommand(),
 		},
 		Action: func(c *cli.Context) error {
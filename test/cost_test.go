@@ -0,0 +1,56 @@
+package test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/cost"
+	"github.com/squadbase/squadbase/internal/provider"
+)
+
+func TestCalculateLambdaCost(t *testing.T) {
+	cfg, err := config.Parse([]byte("deployment:\n    provider: aws\n    aws:\n        memory: 1024\n"))
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	p, err := provider.Get("aws")
+	if err != nil {
+		t.Fatalf("Error getting provider: %v", err)
+	}
+
+	usage := cost.Usage{RequestsPerMonth: 1000000, AvgDuration: 300 * time.Millisecond}
+	estimate, err := cost.Calculate("aws", p.Resources(&cfg.Deployment), usage)
+	if err != nil {
+		t.Fatalf("Error calculating cost: %v", err)
+	}
+
+	// 1M requests x 0.3s x 1 GiB = 300,000 GB-s, plus $0.20 per million requests.
+	if math.Abs(estimate.Items[0].Cost-5.00) > 0.01 {
+		t.Errorf("Expected compute to cost about $5.00, got %.4f", estimate.Items[0].Cost)
+	}
+	if math.Abs(estimate.Total-5.20) > 0.01 {
+		t.Errorf("Expected total of about $5.20, got %.4f", estimate.Total)
+	}
+}
+
+func TestCalculateCostSharesInstancesAcrossConcurrentRequests(t *testing.T) {
+	p, _ := provider.Get("gcp")
+	usage := cost.Usage{RequestsPerMonth: 1000000, AvgDuration: time.Second}
+
+	single, _ := config.Parse([]byte("deployment:\n    provider: gcp\n    gcp:\n        concurrency: 1\n"))
+	shared, _ := config.Parse([]byte("deployment:\n    provider: gcp\n    gcp:\n        concurrency: 10\n"))
+
+	singleEstimate, err := cost.Calculate("gcp", p.Resources(&single.Deployment), usage)
+	if err != nil {
+		t.Fatalf("Error calculating cost: %v", err)
+	}
+	sharedEstimate, err := cost.Calculate("gcp", p.Resources(&shared.Deployment), usage)
+	if err != nil {
+		t.Fatalf("Error calculating cost: %v", err)
+	}
+	if math.Abs(singleEstimate.Items[0].Cost-10*sharedEstimate.Items[0].Cost) > 1e-9 {
+		t.Errorf("Expected compute to scale with concurrency, got %.4f and %.4f", singleEstimate.Items[0].Cost, sharedEstimate.Items[0].Cost)
+	}
+}
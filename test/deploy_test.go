@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+)
+
+var fastBackoff = api.Backoff{Initial: time.Millisecond, Max: 5 * time.Millisecond, Factor: 2}
+
+func TestDeployPollsUntilReady(t *testing.T) {
+	server := newFakeAPI(t)
+	server.failPolls = 2
+	client := server.client(t)
+	ctx := context.Background()
+
+	data := []byte("bundle contents")
+	sum := sha256.Sum256(data)
+	bundle, err := client.UploadBundle(ctx, "sales-dashboard", bytes.NewReader(data), int64(len(data)), hex.EncodeToString(sum[:]))
+	if err != nil {
+		t.Fatalf("Error uploading bundle: %v", err)
+	}
+
+	deployment, err := client.CreateDeployment(ctx, "sales-dashboard", &api.CreateDeploymentRequest{
+		BundleID: bundle.ID,
+		Target:   "production",
+		Config:   "version: '1'\n",
+	})
+	if err != nil {
+		t.Fatalf("Error creating deployment: %v", err)
+	}
+
+	statuses := []string{}
+	final, err := client.WaitForDeployment(ctx, "sales-dashboard", deployment.ID, fastBackoff, func(d *api.Deployment) {
+		statuses = append(statuses, d.Status)
+	})
+	if err != nil {
+		t.Fatalf("Error waiting for deployment: %v", err)
+	}
+	if final.Status != api.StatusReady || final.URL != "https://sales-dashboard.squadbase.app" {
+		t.Errorf("Unexpected final deployment: %+v", final)
+	}
+	if strings.Join(statuses, ",") != "building,deploying,ready" {
+		t.Errorf("Expected every status change to be reported once, got %v", statuses)
+	}
+}
+
+func TestDeployReportsAPIErrors(t *testing.T) {
+	server := newFakeAPI(t)
+	client, _ := api.NewClient(server.URL, "wrong-token")
+
+	_, err := client.GetDeployment(context.Background(), "sales-dashboard", "dep_1")
+	if !api.IsUnauthorized(err) || !strings.Contains(err.Error(), "invalid token") {
+		t.Errorf("Expected an unauthorized error with the API's message, got %v", err)
+	}
+
+	_, err = server.client(t).UploadBundle(context.Background(), "sales-dashboard", strings.NewReader("data"), 4, "not-the-hash")
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected the checksum mismatch to be reported, got %v", err)
+	}
+}
+
+// slowReader returns one byte per read, after a delay.
+type slowReader struct {
+	data  []byte
+	delay time.Duration
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(r.delay)
+	p[0], r.data = r.data[0], r.data[1:]
+	return 1, nil
+}
+
+func TestUploadOutlastsTheRequestTimeout(t *testing.T) {
+	server := newFakeAPI(t)
+	client := server.client(t)
+	client.RequestTimeout = 20 * time.Millisecond
+
+	data := []byte("slow bundle")
+	sum := sha256.Sum256(data)
+	bundle := &slowReader{data: data, delay: 10 * time.Millisecond}
+	if _, err := client.UploadBundle(context.Background(), "sales-dashboard", bundle, int64(len(data)), hex.EncodeToString(sum[:])); err != nil {
+		t.Errorf("Expected a slow upload not to time out, got %v", err)
+	}
+}
+
+func TestDeployCommand(t *testing.T) {
+	server := newFakeAPI(t)
+	server.progress = []string{api.StatusReady}
+	t.Setenv("SQUAD_TOKEN", fakeToken)
+	t.Setenv("SALES_API_TOKEN", "sk-live-123")
+
+	dir := t.TempDir()
+	yml := "version: '1'\nbuild:\n    runtime: python3.11\n    framework: streamlit\n    package_manager: uv\n    build_args:\n        - SALES_API_TOKEN=${SALES_API_TOKEN}\ndeployment:\n    provider: gcp\n"
+	if err := os.WriteFile(filepath.Join(dir, "squadbase.yml"), []byte(yml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.py"), []byte("print('hi')\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := setupApp()
+	err := app.Run([]string{"squad", "deploy", "--api-url", server.URL, "--project", "sales-dashboard", dir})
+	if err != nil {
+		t.Fatalf("Error deploying: %v", err)
+	}
+
+	if len(server.deployments) != 1 {
+		t.Fatalf("Expected one deployment, got %d", len(server.deployments))
+	}
+	d := server.deployments[0]
+	if d.Target != "default" || !strings.Contains(d.Config, "framework: streamlit") || d.Status != api.StatusReady {
+		t.Errorf("Unexpected deployment: %+v", d)
+	}
+	if strings.Contains(d.Config, "sk-live-123") || !strings.Contains(d.Config, "${SALES_API_TOKEN}") {
+		t.Errorf("Expected the config to be stored without resolved values, got:\n%s", d.Config)
+	}
+}
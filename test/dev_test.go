@@ -0,0 +1,103 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dev"
+)
+
+func TestDevPlanCommands(t *testing.T) {
+	dir := t.TempDir()
+	testCases := []struct {
+		build   config.Build
+		command string
+		install string
+	}{
+		{config.Build{Framework: "streamlit", PackageManager: "poetry"}, "poetry run streamlit run main.py", "poetry install --no-root"},
+		{config.Build{Framework: "streamlit", PackageManager: "uv", Entrypoint: "app.py"}, "uv run streamlit run app.py", "uv sync"},
+		{config.Build{Framework: "morph", PackageManager: "pip"}, "morph serve", "pip install -r requirements.txt"},
+		{config.Build{Framework: "nextjs", PackageManager: "npm"}, "npm run dev", "npm install"},
+		{config.Build{Framework: "nextjs", PackageManager: "pnpm"}, "pnpm dev", "pnpm install"},
+	}
+
+	for _, tc := range testCases {
+		plan, err := dev.NewPlan(dir, tc.build)
+		if err != nil {
+			t.Fatalf("Error planning %s with %s: %v", tc.build.Framework, tc.build.PackageManager, err)
+		}
+		if command := strings.Join(plan.Command, " "); command != tc.command {
+			t.Errorf("Expected %q, got %q", tc.command, command)
+		}
+		if install := strings.Join(plan.Install[0], " "); install != tc.install {
+			t.Errorf("Expected %q, got %q", tc.install, install)
+		}
+	}
+
+	plan, _ := dev.NewPlan(dir, config.Build{Framework: "morph", PackageManager: "uv"})
+	if !slices.Contains(plan.Manifests, "uv.lock") || !slices.Contains(plan.Manifests, "package.json") {
+		t.Errorf("Expected morph to watch python and node manifests, got %v", plan.Manifests)
+	}
+
+	if _, err := dev.NewPlan(dir, config.Build{Framework: "nextjs", PackageManager: "poetry"}); err == nil {
+		t.Error("Expected nextjs with poetry to be rejected")
+	}
+}
+
+func TestDevPlanUsesProjectVenv(t *testing.T) {
+	dir := t.TempDir()
+	streamlit := filepath.Join(dir, ".venv", "bin", "streamlit")
+	if err := os.MkdirAll(filepath.Dir(streamlit), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(streamlit, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := dev.NewPlan(dir, config.Build{Framework: "streamlit", PackageManager: "pip"})
+	if err != nil {
+		t.Fatalf("Error planning: %v", err)
+	}
+	if plan.Command[0] != streamlit {
+		t.Errorf("Expected the project's .venv to be used, got %v", plan.Command)
+	}
+}
+
+func TestDevSnapshotDetectsManifestChanges(t *testing.T) {
+	dir := t.TempDir()
+	manifests := []string{"pyproject.toml", "uv.lock"}
+	if err := os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte("[project]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := dev.TakeSnapshot(dir, manifests)
+	if changed := snapshot.Changed(dir, manifests); len(changed) != 0 {
+		t.Errorf("Expected no changes, got %v", changed)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "uv.lock"), []byte("version = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "pyproject.toml"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if changed := snapshot.Changed(dir, manifests); !slices.Equal(changed, manifests) {
+		t.Errorf("Expected both manifests to be reported, got %v", changed)
+	}
+}
+
+func TestDevRejectsNonPositivePollInterval(t *testing.T) {
+	for _, interval := range []string{"0", "-1s"} {
+		app := setupApp()
+		err := app.Run([]string{"squad", "dev", "--poll-interval", interval, t.TempDir()})
+		if err == nil || !strings.Contains(err.Error(), "--poll-interval") {
+			t.Errorf("Expected --poll-interval %s to be rejected, got %v", interval, err)
+		}
+	}
+}
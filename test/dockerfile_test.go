@@ -0,0 +1,111 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/export"
+)
+
+func TestDockerfileCombinations(t *testing.T) {
+	testCases := []struct {
+		build    config.Build
+		expected []string
+	}{
+		{
+			build: config.Build{Runtime: "python3.10", Framework: "streamlit", PackageManager: "poetry", Entrypoint: "app/dashboard.py"},
+			expected: []string{
+				"FROM python:3.10-slim AS deps",
+				"COPY pyproject.toml poetry.lock* ./",
+				"poetry install --only main --no-root",
+				`CMD ["streamlit", "run", "app/dashboard.py", "--server.port=8501"`,
+			},
+		},
+		{
+			build:    config.Build{Runtime: "python3.12", Framework: "streamlit", PackageManager: "uv"},
+			expected: []string{"FROM python:3.12-slim AS runtime", "uv sync --no-dev --no-install-project", `"main.py"`},
+		},
+		{
+			build:    config.Build{Runtime: "python3.11", Framework: "morph", PackageManager: "pip"},
+			expected: []string{"FROM node:20-slim AS frontend", "COPY requirements.txt ./", `CMD ["morph", "serve", "--port", "8080"]`},
+		},
+		{
+			build:    config.Build{Runtime: "nodejs20", Framework: "nextjs", PackageManager: "npm"},
+			expected: []string{"FROM node:20-slim AS deps", "    npm ci", "RUN npm run build", "EXPOSE 3000"},
+		},
+		{
+			build:    config.Build{Runtime: "nodejs18", Framework: "nextjs", PackageManager: "yarn"},
+			expected: []string{"COPY package.json yarn.lock* ./", "RUN yarn build"},
+		},
+		{
+			build:    config.Build{Runtime: "nodejs16", Framework: "nextjs", PackageManager: "pnpm", BuildArgs: []string{"NEXT_PUBLIC_API_URL=https://api.example.com"}, RuntimeArgs: []string{"NEXT_PUBLIC_API_URL"}},
+			expected: []string{"RUN corepack enable pnpm", "ARG NEXT_PUBLIC_API_URL", "ENV NEXT_PUBLIC_API_URL=${NEXT_PUBLIC_API_URL}"},
+		},
+		{
+			build:    config.Build{Runtime: "nodejs20", Framework: "nextjs", PackageManager: "npm", Entrypoint: "apps/web"},
+			expected: []string{"WORKDIR /app/apps/web", `CMD ["/app/node_modules/.bin/next", "start", "-p", "3000"]`},
+		},
+	}
+
+	for _, tc := range testCases {
+		name := tc.build.Framework + "/" + tc.build.PackageManager
+		files, err := export.Dockerfile(tc.build)
+		if err != nil {
+			t.Fatalf("%s: error generating Dockerfile: %v", name, err)
+		}
+		dockerfile := exportedFile(t, files, export.DockerfileName)
+		for _, expected := range tc.expected {
+			if !strings.Contains(dockerfile, expected) {
+				t.Errorf("%s: expected Dockerfile to contain %q:\n%s", name, expected, dockerfile)
+			}
+		}
+		if strings.Contains(dockerfile, "https://api.example.com") {
+			t.Errorf("%s: expected build arg values to stay out of the Dockerfile", name)
+		}
+		if !strings.Contains(exportedFile(t, files, export.DockerignoreName), ".env") {
+			t.Errorf("%s: expected .dockerignore to exclude .env", name)
+		}
+	}
+}
+
+func TestDockerfileKeepsBuildArgsOutOfTheImageEnv(t *testing.T) {
+	build := config.Build{
+		Runtime:        "python3.11",
+		Framework:      "streamlit",
+		PackageManager: "uv",
+		BuildArgs:      []string{"PIP_TOKEN=secret", "APP_MODE=demo"},
+		RuntimeArgs:    []string{"APP_MODE"},
+	}
+	files, err := export.Dockerfile(build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dockerfile := exportedFile(t, files, export.DockerfileName)
+	if !strings.Contains(dockerfile, "ENV APP_MODE=${APP_MODE}") {
+		t.Errorf("Expected the runtime arg to be exposed:\n%s", dockerfile)
+	}
+	if strings.Contains(dockerfile, "PIP_TOKEN") {
+		t.Errorf("Expected the other build args to stay out of the runtime stage:\n%s", dockerfile)
+	}
+
+	build.RuntimeArgs = []string{"MISSING"}
+	if _, err := export.Dockerfile(build); err == nil {
+		t.Error("Expected runtime_args outside build_args to be rejected")
+	}
+}
+
+func TestDockerfileRejectsMismatchedBuild(t *testing.T) {
+	builds := []config.Build{
+		{Runtime: "nodejs20", Framework: "streamlit", PackageManager: "pip"},
+		{Runtime: "python3.11", Framework: "streamlit", PackageManager: "npm"},
+		{Runtime: "nodejs20", Framework: "nextjs", PackageManager: "uv"},
+		{Runtime: "python3.11", Framework: "django", PackageManager: "pip"},
+		{Runtime: "", Framework: "streamlit", PackageManager: "pip"},
+	}
+	for _, build := range builds {
+		if _, err := export.Dockerfile(build); err == nil {
+			t.Errorf("Expected %+v to be rejected", build)
+		}
+	}
+}
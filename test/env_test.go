@@ -0,0 +1,204 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/validate"
+)
+
+func TestDotenvSetKeepsCommentsAndOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	original := "# Local settings\nDEBUG=1\nexport GREETING=hello # inline\n\nLAST=x\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := dotenv.Set(path, "GREETING", "hello world"); err != nil {
+		t.Fatalf("Error setting value: %v", err)
+	}
+	if err := dotenv.Set(path, "QUOTED", `say "hi" # not a comment`); err != nil {
+		t.Fatalf("Error setting value: %v", err)
+	}
+	if removed, err := dotenv.Unset(path, "DEBUG"); err != nil || !removed {
+		t.Fatalf("Expected DEBUG to be removed, got %v, %v", removed, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	expected := "# Local settings\nGREETING=\"hello world\"\n\nLAST=x\nQUOTED=\"say \\\"hi\\\" # not a comment\"\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected .env:\n%s\nexpected:\n%s", data, expected)
+	}
+	values, err := dotenv.Read(path)
+	if err != nil {
+		t.Fatalf("Error reading .env back: %v", err)
+	}
+	if values["GREETING"] != "hello world" || values["QUOTED"] != `say "hi" # not a comment` || values["LAST"] != "x" {
+		t.Errorf("Values did not survive a round trip: %v", values)
+	}
+
+	created := filepath.Join(t.TempDir(), ".env")
+	if err := dotenv.Set(created, "TOKEN", "secret"); err != nil {
+		t.Fatalf("Error creating .env: %v", err)
+	}
+	if info, _ := os.Stat(created); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected a new .env to be readable only by the user, got %v", info.Mode().Perm())
+	}
+}
+
+func TestDotenvSetDropsDuplicateLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("TOKEN=a\nDEBUG=1\nTOKEN=b\nTOKEN=c\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := dotenv.Set(path, "TOKEN", "d"); err != nil {
+		t.Fatalf("Error setting value: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "TOKEN=d\nDEBUG=1\n" {
+		t.Errorf("Expected the later TOKEN lines to be dropped, got:\n%s", data)
+	}
+}
+
+func runEnvCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	app := setupApp()
+	app.Writer = &out
+	err := app.Run(append([]string{"squad", "env"}, args...))
+	return out.String(), err
+}
+
+func TestEnvCommand(t *testing.T) {
+	dir := t.TempDir()
+	if err := project.CreateSquadbaseYml(dir, "streamlit", "3.11", "uv", "gcp", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := runEnvCommand(t, "set", "--dir", dir, "--required", "-d", "Key of the sales API", "SALES_API_KEY=sk-123")
+	if err != nil {
+		t.Fatalf("Error setting variable: %v", err)
+	}
+	if _, err := runEnvCommand(t, "set", "--dir", dir, "SALES_REGION=eu west"); err != nil {
+		t.Fatalf("Error setting variable: %v", err)
+	}
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := cfg.EnvVar("SALES_API_KEY")
+	if key == nil || !key.Required || !key.Secret || key.Description != "Key of the sales API" {
+		t.Errorf("Expected SALES_API_KEY to be declared as a required secret, got %+v", key)
+	}
+	if region := cfg.EnvVar("SALES_REGION"); region == nil || region.Required || region.Secret {
+		t.Errorf("Expected SALES_REGION to be declared as optional, got %+v", region)
+	}
+
+	example, _ := os.ReadFile(filepath.Join(dir, config.EnvExampleFileName))
+	for _, expected := range []string{"# Key of the sales API\n# (required, secret)\nSALES_API_KEY=\n", "SALES_REGION=\n"} {
+		if !strings.Contains(string(example), expected) {
+			t.Errorf("Expected .env.example to contain %q, got:\n%s", expected, example)
+		}
+	}
+	if strings.Contains(string(example), "sk-123") {
+		t.Error("Expected .env.example not to contain values")
+	}
+
+	out, err := runEnvCommand(t, "list", "--dir", dir, "--json")
+	if err != nil {
+		t.Fatalf("Error listing variables: %v", err)
+	}
+	var entries []struct {
+		Name   string `json:"name"`
+		Value  string `json:"value"`
+		Source string `json:"source"`
+	}
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("Error decoding %q: %v", out, err)
+	}
+	if len(entries) != 2 || entries[0].Value != "********" || entries[1].Value != "eu west" || entries[1].Source != ".env" {
+		t.Errorf("Expected secrets to be masked, got %+v", entries)
+	}
+
+	if _, err := runEnvCommand(t, "unset", "--dir", dir, "SALES_API_KEY"); err != nil {
+		t.Fatalf("Error unsetting variable: %v", err)
+	}
+	if _, err := runEnvCommand(t, "list", "--dir", dir); err == nil || !strings.Contains(err.Error(), "SALES_API_KEY") {
+		t.Errorf("Expected list to fail on the missing required variable, got %v", err)
+	}
+
+	if _, err := runEnvCommand(t, "unset", "--dir", dir, "--undeclare", "SALES_API_KEY"); err != nil {
+		t.Fatalf("Error undeclaring variable: %v", err)
+	}
+	if _, err := runEnvCommand(t, "list", "--dir", dir); err != nil {
+		t.Errorf("Expected list to pass once the variable is no longer declared, got %v", err)
+	}
+	if cfg, _ := config.Load(dir); cfg.EnvVar("SALES_API_KEY") != nil {
+		t.Error("Expected the declaration to be removed")
+	}
+}
+
+func TestEnvSetChecksTheVersion(t *testing.T) {
+	dir := t.TempDir()
+	original := "version: '99'\ndeployment:\n    provider: gcp\n"
+	if err := os.WriteFile(config.Path(dir), []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runEnvCommand(t, "set", "--dir", dir, "DEBUG=1"); !errors.Is(err, config.ErrUnsupportedVersion) {
+		t.Errorf("Expected set to reject an unsupported version, got %v", err)
+	}
+	if data, _ := os.ReadFile(config.Path(dir)); string(data) != original {
+		t.Errorf("Expected %s to be left as it is, got:\n%s", config.FileName, data)
+	}
+}
+
+func TestEnvDeclarationsAreValidated(t *testing.T) {
+	cfg, err := config.Parse([]byte("version: '1'\ndeployment:\n    provider: gcp\nenv:\n    - name: PORT\n    - name: 1BAD\n    - name: API_KEY\n    - name: API_KEY\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = validate.Config(cfg)
+	if err == nil {
+		t.Fatal("Expected invalid declarations to fail validation")
+	}
+	for _, expected := range []string{"env[0].name", "env[1].name", "env[3].name"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %s to be reported, got:\n%v", expected, err)
+		}
+	}
+}
+
+func TestGeneratedProjectDeclaresEnv(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("OPENAI_API_KEY=sk\nLOG_LEVEL=info\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	env := project.DetectEnvVars(dir)
+	if err := project.CreateSquadbaseYml(dir, "streamlit", "3.11", "uv", "gcp", "", env); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatalf("Error loading generated squadbase.yml: %v", err)
+	}
+	if len(cfg.Env) != 2 || cfg.Env[0].Name != "LOG_LEVEL" || cfg.Env[0].Secret || !cfg.Env[1].Secret {
+		t.Errorf("Expected the variables of .env to be declared, got %+v", cfg.Env)
+	}
+
+	handwritten := "# Ask the data team for a key\nOPENAI_API_KEY=sk-...\n"
+	if err := os.WriteFile(filepath.Join(dir, config.EnvExampleFileName), []byte(handwritten), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if written, err := config.WriteEnvExample(dir, cfg.Env); err != nil || written {
+		t.Errorf("Expected a hand-written .env.example to be kept, got %v, %v", written, err)
+	}
+}
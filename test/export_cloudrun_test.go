@@ -0,0 +1,121 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/export"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
+	"gopkg.in/yaml.v3"
+)
+
+// labelKeyPattern matches the label keys GCP accepts.
+var labelKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+
+// TestExportCloudRunGolden exports each project in testdata/cloudrun and
+// compares the result with the hand-written service.yaml next to it. Both are
+// parsed before comparing, so only the manifest content has to match.
+func TestExportCloudRunGolden(t *testing.T) {
+	tests := []struct {
+		name             string
+		target           string
+		previousRevision string
+	}{
+		{name: "streamlit-defaults"},
+		{name: "nextjs-preset", target: "tokyo"},
+		{name: "canary", previousRevision: "sales-dashboard-00007-xyz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "cloudrun", tt.name)
+			doc, err := config.ReadDocument(dir)
+			if err != nil {
+				t.Fatalf("Error reading squadbase.yml: %v", err)
+			}
+			if err := resources.ExpandPresets(doc); err != nil {
+				t.Fatalf("Error expanding presets: %v", err)
+			}
+			cfg, err := doc.Decode()
+			if err != nil {
+				t.Fatalf("Error decoding squadbase.yml: %v", err)
+			}
+			if err := provider.Validate(cfg); err != nil {
+				t.Fatalf("Expected squadbase.yml to be valid, got %v", err)
+			}
+			target, err := cfg.Target(tt.target)
+			if err != nil {
+				t.Fatalf("Error selecting target: %v", err)
+			}
+
+			app, err := export.NewApp("sales-dashboard", cfg, target)
+			if err != nil {
+				t.Fatalf("Error collecting app: %v", err)
+			}
+			app.Image = "us-docker.pkg.dev/acme/apps/sales-dashboard:v1"
+			app.PreviousRevision = tt.previousRevision
+
+			files, err := export.CloudRunService(app)
+			if err != nil {
+				t.Fatalf("Error exporting service.yaml: %v", err)
+			}
+			generated := exportedFile(t, files, "service.yaml")
+
+			reference, err := os.ReadFile(filepath.Join(dir, "service.yaml"))
+			if err != nil {
+				t.Fatalf("Error reading reference manifest: %v", err)
+			}
+
+			var got, want any
+			if err := yaml.Unmarshal([]byte(generated), &got); err != nil {
+				t.Fatalf("Generated service.yaml is not valid YAML: %v\n%s", err, generated)
+			}
+			if err := yaml.Unmarshal(reference, &want); err != nil {
+				t.Fatalf("Error parsing reference manifest: %v", err)
+			}
+			var manifest struct {
+				Metadata struct {
+					Labels map[string]string `yaml:"labels"`
+				} `yaml:"metadata"`
+			}
+			if err := yaml.Unmarshal([]byte(generated), &manifest); err != nil {
+				t.Fatal(err)
+			}
+			for key := range manifest.Metadata.Labels {
+				// Keys under cloud.googleapis.com/ are set by Cloud Run itself.
+				if !strings.HasPrefix(key, "cloud.googleapis.com/") && !labelKeyPattern.MatchString(key) {
+					t.Errorf("Label key %q is not accepted by Cloud Run", key)
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Generated service.yaml does not match %s:\n%s", filepath.Join(dir, "service.yaml"), generated)
+			}
+		})
+	}
+}
+
+func TestExportCloudRunNeedsPreviousRevision(t *testing.T) {
+	app := exportApp(t, `deployment:
+    provider: gcp
+traffic:
+    split:
+        - revision: latest
+          percent: 50
+        - revision: previous
+          percent: 50
+`, "")
+	if _, err := export.CloudRunService(app); err == nil {
+		t.Errorf("Expected an error when the previous revision is not named")
+	}
+
+	app = exportApp(t, "deployment:\n    provider: aws\n", "")
+	if _, err := export.CloudRunService(app); err == nil {
+		t.Errorf("Expected an error for an aws target")
+	}
+}
@@ -0,0 +1,319 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/export"
+	"gopkg.in/yaml.v3"
+)
+
+func exportApp(t *testing.T, yml string, targetName string) *export.App {
+	t.Helper()
+	cfg, err := config.Parse([]byte(yml))
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+	target, err := cfg.Target(targetName)
+	if err != nil {
+		t.Fatalf("Error selecting target: %v", err)
+	}
+	app, err := export.NewApp("sales-dashboard", cfg, target)
+	if err != nil {
+		t.Fatalf("Error collecting app: %v", err)
+	}
+	return app
+}
+
+func exportedFile(t *testing.T, files []export.File, name string) string {
+	t.Helper()
+	for _, file := range files {
+		if file.Name == name {
+			return string(file.Content)
+		}
+	}
+	t.Fatalf("Expected %s to be exported", name)
+	return ""
+}
+
+func TestExportTerraformLambda(t *testing.T) {
+	app := exportApp(t, `build:
+    framework: morph
+deployment:
+    provider: aws
+    aws:
+        memory: 2048
+        timeout: 120
+        provisioned_concurrency: 2
+        ephemeral_storage: 1024MB
+traffic:
+    split:
+        - revision: latest
+          percent: 10
+        - revision: previous
+          percent: 90
+`, "")
+
+	files, err := export.Terraform(app)
+	if err != nil {
+		t.Fatalf("Error exporting terraform: %v", err)
+	}
+	main := exportedFile(t, files, "main.tf")
+	for _, expected := range []string{
+		"  memory_size   = 2048\n",
+		"  timeout       = 120\n",
+		"  ephemeral_storage {\n    size = 1024\n  }\n",
+		"  function_version = var.previous_version\n",
+		"      (aws_lambda_function.app.version) = 0.1\n",
+		"  provisioned_concurrent_executions = 2\n",
+	} {
+		if !strings.Contains(main, expected) {
+			t.Errorf("Expected main.tf to contain %q, got:\n%s", expected, main)
+		}
+	}
+	if variables := exportedFile(t, files, "variables.tf"); !strings.Contains(variables, `variable "previous_version"`) {
+		t.Errorf("Expected a previous_version variable, got:\n%s", variables)
+	}
+
+	again, _ := export.Terraform(app)
+	for i := range files {
+		if !bytes.Equal(files[i].Content, again[i].Content) {
+			t.Errorf("Expected %s to be deterministic", files[i].Name)
+		}
+	}
+}
+
+func TestExportTerraformCloudRun(t *testing.T) {
+	app := exportApp(t, `build:
+    framework: streamlit
+deployment:
+    targets:
+        production:
+            provider: aws
+        tokyo:
+            provider: gcp
+            gcp:
+                region: asia-northeast1
+                cpu: 0.5
+                memory: 512
+                concurrency: 1
+                min_instances: 1
+`, "tokyo")
+
+	files, err := export.Terraform(app)
+	if err != nil {
+		t.Fatalf("Error exporting terraform: %v", err)
+	}
+	main := exportedFile(t, files, "main.tf")
+	for _, expected := range []string{
+		"    max_instance_request_concurrency = 1\n",
+		"      min_instance_count = 1\n",
+		"        container_port = 8501\n",
+		"          cpu    = \"500m\"\n          memory = \"512Mi\"\n",
+		"    type    = \"TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST\"\n    percent = 100\n",
+	} {
+		if !strings.Contains(main, expected) {
+			t.Errorf("Expected main.tf to contain %q, got:\n%s", expected, main)
+		}
+	}
+	if variables := exportedFile(t, files, "variables.tf"); !strings.Contains(variables, `default     = "asia-northeast1"`) {
+		t.Errorf("Expected the region to be the default of var.region, got:\n%s", variables)
+	}
+}
+
+func TestExportSAMTemplate(t *testing.T) {
+	app := exportApp(t, `build:
+    framework: morph
+    build_args:
+        - API_BASE_URL=https://api.example.com
+        - SENTRY_AUTH_TOKEN=sntrys-123
+    runtime_args:
+        - API_BASE_URL
+deployment:
+    provider: aws
+    aws:
+        region: eu-west-1
+        memory: 2048
+        timeout: 60
+        provisioned_concurrency: 3
+        ephemeral_storage: 1GB
+`, "")
+	app.ProjectPath = ".."
+
+	files, err := export.SAMTemplate(app)
+	if err != nil {
+		t.Fatalf("Error exporting template.yaml: %v", err)
+	}
+	content := exportedFile(t, files, "template.yaml")
+
+	var template struct {
+		Parameters map[string]struct {
+			Default string `yaml:"Default"`
+			NoEcho  bool   `yaml:"NoEcho"`
+		} `yaml:"Parameters"`
+		Resources struct {
+			AppFunction struct {
+				Type       string `yaml:"Type"`
+				Properties struct {
+					PackageType      string `yaml:"PackageType"`
+					MemorySize       int    `yaml:"MemorySize"`
+					Timeout          int    `yaml:"Timeout"`
+					EphemeralStorage struct {
+						Size int `yaml:"Size"`
+					} `yaml:"EphemeralStorage"`
+					ProvisionedConcurrencyConfig struct {
+						ProvisionedConcurrentExecutions int `yaml:"ProvisionedConcurrentExecutions"`
+					} `yaml:"ProvisionedConcurrencyConfig"`
+					FunctionUrlConfig map[string]string `yaml:"FunctionUrlConfig"`
+					Environment       struct {
+						Variables map[string]string `yaml:"Variables"`
+					} `yaml:"Environment"`
+				} `yaml:"Properties"`
+				Metadata struct {
+					DockerContext   string            `yaml:"DockerContext"`
+					DockerBuildArgs map[string]string `yaml:"DockerBuildArgs"`
+				} `yaml:"Metadata"`
+			} `yaml:"AppFunction"`
+		} `yaml:"Resources"`
+	}
+	if err := yaml.Unmarshal([]byte(content), &template); err != nil {
+		t.Fatalf("Generated template.yaml is not valid YAML: %v\n%s", err, content)
+	}
+
+	function := template.Resources.AppFunction
+	props := function.Properties
+	if function.Type != "AWS::Serverless::Function" || props.PackageType != "Image" {
+		t.Errorf("Expected an image function, got %s (%s)", function.Type, props.PackageType)
+	}
+	if props.MemorySize != 2048 || props.Timeout != 60 || props.EphemeralStorage.Size != 1024 ||
+		props.ProvisionedConcurrencyConfig.ProvisionedConcurrentExecutions != 3 {
+		t.Errorf("Unexpected function settings: %+v", props)
+	}
+	if props.FunctionUrlConfig["AuthType"] != "FunctionUrlAuthType" {
+		t.Errorf("Expected a function URL, got %v", props.FunctionUrlConfig)
+	}
+	if template.Parameters["Region"].Default != "eu-west-1" {
+		t.Errorf("Expected the region as a parameter, got %+v", template.Parameters)
+	}
+	token, ok := template.Parameters["BuildArgSentryAuthToken"]
+	if _, found := template.Parameters["BuildArgApiBaseUrl"]; !found || !ok || !token.NoEcho {
+		t.Errorf("Expected build args as parameters, secret ones with NoEcho, got %+v", template.Parameters)
+	}
+	for _, value := range []string{"https://api.example.com", "sntrys-123"} {
+		if strings.Contains(content, value) {
+			t.Errorf("Expected the value %q of a build arg not to be written, got:\n%s", value, content)
+		}
+	}
+	if function.Metadata.DockerContext != ".." || function.Metadata.DockerBuildArgs["SENTRY_AUTH_TOKEN"] != "BuildArgSentryAuthToken" {
+		t.Errorf("Unexpected build metadata: %+v", function.Metadata)
+	}
+	if variables := props.Environment.Variables; len(variables) != 1 || variables["API_BASE_URL"] != "BuildArgApiBaseUrl" {
+		t.Errorf("Expected only the runtime args as environment variables, got %v", variables)
+	}
+	if !strings.Contains(content, "ImageUri: !Sub ") {
+		t.Errorf("Expected the image URI to use !Sub, got:\n%s", content)
+	}
+}
+
+func TestExportKubernetesManifests(t *testing.T) {
+	app := exportApp(t, `build:
+    framework: nextjs
+deployment:
+    provider: azure
+    azure:
+        cpu: 0.5
+        memory: 1024
+        min_replicas: 2
+        max_replicas: 5
+        concurrency: 20
+`, "")
+
+	files, err := export.KubernetesManifests(app)
+	if err != nil {
+		t.Fatalf("Error exporting manifests: %v", err)
+	}
+
+	var deployment struct {
+		Spec struct {
+			Replicas int `yaml:"replicas"`
+			Template struct {
+				Spec struct {
+					Containers []struct {
+						Ports []struct {
+							ContainerPort int `yaml:"containerPort"`
+						} `yaml:"ports"`
+						Resources struct {
+							Requests map[string]string `yaml:"requests"`
+							Limits   map[string]string `yaml:"limits"`
+						} `yaml:"resources"`
+					} `yaml:"containers"`
+				} `yaml:"spec"`
+			} `yaml:"template"`
+		} `yaml:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(exportedFile(t, files, "deployment.yaml")), &deployment); err != nil {
+		t.Fatalf("Error parsing deployment.yaml: %v", err)
+	}
+	c := deployment.Spec.Template.Spec.Containers[0]
+	if c.Ports[0].ContainerPort != 3000 {
+		t.Errorf("Expected nextjs to listen on 3000, got %d", c.Ports[0].ContainerPort)
+	}
+	if c.Resources.Requests["cpu"] != "500m" || c.Resources.Limits["memory"] != "1024Mi" {
+		t.Errorf("Unexpected resources: %+v", c.Resources)
+	}
+
+	var hpa struct {
+		Spec struct {
+			MinReplicas int `yaml:"minReplicas"`
+			MaxReplicas int `yaml:"maxReplicas"`
+		} `yaml:"spec"`
+	}
+	hpaContent := exportedFile(t, files, "hpa.yaml")
+	if err := yaml.Unmarshal([]byte(hpaContent), &hpa); err != nil {
+		t.Fatalf("Error parsing hpa.yaml: %v", err)
+	}
+	if !strings.Contains(hpaContent, "#   - type: Pods\n") || !strings.Contains(hpaContent, "#         averageValue: \"20\"\n") {
+		t.Errorf("Expected a commented-out Pods metric for the concurrency, got:\n%s", hpaContent)
+	}
+	if deployment.Spec.Replicas != 2 || hpa.Spec.MinReplicas != 2 || hpa.Spec.MaxReplicas != 5 {
+		t.Errorf("Expected 2-5 replicas, got deployment %d and hpa %+v", deployment.Spec.Replicas, hpa.Spec)
+	}
+	exportedFile(t, files, "service.yaml")
+}
+
+func TestExportHelmChart(t *testing.T) {
+	app := exportApp(t, "build:\n    framework: streamlit\ndeployment:\n    provider: gcp\n    gcp:\n        concurrency: 40\n", "")
+	app.Image = "registry.example.com/team/sales-dashboard:v3"
+
+	files, err := export.HelmChart(app)
+	if err != nil {
+		t.Fatalf("Error exporting chart: %v", err)
+	}
+	for _, name := range []string{"Chart.yaml", "templates/deployment.yaml", "templates/service.yaml", "templates/hpa.yaml", "templates/_helpers.tpl"} {
+		exportedFile(t, files, name)
+	}
+
+	var values struct {
+		Image struct {
+			Repository string `yaml:"repository"`
+			Tag        string `yaml:"tag"`
+		} `yaml:"image"`
+		ContainerPort int `yaml:"containerPort"`
+		Autoscaling   struct {
+			Concurrency struct {
+				Target string `yaml:"target"`
+			} `yaml:"concurrency"`
+		} `yaml:"autoscaling"`
+	}
+	if err := yaml.Unmarshal([]byte(exportedFile(t, files, "values.yaml")), &values); err != nil {
+		t.Fatalf("Error parsing values.yaml: %v", err)
+	}
+	if values.Image.Repository != "registry.example.com/team/sales-dashboard" || values.Image.Tag != "v3" {
+		t.Errorf("Unexpected image values: %+v", values.Image)
+	}
+	if values.ContainerPort != 8501 || values.Autoscaling.Concurrency.Target != "40" {
+		t.Errorf("Unexpected values: %+v", values)
+	}
+}
@@ -0,0 +1,362 @@
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+)
+
+const fakeToken = "test-token"
+
+// fakeAPI is an in-memory stand-in of the Squadbase API.
+type fakeAPI struct {
+	*httptest.Server
+	t *testing.T
+
+	mu          sync.Mutex
+	bundles     map[string][]byte
+	deployments []*api.Deployment
+	// progress is the sequence of statuses a deployment goes through, one
+	// per poll.
+	progress []string
+	polls    map[string]int
+	// failPolls makes that many polls fail with 503 first.
+	failPolls int
+
+	// users are the identities of the valid tokens.
+	users map[string]*api.User
+	// loginUser is who confirms device codes; pendingPolls token requests
+	// are answered with authorization_pending first, denied rejects them and
+	// expired answers expired_token.
+	loginUser    *api.User
+	pendingPolls int
+	denied       bool
+	expired      bool
+	tokenPolls   int
+
+	statuses []*api.TargetStatus
+	// logs are served as server-sent events when sse is set, and as
+	// newline-delimited JSON otherwise. Every response ends the stream.
+	// The first logFailures requests fail with 503.
+	logs        []*api.LogEntry
+	sse         bool
+	logFailures int
+	logRequests []url.Values
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	f := &fakeAPI{
+		t:        t,
+		bundles:  map[string][]byte{},
+		progress: []string{api.StatusBuilding, api.StatusDeploying, api.StatusReady},
+		polls:    map[string]int{},
+		users: map[string]*api.User{
+			fakeToken: {ID: "usr_1", Email: "dev@example.com", Name: "Dev"},
+		},
+		loginUser: &api.User{ID: "usr_1", Email: "dev@example.com", Name: "Dev"},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeAPI) client(t *testing.T) *api.Client {
+	client, err := api.NewClient(f.URL, fakeToken)
+	if err != nil {
+		t.Fatalf("Error creating client: %v", err)
+	}
+	return client
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]string{"code": code, "message": message}})
+}
+
+// writeOAuthError answers like an RFC 8628 token endpoint, with the error
+// code as a top-level string.
+func writeOAuthError(w http.ResponseWriter, code string, description string) {
+	body := map[string]string{"error": code}
+	if description != "" {
+		body["error_description"] = description
+	}
+	writeJSON(w, http.StatusBadRequest, body)
+}
+
+// parseOAuthForm reads a form-encoded OAuth request, failing requests sent
+// in any other format.
+func parseOAuthForm(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") || r.ParseForm() != nil {
+		writeOAuthError(w, "invalid_request", "expected a form-encoded POST")
+		return false
+	}
+	if r.PostForm.Get("client_id") != api.ClientID {
+		writeOAuthError(w, "invalid_client", "unknown client")
+		return false
+	}
+	return true
+}
+
+func (f *fakeAPI) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/oauth/device/code":
+		if !parseOAuthForm(w, r) {
+			return
+		}
+		writeJSON(w, http.StatusOK, api.DeviceCode{
+			DeviceCode:      "device-code-1",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: f.URL + "/device",
+			ExpiresIn:       60,
+			Interval:        1,
+		})
+		return
+	case "/v1/oauth/token":
+		f.serveToken(w, r)
+		return
+	}
+
+	user := f.users[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	if user == nil {
+		writeAPIError(w, http.StatusUnauthorized, "unauthorized", "invalid token")
+		return
+	}
+	if r.URL.Path == "/v1/me" {
+		writeJSON(w, http.StatusOK, user)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "v1" || parts[1] != "projects" {
+		writeAPIError(w, http.StatusNotFound, "not_found", "no such endpoint")
+		return
+	}
+	project, resource, rest := parts[2], parts[3], parts[4:]
+
+	switch {
+	case resource == "bundles" && r.Method == http.MethodPost:
+		data, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if r.Header.Get("X-Content-Sha256") != hash {
+			writeAPIError(w, http.StatusBadRequest, "checksum_mismatch", "bundle checksum mismatch")
+			return
+		}
+		id := fmt.Sprintf("bnd_%d", len(f.bundles)+1)
+		f.bundles[id] = data
+		writeJSON(w, http.StatusCreated, api.Bundle{ID: id, SHA256: hash, Size: int64(len(data))})
+
+	case resource == "deployments" && r.Method == http.MethodPost && len(rest) == 0:
+		var request api.CreateDeploymentRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request", err.Error())
+			return
+		}
+		if _, ok := f.bundles[request.BundleID]; !ok {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request", "unknown bundle")
+			return
+		}
+		d := f.addDeployment(project, &request)
+		writeJSON(w, http.StatusCreated, d)
+
+	case resource == "deployments" && r.Method == http.MethodGet && len(rest) == 0:
+		list := []*api.Deployment{}
+		for i := len(f.deployments) - 1; i >= 0; i-- {
+			d := f.deployments[i]
+			if d.Project != project || (r.URL.Query().Get("target") != "" && d.Target != r.URL.Query().Get("target")) {
+				continue
+			}
+			if limit, _ := strconv.Atoi(r.URL.Query().Get("limit")); limit > 0 && len(list) == limit {
+				break
+			}
+			list = append(list, d)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"deployments": list})
+
+	case resource == "deployments" && r.Method == http.MethodPost && len(rest) == 2 && rest[1] == "rollback":
+		source := f.deployment(project, rest[0])
+		if source == nil {
+			writeAPIError(w, http.StatusNotFound, "not_found", "deployment not found")
+			return
+		}
+		d := f.addDeployment(project, &api.CreateDeploymentRequest{
+			BundleID:    source.BundleID,
+			Target:      source.Target,
+			Environment: source.Environment,
+			Commit:      source.Commit,
+			Author:      source.Author,
+			Config:      source.Config,
+		})
+		d.RollbackOf = source.ID
+		writeJSON(w, http.StatusCreated, d)
+
+	case resource == "deployments" && r.Method == http.MethodGet && len(rest) == 1:
+		if f.failPolls > 0 {
+			f.failPolls--
+			writeAPIError(w, http.StatusServiceUnavailable, "unavailable", "try again")
+			return
+		}
+		d := f.deployment(project, rest[0])
+		if d == nil {
+			writeAPIError(w, http.StatusNotFound, "not_found", "deployment not found")
+			return
+		}
+		if !d.Done() && f.polls[d.ID] < len(f.progress) {
+			d.Status = f.progress[f.polls[d.ID]]
+			f.polls[d.ID]++
+			if d.Status == api.StatusReady {
+				d.URL = fmt.Sprintf("https://%s.squadbase.app", project)
+				f.markLive(d)
+			}
+			if d.Status == api.StatusFailed {
+				d.Message = "build failed: pip install exited with code 1"
+			}
+		}
+		writeJSON(w, http.StatusOK, d)
+
+	case resource == "status" && r.Method == http.MethodGet:
+		status := api.ProjectStatus{Project: project, Targets: []*api.TargetStatus{}}
+		for _, s := range f.statuses {
+			if target := r.URL.Query().Get("target"); target == "" || target == s.Target {
+				status.Targets = append(status.Targets, s)
+			}
+		}
+		writeJSON(w, http.StatusOK, status)
+
+	case resource == "logs" && r.Method == http.MethodGet:
+		f.serveLogs(w, r)
+
+	default:
+		writeAPIError(w, http.StatusNotFound, "not_found", "no such endpoint")
+	}
+}
+
+func (f *fakeAPI) serveLogs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f.logRequests = append(f.logRequests, q)
+	if f.logFailures > 0 {
+		f.logFailures--
+		writeAPIError(w, http.StatusServiceUnavailable, "unavailable", "try again")
+		return
+	}
+	since, _ := time.Parse(time.RFC3339Nano, q.Get("since"))
+	until, _ := time.Parse(time.RFC3339Nano, q.Get("until"))
+
+	entries := f.logs
+	if after := q.Get("after"); after != "" {
+		for i, entry := range f.logs {
+			if entry.ID == after {
+				entries = f.logs[i+1:]
+			}
+		}
+	}
+
+	if f.sse {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\nevent: heartbeat\ndata: {}\n\n")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	for _, entry := range entries {
+		if (!since.IsZero() && entry.Time.Before(since)) || (!until.IsZero() && entry.Time.After(until)) {
+			continue
+		}
+		if severity := q.Get("severity"); severity != "" && api.SeverityRank(entry.Severity) < api.SeverityRank(severity) {
+			continue
+		}
+		data, _ := json.Marshal(entry)
+		if f.sse {
+			fmt.Fprintf(w, "id: %s\ndata: %s\n\n", entry.ID, data)
+		} else {
+			fmt.Fprintf(w, "%s\n", data)
+		}
+		w.(http.Flusher).Flush()
+	}
+}
+
+func (f *fakeAPI) serveToken(w http.ResponseWriter, r *http.Request) {
+	if !parseOAuthForm(w, r) {
+		return
+	}
+	if r.PostForm.Get("grant_type") != api.DeviceCodeGrantType {
+		writeOAuthError(w, "unsupported_grant_type", "")
+		return
+	}
+	if r.PostForm.Get("device_code") != "device-code-1" {
+		writeOAuthError(w, "invalid_grant", "unknown device code")
+		return
+	}
+	f.tokenPolls++
+	switch {
+	case f.denied:
+		writeOAuthError(w, api.ErrAccessDenied, "the user denied the request")
+		return
+	case f.expired:
+		writeOAuthError(w, api.ErrExpiredToken, "")
+		return
+	case f.pendingPolls > 0:
+		f.pendingPolls--
+		writeOAuthError(w, api.ErrAuthorizationPending, "")
+		return
+	}
+	token := fmt.Sprintf("token-%d", len(f.users)+1)
+	f.users[token] = f.loginUser
+	writeJSON(w, http.StatusOK, api.Token{AccessToken: token, TokenType: "Bearer"})
+}
+
+func (f *fakeAPI) addDeployment(project string, request *api.CreateDeploymentRequest) *api.Deployment {
+	sum := sha256.Sum256([]byte(request.Config))
+	d := &api.Deployment{
+		ID:           fmt.Sprintf("dep_%d", len(f.deployments)+1),
+		Project:      project,
+		Target:       request.Target,
+		Environment:  request.Environment,
+		Status:       api.StatusQueued,
+		BundleID:     request.BundleID,
+		Commit:       request.Commit,
+		Author:       request.Author,
+		Config:       request.Config,
+		ConfigSHA256: hex.EncodeToString(sum[:]),
+		CreatedAt:    time.Date(2024, 5, 1, 9, len(f.deployments), 0, 0, time.UTC),
+	}
+	f.deployments = append(f.deployments, d)
+	return d
+}
+
+// markLive makes d the deployment serving its target.
+func (f *fakeAPI) markLive(d *api.Deployment) {
+	for _, other := range f.deployments {
+		if other.Project == d.Project && other.Target == d.Target {
+			other.Live = other == d
+		}
+	}
+}
+
+func (f *fakeAPI) deployment(project string, id string) *api.Deployment {
+	for _, d := range f.deployments {
+		if d.Project == project && d.ID == id {
+			return d
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+	"github.com/squadbase/squadbase/internal/auth"
+)
+
+func TestLoginWaitsForApproval(t *testing.T) {
+	server := newFakeAPI(t)
+	server.pendingPolls = 2
+	client := server.client(t)
+	ctx := context.Background()
+
+	code, err := client.RequestDeviceCode(ctx)
+	if err != nil {
+		t.Fatalf("Error requesting device code: %v", err)
+	}
+	token, err := client.WaitForToken(ctx, code, time.Millisecond)
+	if err != nil {
+		t.Fatalf("Error waiting for token: %v", err)
+	}
+	if token.AccessToken == "" || server.tokenPolls != 3 {
+		t.Errorf("Expected a token after two pending polls, got %+v after %d polls", token, server.tokenPolls)
+	}
+
+	server.denied = true
+	_, err = client.WaitForToken(ctx, code, time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("Expected the denial to be reported, got %v", err)
+	}
+
+	server.denied, server.expired = false, true
+	_, err = client.WaitForToken(ctx, code, time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected the expired code to be reported, got %v", err)
+	}
+
+	if _, err := client.RequestToken(ctx, "unknown"); err == nil {
+		t.Error("Expected an unknown device code to fail")
+	} else if oauthErr, ok := err.(*api.OAuthError); !ok || oauthErr.Code != "invalid_grant" {
+		t.Errorf("Expected an RFC 8628 error, got %#v", err)
+	}
+}
+
+type whoamiOutput struct {
+	Account string `json:"account"`
+	Source  string `json:"source"`
+	User    struct {
+		Email string `json:"email"`
+	} `json:"user"`
+}
+
+func whoami(t *testing.T, args ...string) whoamiOutput {
+	t.Helper()
+	var out bytes.Buffer
+	app := setupApp()
+	app.Writer = &out
+	if err := app.Run(append([]string{"squad", "whoami", "--json"}, args...)); err != nil {
+		t.Fatalf("Error running whoami: %v", err)
+	}
+	var id whoamiOutput
+	if err := json.Unmarshal(out.Bytes(), &id); err != nil {
+		t.Fatalf("Error decoding whoami output %q: %v", out.String(), err)
+	}
+	return id
+}
+
+func TestLoginCommandStoresAccounts(t *testing.T) {
+	server := newFakeAPI(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("SQUAD_TOKEN", "")
+	t.Setenv("SQUAD_ACCOUNT", "")
+
+	if err := setupApp().Run([]string{"squad", "login", "--account", "work", "--api-url", server.URL}); err != nil {
+		t.Fatalf("Error logging in: %v", err)
+	}
+	server.mu.Lock()
+	server.loginUser = &api.User{ID: "usr_2", Email: "me@example.com"}
+	server.mu.Unlock()
+	if err := setupApp().Run([]string{"squad", "login", "--account", "personal", "--api-url", server.URL}); err != nil {
+		t.Fatalf("Error logging in: %v", err)
+	}
+
+	path, err := auth.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected credentials to be stored: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected credentials to be readable only by the user, got %v", info.Mode().Perm())
+	}
+
+	if id := whoami(t); id.Account != "personal" || id.User.Email != "me@example.com" {
+		t.Errorf("Expected the last login to be current, got %+v", id)
+	}
+	if id := whoami(t, "--account", "work"); id.Account != "work" || id.User.Email != "dev@example.com" {
+		t.Errorf("Expected --account to select the work account, got %+v", id)
+	}
+
+	t.Setenv("SQUAD_TOKEN", fakeToken)
+	if id := whoami(t, "--api-url", server.URL); id.Source != "SQUAD_TOKEN" || id.Account != "" {
+		t.Errorf("Expected SQUAD_TOKEN to override the stored account, got %+v", id)
+	}
+	t.Setenv("SQUAD_TOKEN", "")
+
+	if err := setupApp().Run([]string{"squad", "logout"}); err != nil {
+		t.Fatalf("Error logging out: %v", err)
+	}
+	if id := whoami(t); id.Account != "work" {
+		t.Errorf("Expected the remaining account to become current, got %+v", id)
+	}
+	if err := setupApp().Run([]string{"squad", "logout", "--all"}); err != nil {
+		t.Fatalf("Error logging out: %v", err)
+	}
+	if err := setupApp().Run([]string{"squad", "whoami"}); err == nil {
+		t.Error("Expected whoami to fail after logging out of every account")
+	}
+}
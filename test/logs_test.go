@@ -0,0 +1,177 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/api"
+)
+
+func fakeLogs() []*api.LogEntry {
+	at := func(minute int) time.Time { return time.Date(2024, 5, 1, 9, minute, 0, 0, time.UTC) }
+	return []*api.LogEntry{
+		{ID: "log_1", Time: at(0), Severity: api.SeverityInfo, Message: "Starting server"},
+		{ID: "log_2", Time: at(1), Severity: api.SeverityWarning, Message: "Slow query", Instance: "web-1"},
+		{ID: "log_3", Time: at(2), Severity: api.SeverityDebug, Message: "Cache hit"},
+		{ID: "log_4", Time: at(3), Severity: api.SeverityError, Message: "Traceback (most recent call last)"},
+		{ID: "log_5", Time: at(4), Severity: api.SeverityError, Message: "Out of memory"},
+	}
+}
+
+func TestStatusCommand(t *testing.T) {
+	server := newFakeAPI(t)
+	server.statuses = []*api.TargetStatus{
+		{Target: "production", Provider: "gcp", Region: "asia-northeast1", Revision: "rev-3", DeploymentID: "dep_3", URL: "https://sales.squadbase.app", Health: api.HealthHealthy, Instances: 2},
+		{Target: "staging", Provider: "aws", Region: "us-east-1", Health: api.HealthDegraded, HealthMessage: "1 of 2 instances failing"},
+	}
+	t.Setenv("SQUAD_TOKEN", fakeToken)
+
+	var out bytes.Buffer
+	app := setupApp()
+	app.Writer = &out
+	err := app.Run([]string{"squad", "status", "--api-url", server.URL, "--project", "sales", "--target", "production", "--json"})
+	if err != nil {
+		t.Fatalf("Error getting status: %v", err)
+	}
+	var status api.ProjectStatus
+	if err := json.Unmarshal(out.Bytes(), &status); err != nil {
+		t.Fatalf("Error decoding status %q: %v", out.String(), err)
+	}
+	if len(status.Targets) != 1 || status.Targets[0].Revision != "rev-3" || status.Targets[0].Region != "asia-northeast1" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	out.Reset()
+	if err := app.Run([]string{"squad", "status", "--api-url", server.URL, "--project", "sales"}); err != nil {
+		t.Fatalf("Error getting status: %v", err)
+	}
+	for _, expected := range []string{"production", "rev-3", "https://sales.squadbase.app", "gcp (asia-northeast1)", "degraded", "1 of 2 instances failing"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected status output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestLogsCommandFilters(t *testing.T) {
+	for _, sse := range []bool{false, true} {
+		server := newFakeAPI(t)
+		server.logs = fakeLogs()
+		server.sse = sse
+		t.Setenv("SQUAD_TOKEN", fakeToken)
+
+		var out bytes.Buffer
+		app := setupApp()
+		app.Writer = &out
+		err := app.Run([]string{"squad", "logs", "--api-url", server.URL, "--project", "sales", "--json",
+			"--severity", "WARN", "--since", "2024-05-01T09:01:00Z", "--until", "2024-05-01T09:03:00Z"})
+		if err != nil {
+			t.Fatalf("Error reading logs: %v", err)
+		}
+
+		ids := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			var entry api.LogEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("Error decoding log line %q: %v", line, err)
+			}
+			ids = append(ids, entry.ID)
+		}
+		if strings.Join(ids, ",") != "log_2,log_4" {
+			t.Errorf("Expected warnings and errors between 09:01 and 09:03 (sse=%v), got %v", sse, ids)
+		}
+		if q := server.logRequests[0]; q.Get("severity") != "warning" || q.Get("follow") != "" {
+			t.Errorf("Unexpected logs query: %v", q)
+		}
+	}
+}
+
+func TestLogsFollowReconnects(t *testing.T) {
+	server := newFakeAPI(t)
+	server.logs = fakeLogs()[:2]
+	server.sse = true
+	client := server.client(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ids := []string{}
+	err := client.StreamLogs(ctx, "sales", api.LogsOptions{Follow: true}, fastBackoff, func(entry *api.LogEntry) {
+		ids = append(ids, entry.ID)
+		switch len(ids) {
+		case 2:
+			// The stream ends after this entry; the next one only shows up
+			// once the client reconnects.
+			server.mu.Lock()
+			server.logs = append(server.logs, fakeLogs()[2])
+			server.mu.Unlock()
+		case 3:
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Fatalf("Expected the stream to run until canceled, got %v", err)
+	}
+	if strings.Join(ids, ",") != "log_1,log_2,log_3" {
+		t.Errorf("Expected every entry exactly once, got %v", ids)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.logRequests) < 2 || server.logRequests[1].Get("after") != "log_2" || server.logRequests[1].Get("follow") != "true" {
+		t.Errorf("Expected the stream to be reopened after the last entry, got %v", server.logRequests)
+	}
+}
+
+func TestLogsRetriesWithoutFollowing(t *testing.T) {
+	server := newFakeAPI(t)
+	server.logs = fakeLogs()
+	server.logFailures = 2
+	client := server.client(t)
+
+	received := 0
+	err := client.StreamLogs(context.Background(), "sales", api.LogsOptions{}, fastBackoff, func(entry *api.LogEntry) {
+		received++
+	})
+	if err != nil || received != len(server.logs) {
+		t.Errorf("Expected the logs after two 503s, got %d entries and %v", received, err)
+	}
+
+	server.logFailures = 10
+	err = client.StreamLogs(context.Background(), "sales", api.LogsOptions{}, fastBackoff, func(*api.LogEntry) {})
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a one-shot read to give up, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestLogsFollowStopsOnWriteError(t *testing.T) {
+	server := newFakeAPI(t)
+	server.logs = fakeLogs()
+	t.Setenv("SQUAD_TOKEN", fakeToken)
+
+	app := setupApp()
+	app.Writer = failingWriter{}
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run([]string{"squad", "logs", "--api-url", server.URL, "--project", "sales", "--json", "--follow"})
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "broken pipe") {
+			t.Errorf("Expected the write error to be returned, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected following to stop when the output is closed")
+	}
+}
@@ -0,0 +1,136 @@
+package test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/bundle"
+)
+
+func TestSquadignoreMatching(t *testing.T) {
+	m, err := bundle.ParseIgnore(`# comment
+*.log
+/build
+docs/**/draft.md
+cache/
+!keep.log
+data/[0-9]*.csv
+`)
+	if err != nil {
+		t.Fatalf("Error parsing ignore file: %v", err)
+	}
+
+	testCases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{"app.log", false, true},
+		{"logs/debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"src/build", true, false},
+		{"docs/draft.md", false, true},
+		{"docs/a/b/draft.md", false, true},
+		{"cache", true, true},
+		{"cache", false, false},
+		{"src/cache", true, true},
+		{"data/2024.csv", false, true},
+		{"data/latest.csv", false, false},
+		{"main.py", false, false},
+	}
+	for _, tc := range testCases {
+		if got := m.Match(tc.path, tc.isDir); got != tc.ignored {
+			t.Errorf("Match(%q, dir=%v) = %v, expected %v", tc.path, tc.isDir, got, tc.ignored)
+		}
+	}
+}
+
+func writeProject(t *testing.T, dir string, files map[string]string, mtime time.Time) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPackageIsReproducible(t *testing.T) {
+	files := map[string]string{
+		"main.py":                  "import streamlit as st\n",
+		"pages/report.py":          "st.title('Report')\n",
+		"node_modules/x/index.js":  "module.exports = 1\n",
+		".venv/bin/python":         "",
+		".env":                     "TOKEN=secret\n",
+		".env.example":             "TOKEN=\n",
+		bundle.IgnoreFileName:      "*.tmp\n",
+		"scratch.tmp":              "tmp\n",
+		"__pycache__/main.cpython": "",
+	}
+
+	first, second := t.TempDir(), t.TempDir()
+	writeProject(t, first, files, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	writeProject(t, second, files, time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))
+
+	a, err := bundle.Create(first, filepath.Join(first, ".squad", "app.tar.gz"))
+	if err != nil {
+		t.Fatalf("Error creating bundle: %v", err)
+	}
+	b, err := bundle.Create(second, filepath.Join(t.TempDir(), "app.tar.gz"))
+	if err != nil {
+		t.Fatalf("Error creating bundle: %v", err)
+	}
+	if a.SHA256 != b.SHA256 {
+		t.Errorf("Expected identical bundles, got %s and %s", a.SHA256, b.SHA256)
+	}
+
+	f, err := os.Open(a.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	names := []string{}
+	var manifest []byte
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !h.ModTime.Equal(time.Unix(0, 0)) {
+			t.Errorf("Expected %s to have a zero mtime, got %v", h.Name, h.ModTime)
+		}
+		if h.Name == bundle.ManifestName {
+			manifest, _ = io.ReadAll(tr)
+		}
+		names = append(names, h.Name)
+	}
+
+	expected := []string{bundle.ManifestName, ".env.example", bundle.IgnoreFileName, "main.py", "pages/report.py"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Unexpected bundle contents:\n got %v\nwant %v", names, expected)
+	}
+	if string(manifest) != string(bundle.Manifest(a.Files)) || len(a.Files) != 4 {
+		t.Errorf("Expected the manifest to list the 4 files, got:\n%s", manifest)
+	}
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+func TestExpandPresetsKeepsExplicitValues(t *testing.T) {
+	doc, err := config.ParseDocument([]byte("version: '1'\ndeployment:\n    provider: aws\n    aws:\n        preset: medium\n        timeout: 120\n"))
+	if err != nil {
+		t.Fatalf("Error parsing document: %v", err)
+	}
+	if err := resources.ExpandPresets(doc); err != nil {
+		t.Fatalf("Error expanding presets: %v", err)
+	}
+
+	cfg, err := doc.Decode()
+	if err != nil {
+		t.Fatalf("Error decoding document: %v", err)
+	}
+	aws := cfg.Deployment.AWS
+	if aws.Memory != 1024 || aws.Timeout != 120 || aws.EphemeralStorage != "1024MB" {
+		t.Errorf("Unexpected expanded aws settings: %+v", aws)
+	}
+	if err := provider.Validate(cfg); err != nil {
+		t.Errorf("Expected medium preset to be valid, got %v", err)
+	}
+
+	doc, _ = config.ParseDocument([]byte("deployment:\n    provider: gcp\n    gcp:\n        preset: huge\n"))
+	if err := resources.ExpandPresets(doc); err == nil {
+		t.Errorf("Expected unknown preset to be rejected")
+	}
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.Deployment
+		problems []string
+	}{
+		{
+			name: "valid gcp",
+			cfg:  config.Deployment{Provider: "gcp", GCP: &config.GCP{Region: "us-central1", CPU: 2, Memory: 8192, Concurrency: 80}},
+		},
+		{
+			name:     "lambda memory too low",
+			cfg:      config.Deployment{Provider: "aws", AWS: &config.AWS{Memory: 64, Timeout: 30}},
+			problems: []string{"deployment.aws.memory"},
+		},
+		{
+			name:     "lambda timeout and region",
+			cfg:      config.Deployment{Provider: "aws", AWS: &config.AWS{Region: "us-central1", Timeout: 1200, EphemeralStorage: "20Gi"}},
+			problems: []string{"deployment.aws.region", "deployment.aws.timeout", "deployment.aws.ephemeral_storage"},
+		},
+		{
+			name:     "cloud run cpu memory pairing",
+			cfg:      config.Deployment{Provider: "gcp", GCP: &config.GCP{CPU: 4, Memory: 1024}},
+			problems: []string{"deployment.gcp.memory"},
+		},
+		{
+			name:     "cloud run cpu with the default memory",
+			cfg:      config.Deployment{Provider: "gcp", GCP: &config.GCP{CPU: 4}},
+			problems: []string{"deployment.gcp.memory"},
+		},
+		{
+			name:     "cloud run fractional cpu with the default concurrency",
+			cfg:      config.Deployment{Provider: "gcp", GCP: &config.GCP{CPU: 0.5, Memory: 512}},
+			problems: []string{"deployment.gcp.concurrency"},
+		},
+		{
+			name:     "cloud run invalid cpu",
+			cfg:      config.Deployment{Provider: "gcp", GCP: &config.GCP{CPU: 3}},
+			problems: []string{"deployment.gcp.cpu"},
+		},
+		{
+			name:     "container apps cpu memory pairing",
+			cfg:      config.Deployment{Provider: "azure", Azure: &config.Azure{Region: "japaneast", CPU: 1, Memory: 1024, MinReplicas: 3, MaxReplicas: 2}},
+			problems: []string{"deployment.azure.memory", "deployment.azure.min_replicas"},
+		},
+		{
+			name:     "container apps cpu with the default memory",
+			cfg:      config.Deployment{Provider: "azure", Azure: &config.Azure{CPU: 2}},
+			problems: []string{"deployment.azure.memory"},
+		},
+		{
+			name:     "unknown provider",
+			cfg:      config.Deployment{Provider: "heroku"},
+			problems: []string{"deployment.provider"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := provider.Validate(&config.Config{Deployment: tt.cfg})
+			var validationErr *resources.ValidationError
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Errorf("Expected no problems, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+			if len(validationErr.Problems) != len(tt.problems) {
+				t.Fatalf("Expected %d problems, got %v", len(tt.problems), err)
+			}
+			for i, path := range tt.problems {
+				if validationErr.Problems[i].Path != path {
+					t.Errorf("Expected problem %d at %s, got %s", i, path, validationErr.Problems[i].Path)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderOptionsByFramework(t *testing.T) {
+	if options := provider.Options("morph"); len(options) != 3 || options[0] != "aws" {
+		t.Errorf("Expected morph to offer aws first, got %v", options)
+	}
+	for _, framework := range []string{"streamlit", "nextjs"} {
+		options := provider.Options(framework)
+		for _, name := range options {
+			if name == "aws" {
+				t.Errorf("Expected %s not to offer aws, got %v", framework, options)
+			}
+		}
+		if provider.Default(framework) != "gcp" {
+			t.Errorf("Expected %s to default to gcp, got %s", framework, provider.Default(framework))
+		}
+	}
+}
+
+func TestGeneratedSquadbaseYmlIsValidForEveryProvider(t *testing.T) {
+	for _, p := range provider.All() {
+		dir := t.TempDir()
+		if err := project.CreateSquadbaseYml(dir, "morph", "3.11", "uv", p.Name(), "", nil); err != nil {
+			t.Fatalf("Error creating squadbase.yml for %s: %v", p.Name(), err)
+		}
+		region, _ := provider.DefaultValue(p, "region").(string)
+		withRegion := t.TempDir()
+		if err := project.CreateSquadbaseYml(withRegion, "morph", "3.11", "uv", p.Name(), region, nil); err != nil {
+			t.Fatalf("Error creating squadbase.yml for %s: %v", p.Name(), err)
+		}
+
+		for _, d := range []string{dir, withRegion} {
+			cfg, err := config.Load(d)
+			if err != nil {
+				t.Fatalf("Error loading squadbase.yml for %s: %v", p.Name(), err)
+			}
+			if cfg.Deployment.Provider != p.Name() {
+				t.Errorf("Expected provider %s, got %s", p.Name(), cfg.Deployment.Provider)
+			}
+			if err := provider.Validate(cfg); err != nil {
+				t.Errorf("Expected generated squadbase.yml for %s to be valid, got %v", p.Name(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/api"
+)
+
+// seedDeployment deploys config at commit through the API and waits until
+// it is done.
+func (f *fakeAPI) seedDeployment(t *testing.T, config string, commit string) *api.Deployment {
+	t.Helper()
+	client := f.client(t)
+	ctx := context.Background()
+
+	f.mu.Lock()
+	f.bundles["bnd_seed"] = []byte("bundle")
+	f.mu.Unlock()
+	d, err := client.CreateDeployment(ctx, "sales", &api.CreateDeploymentRequest{
+		BundleID: "bnd_seed",
+		Target:   "production",
+		Commit:   commit,
+		Author:   "Dev",
+		Config:   config,
+	})
+	if err != nil {
+		t.Fatalf("Error creating deployment: %v", err)
+	}
+	d, err = client.WaitForDeployment(ctx, "sales", d.ID, fastBackoff, nil)
+	if err != nil {
+		t.Fatalf("Error waiting for deployment: %v", err)
+	}
+	return d
+}
+
+func listDeployments(t *testing.T, server *fakeAPI) []*api.Deployment {
+	t.Helper()
+	var out bytes.Buffer
+	app := setupApp()
+	app.Writer = &out
+	if err := app.Run([]string{"squad", "deployments", "list", "--api-url", server.URL, "--project", "sales", "--json"}); err != nil {
+		t.Fatalf("Error listing deployments: %v", err)
+	}
+	var deployments []*api.Deployment
+	if err := json.Unmarshal(out.Bytes(), &deployments); err != nil {
+		t.Fatalf("Error decoding deployments %q: %v", out.String(), err)
+	}
+	return deployments
+}
+
+func TestDeploymentsListAndRollback(t *testing.T) {
+	server := newFakeAPI(t)
+	server.progress = []string{api.StatusReady}
+	t.Setenv("SQUAD_TOKEN", fakeToken)
+
+	first := server.seedDeployment(t, "version: '1'\ndeployment:\n    provider: gcp\n    memory: 512\n", "1111111aaaa")
+	server.seedDeployment(t, "version: '1'\ndeployment:\n    provider: gcp\n    memory: 2048\n", "2222222bbbb")
+
+	deployments := listDeployments(t, server)
+	if len(deployments) != 2 || deployments[0].ID != "dep_2" || !deployments[0].Live || deployments[1].Live {
+		t.Fatalf("Expected dep_2 to be listed first and live, got %+v", deployments)
+	}
+	if deployments[1].Commit != "1111111aaaa" || deployments[1].Author != "Dev" || len(deployments[1].ConfigSHA256) != 64 {
+		t.Errorf("Expected commit, author and config hash to be listed, got %+v", deployments[1])
+	}
+
+	app := setupApp()
+	if err := app.Run([]string{"squad", "rollback", "--api-url", server.URL, "--project", "sales", "--yes", first.ID}); err != nil {
+		t.Fatalf("Error rolling back: %v", err)
+	}
+
+	deployments = listDeployments(t, server)
+	if len(deployments) != 3 {
+		t.Fatalf("Expected the rollback to create a deployment, got %d deployments", len(deployments))
+	}
+	rollback := deployments[0]
+	if !rollback.Live || rollback.RollbackOf != first.ID || rollback.Config != first.Config || rollback.Commit != first.Commit {
+		t.Errorf("Expected the rollback to restore %s, got %+v", first.ID, rollback)
+	}
+
+	// Rolling back to the live deployment changes nothing.
+	if err := app.Run([]string{"squad", "rollback", "--api-url", server.URL, "--project", "sales", "--yes", rollback.ID}); err != nil {
+		t.Fatalf("Error rolling back: %v", err)
+	}
+	if len(listDeployments(t, server)) != 3 {
+		t.Error("Expected no deployment when rolling back to the live one")
+	}
+}
+
+func TestRollbackRejectsFailedDeployments(t *testing.T) {
+	server := newFakeAPI(t)
+	server.progress = []string{api.StatusFailed}
+	t.Setenv("SQUAD_TOKEN", fakeToken)
+	failed := server.seedDeployment(t, "version: '1'\n", "")
+
+	err := setupApp().Run([]string{"squad", "rollback", "--api-url", server.URL, "--project", "sales", "--yes", failed.ID})
+	if err == nil {
+		t.Error("Expected rolling back to a failed deployment to fail")
+	}
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/squadbase/squadbase/internal/container"
+)
+
+func TestRunOptionsApplyLimits(t *testing.T) {
+	opts := &container.RunOptions{
+		Image:    "dashboard:squad-run",
+		Name:     "dashboard-squad-run",
+		Port:     8501,
+		HostPort: 49152,
+		MemoryMB: 2048,
+		CPU:      0.5,
+		Env:      map[string]string{"TOKEN": "secret", "API_URL": "https://api.example.com"},
+	}
+
+	expected := []string{
+		"run", "--detach", "--name", "dashboard-squad-run",
+		"--memory", "2048m", "--memory-swap", "2048m", "--cpus", "0.5",
+		"--publish", "127.0.0.1:49152:8501",
+		"--env-file", "/tmp/run.env",
+		"dashboard:squad-run",
+	}
+	if args := opts.Args("/tmp/run.env"); !slices.Equal(args, expected) {
+		t.Errorf("Unexpected run command:\n got %q\nwant %q", args, expected)
+	}
+	env, err := opts.EnvFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "API_URL=https://api.example.com\nTOKEN=secret\nPORT=8501\n"; env != expected {
+		t.Errorf("Unexpected env file:\n got %q\nwant %q", env, expected)
+	}
+}
+
+// envFileEngine records the env file of a run command while it exists.
+type envFileEngine struct {
+	fakeEngine
+	content string
+	mode    os.FileMode
+}
+
+func (e *envFileEngine) Run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	if i := slices.Index(args, "--env-file"); i >= 0 {
+		data, _ := os.ReadFile(args[i+1])
+		info, _ := os.Stat(args[i+1])
+		e.content, e.mode = string(data), info.Mode().Perm()
+	}
+	return e.fakeEngine.Run(ctx, args, stdout, stderr)
+}
+
+func TestStartKeepsEnvValuesOffTheCommandLine(t *testing.T) {
+	engine := &envFileEngine{fakeEngine: fakeEngine{stdout: "abc123\n"}}
+	opts := &container.RunOptions{Image: "dashboard", Port: 8501, HostPort: 49152, Env: map[string]string{"TOKEN": "secret"}}
+	if _, err := container.Start(context.Background(), engine, opts); err != nil {
+		t.Fatalf("Error starting container: %v", err)
+	}
+
+	args := engine.calls[0]
+	for _, arg := range args {
+		if strings.Contains(arg, "secret") {
+			t.Errorf("Expected values not to be passed as arguments, got %q", args)
+		}
+	}
+	if engine.content != "TOKEN=secret\nPORT=8501\n" || engine.mode != 0600 {
+		t.Errorf("Expected a private env file with the values, got %q (%v)", engine.content, engine.mode)
+	}
+	if _, err := os.Stat(args[slices.Index(args, "--env-file")+1]); !os.IsNotExist(err) {
+		t.Errorf("Expected the env file to be removed, got %v", err)
+	}
+
+	opts.Env["CERT"] = "line one\nline two"
+	if _, err := container.Start(context.Background(), engine, opts); err == nil {
+		t.Error("Expected a multiline value to be rejected")
+	}
+}
+
+func TestInspectReportsOOMKill(t *testing.T) {
+	engine := &fakeEngine{stdout: "137 true\n"}
+	state, err := container.Inspect(context.Background(), engine, "abc123")
+	if err != nil {
+		t.Fatalf("Error inspecting container: %v", err)
+	}
+	if !state.OOMKilled || state.ExitCode != 137 {
+		t.Errorf("Expected an OOM kill with exit code 137, got %+v", state)
+	}
+	if engine.calls[0][0] != "inspect" || engine.calls[0][len(engine.calls[0])-1] != "abc123" {
+		t.Errorf("Unexpected inspect command: %q", engine.calls[0])
+	}
+}
+
+func TestTimeoutProxyCutsOffSlowRequests(t *testing.T) {
+	app := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-time.After(2 * time.Second):
+			case <-r.Context().Done():
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer app.Close()
+
+	target, _ := url.Parse(app.URL)
+	timedOut := make(chan string, 1)
+	proxy := httptest.NewServer(container.NewTimeoutProxy(target, 100*time.Millisecond, func(r *http.Request) {
+		timedOut <- r.URL.Path
+	}))
+	defer proxy.Close()
+
+	resp, err := http.Get(proxy.URL + "/fast")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected fast request to pass, got %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(proxy.URL + "/slow")
+	if err != nil {
+		t.Fatalf("Error requesting /slow: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("Expected 504 for a slow request, got %d", resp.StatusCode)
+	}
+	select {
+	case path := <-timedOut:
+		if path != "/slow" {
+			t.Errorf("Expected /slow to be reported, got %s", path)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the timeout to be reported")
+	}
+}
+
+func TestWaitHealthyStopsWhenContainerExits(t *testing.T) {
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	exited := make(chan struct{})
+	close(exited)
+	if err := container.WaitHealthy(context.Background(), unhealthy.URL, 10*time.Millisecond, time.Minute, exited); err == nil {
+		t.Error("Expected an error when the container exits before becoming healthy")
+	}
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+	if err := container.WaitHealthy(context.Background(), healthy.URL+"/_stcore/health", 10*time.Millisecond, time.Second, nil); err != nil {
+		t.Errorf("Expected a healthy app, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/secrets"
+)
+
+func TestSecretsVault(t *testing.T) {
+	identity, err := secrets.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault, err := secrets.Create([]secrets.Recipient{
+		identity.Recipient(),
+		&secrets.ScryptRecipient{Passphrase: "correct horse", WorkFactor: 10},
+	})
+	if err != nil {
+		t.Fatalf("Error creating vault: %v", err)
+	}
+	if err := vault.Set(map[string]string{"API_KEY": "sk-1", "REGION": "eu"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), secrets.FileName)
+	if err := vault.File().Write(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "sk-1") || !strings.Contains(string(data), "API_KEY: ENC[") {
+		t.Errorf("Expected readable names and encrypted values, got:\n%s", data)
+	}
+
+	for _, id := range []secrets.Identity{identity, &secrets.ScryptIdentity{Passphrase: "correct horse"}} {
+		file, err := secrets.Read(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opened, err := secrets.Open(file, []secrets.Identity{id})
+		if err != nil {
+			t.Fatalf("Error opening with %T: %v", id, err)
+		}
+		if values := opened.Values(); values["API_KEY"] != "sk-1" || values["REGION"] != "eu" {
+			t.Errorf("Unexpected values %v", values)
+		}
+	}
+	file, _ := secrets.Read(path)
+	if _, err := secrets.Open(file, []secrets.Identity{&secrets.ScryptIdentity{Passphrase: "wrong"}}); err == nil {
+		t.Error("Expected a wrong passphrase to fail")
+	}
+
+	// Changing one value leaves the ciphertext of the others alone.
+	before := file.Values["REGION"]
+	opened, _ := secrets.Open(file, []secrets.Identity{identity})
+	if err := opened.Set(map[string]string{"API_KEY": "sk-2", "REGION": "eu"}); err != nil {
+		t.Fatal(err)
+	}
+	if opened.File().Values["REGION"] != before {
+		t.Error("Expected the unchanged value to keep its ciphertext")
+	}
+
+	// Values cannot be moved between names or removed without the key.
+	swapped, _ := secrets.Read(path)
+	swapped.Values["REGION"], swapped.Values["API_KEY"] = swapped.Values["API_KEY"], swapped.Values["REGION"]
+	if _, err := secrets.Open(swapped, []secrets.Identity{identity}); err == nil {
+		t.Error("Expected swapped values to be rejected")
+	}
+	removed, _ := secrets.Read(path)
+	delete(removed.Values, "API_KEY")
+	if _, err := secrets.Open(removed, []secrets.Identity{identity}); err == nil {
+		t.Error("Expected a removed value to be rejected")
+	}
+}
+
+func TestSecretsCommand(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf 'API_KEY: sk-123\\nGREETING: hello world\\n' > \"$1\"\n"
+	if err := os.WriteFile(editor, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	run := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		app := setupApp()
+		app.Writer = &out
+		if err := app.Run(append([]string{"squad", "secrets"}, args...)); err != nil {
+			t.Fatalf("Error running secrets %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if out := run("keygen"); !strings.Contains(out, secrets.RecipientPrefix) {
+		t.Fatalf("Expected keygen to print the recipient, got %q", out)
+	}
+	if out := run("edit", "--dir", dir); out != "  + API_KEY\n  + GREETING\n" {
+		t.Errorf("Expected edit to print the changed names, got %q", out)
+	}
+
+	file, err := secrets.Read(secrets.Path(dir))
+	if err != nil {
+		t.Fatalf("Error reading the secrets file: %v", err)
+	}
+	if len(file.RecipientKeys()) != 1 || file.HasPassphrase() {
+		t.Errorf("Expected the file to be encrypted to the generated key, got %+v", file.Squad.Recipients)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal([]byte(run("decrypt", "--dir", dir, "--format", "json")), &values); err != nil {
+		t.Fatal(err)
+	}
+	if values["API_KEY"] != "sk-123" || values["GREETING"] != "hello world" {
+		t.Errorf("Unexpected decrypted values %v", values)
+	}
+
+	if out := run("exec", "--dir", dir, "--", "sh", "-c", "echo \"$GREETING\""); out != "hello world\n" {
+		t.Errorf("Expected the command to see the secrets, got %q", out)
+	}
+}
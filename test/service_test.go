@@ -0,0 +1,113 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/dotenv"
+	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/services"
+	"github.com/squadbase/squadbase/internal/validate"
+)
+
+func TestServiceAdd(t *testing.T) {
+	dir := t.TempDir()
+	if err := project.CreateSquadbaseYml(dir, "streamlit", "3.11", "uv", "gcp", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dotenv.Path(dir), []byte("DATABASE_URL=postgres://prod\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := setupApp()
+	if err := app.Run([]string{"squad", "service", "add", "--dir", dir, "postgres"}); err != nil {
+		t.Fatalf("Error adding postgres: %v", err)
+	}
+	if err := app.Run([]string{"squad", "service", "add", "--dir", dir, "--name", "cache", "--port", "6380", "redis"}); err != nil {
+		t.Fatalf("Error adding redis: %v", err)
+	}
+	if err := app.Run([]string{"squad", "service", "add", "--dir", dir, "--name", "cache", "redis"}); err == nil {
+		t.Error("Expected a second service named cache to be rejected")
+	}
+	if err := app.Run([]string{"squad", "service", "add", "--dir", dir, "--name", "queue", "--port", "70000", "redis"}); err == nil {
+		t.Error("Expected port 70000 to be rejected")
+	}
+
+	cfg, err := config.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Services) != 2 || cfg.Services[0].Version != "16" || cfg.Services[1].Port != 6380 {
+		t.Errorf("Unexpected services %+v", cfg.Services)
+	}
+	if cfg.EnvVar("DATABASE_URL") == nil || cfg.EnvVar("CACHE_REDIS_URL") == nil || cfg.EnvVar("QUEUE_REDIS_URL") != nil {
+		t.Errorf("Expected the connection URLs to be declared, got %+v", cfg.Env)
+	}
+	if err := validate.Config(cfg); err != nil {
+		t.Errorf("Expected the services to be valid, got %v", err)
+	}
+
+	values, _ := dotenv.Read(dotenv.Path(dir))
+	if values["DATABASE_URL"] != "postgres://prod" || values["CACHE_REDIS_URL"] != "redis://localhost:6380/0" || values["QUEUE_REDIS_URL"] != "" {
+		t.Errorf("Expected existing values to be kept and new ones added, got %v", values)
+	}
+
+	compose, _ := os.ReadFile(services.ComposePath(dir))
+	for _, expected := range []string{"image: postgres:16", "pg_isready", "127.0.0.1:6380:6379", "cache-data:/data", "postgres-data: {}"} {
+		if !strings.Contains(string(compose), expected) {
+			t.Errorf("Expected compose.yaml to contain %q, got:\n%s", expected, compose)
+		}
+	}
+}
+
+func TestServiceUpRunsCompose(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(config.Path(dir), []byte("version: '1'\ndeployment:\n    provider: gcp\nservices:\n    - name: postgres\n      type: postgres\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := t.TempDir()
+	calls := filepath.Join(bin, "calls")
+	script := "#!/bin/sh\necho \"$@\" >> " + calls + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	app := setupApp()
+	if err := app.Run([]string{"squad", "service", "up", "--dir", dir, "--engine", "docker"}); err != nil {
+		t.Fatalf("Error starting services: %v", err)
+	}
+	if err := app.Run([]string{"squad", "service", "down", "--dir", dir, "--engine", "docker", "--volumes"}); err != nil {
+		t.Fatalf("Error stopping services: %v", err)
+	}
+
+	data, _ := os.ReadFile(calls)
+	composePath := services.ComposePath(dir)
+	expected := "compose --file " + composePath + " up --detach --wait\ncompose --file " + composePath + " down --volumes\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected engine calls:\n%s\nexpected:\n%s", data, expected)
+	}
+	if _, err := os.Stat(composePath); err != nil {
+		t.Errorf("Expected up to generate compose.yaml: %v", err)
+	}
+}
+
+func TestServicesAreValidated(t *testing.T) {
+	cfg, err := config.Parse([]byte("version: '1'\ndeployment:\n    provider: gcp\nservices:\n    - name: db\n      type: mysql\n    - name: cache\n      type: redis\n    - name: queue\n      type: redis\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = validate.Config(cfg)
+	if err == nil {
+		t.Fatal("Expected invalid services to fail validation")
+	}
+	for _, expected := range []string{"services[0].type", "services[2].port"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %s to be reported, got:\n%v", expected, err)
+		}
+	}
+}
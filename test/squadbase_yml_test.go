@@ -0,0 +1,203 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/project"
+)
+
+func TestMergeSquadbaseYmlKeepsCommentsAndUnknownKeys(t *testing.T) {
+	dir := t.TempDir()
+	err := project.CreateSquadbaseYml(dir, "streamlit", "3.10", "poetry", "gcp", "", nil)
+	if err != nil {
+		t.Fatalf("Error creating squadbase.yml: %v", err)
+	}
+
+	path := filepath.Join(dir, config.FileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading squadbase.yml: %v", err)
+	}
+	data = append(data, []byte("custom:\n    team: data # owned by data team\n")...)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Error writing squadbase.yml: %v", err)
+	}
+
+	doc, err := config.ReadDocument(dir)
+	if err != nil {
+		t.Fatalf("Error reading document: %v", err)
+	}
+
+	project.MergeSquadbaseYml(doc, "streamlit", "3.10", "poetry", "", "gcp", "")
+	changes, err := doc.Diff(config.FileName)
+	if err != nil {
+		t.Fatalf("Error computing diff: %v", err)
+	}
+	if changes != "" {
+		t.Errorf("Expected no changes when values are unchanged, got:\n%s", changes)
+	}
+
+	project.MergeSquadbaseYml(doc, "streamlit", "3.12", "uv", "", "gcp", "")
+	if err := doc.WriteFile(dir); err != nil {
+		t.Fatalf("Error writing document: %v", err)
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading updated squadbase.yml: %v", err)
+	}
+
+	expectedStrings := []string{
+		"runtime: python3.12 # Supported: python3.9, python3.10, python3.11, python3.12",
+		"package_manager: uv # Supported: poetry, uv, pip",
+		"# Deployment Settings",
+		"    #     min_instances: 0",
+		"team: data # owned by data team",
+		"version: '1'",
+	}
+	for _, expected := range expectedStrings {
+		if !strings.Contains(string(updated), expected) {
+			t.Errorf("Expected updated file to contain '%s', but it doesn't:\n%s", expected, updated)
+		}
+	}
+
+	changedLines := 0
+	for _, line := range strings.Split(string(updated), "\n") {
+		if !strings.Contains(string(data), line) {
+			changedLines++
+		}
+	}
+	if changedLines != 2 {
+		t.Errorf("Expected exactly 2 changed lines, got %d:\n%s", changedLines, updated)
+	}
+}
+
+func TestMigrateUnversionedSquadbaseYml(t *testing.T) {
+	doc, err := config.ParseDocument([]byte("# Build Settings\nbuild:\n    runtime: python3.11\n    framework: morph\n"))
+	if err != nil {
+		t.Fatalf("Error parsing document: %v", err)
+	}
+
+	applied, err := config.Migrate(doc)
+	if err != nil {
+		t.Fatalf("Error migrating document: %v", err)
+	}
+	if len(applied) != 1 || applied[len(applied)-1].To != config.CurrentVersion {
+		t.Errorf("Expected migration to end at version %s, got %+v", config.CurrentVersion, applied)
+	}
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error rendering document: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "version: '"+config.CurrentVersion+"'\n") {
+		t.Errorf("Expected version to be the first key, got:\n%s", data)
+	}
+
+	applied, err = config.Migrate(doc)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("Expected second migration to be a no-op, got %+v, %v", applied, err)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	if err := config.CheckVersion(config.CurrentVersion); err != nil {
+		t.Errorf("Expected current version to be accepted, got %v", err)
+	}
+	if err := config.CheckVersion(""); !errors.Is(err, config.ErrOutdatedVersion) {
+		t.Errorf("Expected missing version to be outdated, got %v", err)
+	}
+	for _, version := range []string{"99", "beta"} {
+		if err := config.CheckVersion(version); !errors.Is(err, config.ErrUnsupportedVersion) {
+			t.Errorf("Expected version %q to be unsupported, got %v", version, err)
+		}
+	}
+}
+
+func TestEnvironmentOverlayDeepMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := "version: '1'\nbuild:\n    runtime: python3.11\n    framework: streamlit\ndeployment:\n    provider: gcp\n    gcp:\n        region: us-central1\n        memory: 1024\n        min_instances: 0\n        cpu: 1\n"
+	overlay := "deployment:\n    gcp:\n        memory: 4096\n        min_instances: 2\n        cpu: null\n"
+	if err := os.WriteFile(filepath.Join(dir, config.FileName), []byte(base), 0644); err != nil {
+		t.Fatalf("Error writing squadbase.yml: %v", err)
+	}
+	if err := os.WriteFile(config.OverlayPath(dir, "production"), []byte(overlay), 0644); err != nil {
+		t.Fatalf("Error writing overlay: %v", err)
+	}
+
+	doc, err := config.ReadMergedDocument(dir, "production")
+	if err != nil {
+		t.Fatalf("Error reading merged document: %v", err)
+	}
+	cfg, err := doc.Decode()
+	if err != nil {
+		t.Fatalf("Error decoding merged document: %v", err)
+	}
+
+	gcp := cfg.Deployment.GCP
+	if gcp == nil || gcp.Region != "us-central1" || gcp.Memory != 4096 || gcp.MinInstances != 2 || gcp.CPU != 0 {
+		t.Errorf("Unexpected merged gcp settings: %+v", gcp)
+	}
+	if cfg.Build.Framework != "streamlit" {
+		t.Errorf("Expected base build settings to be kept, got %+v", cfg.Build)
+	}
+
+	if _, err := config.ReadMergedDocument(dir, "staging"); err == nil || !strings.Contains(err.Error(), "production") {
+		t.Errorf("Expected missing overlay error to list available environments, got %v", err)
+	}
+}
+
+func TestInterpolateSquadbaseYml(t *testing.T) {
+	doc, err := config.ParseDocument([]byte("version: '1'\nbuild:\n    build_args:\n        - API_URL=${API_URL}\n        - PRICE=$$5\ndeployment:\n    provider: gcp\n    gcp:\n        region: ${REGION:-us-central1}\n        memory: ${MEMORY}\n"))
+	if err != nil {
+		t.Fatalf("Error parsing document: %v", err)
+	}
+
+	vars := config.Variables{
+		Environment: func(name string) (string, bool) {
+			if name == "API_URL" {
+				return "https://api.example.com", true
+			}
+			return "", false
+		},
+		DotEnv: map[string]string{"MEMORY": "2048", "API_URL": "ignored"},
+	}
+	resolutions, err := doc.Interpolate(vars)
+	if err != nil {
+		t.Fatalf("Error interpolating document: %v", err)
+	}
+
+	cfg, err := doc.Decode()
+	if err != nil {
+		t.Fatalf("Error decoding interpolated document: %v", err)
+	}
+	if cfg.Build.BuildArgs[0] != "API_URL=https://api.example.com" || cfg.Build.BuildArgs[1] != "PRICE=$5" {
+		t.Errorf("Unexpected build args: %v", cfg.Build.BuildArgs)
+	}
+	if cfg.Deployment.GCP.Region != "us-central1" || cfg.Deployment.GCP.Memory != 2048 {
+		t.Errorf("Unexpected gcp settings: %+v", cfg.Deployment.GCP)
+	}
+
+	sources := map[string]string{}
+	for _, r := range resolutions {
+		sources[r.Variable] = r.Source
+	}
+	expected := map[string]string{"API_URL": config.SourceEnvironment, "REGION": config.SourceDefault, "MEMORY": config.SourceDotEnv}
+	for name, source := range expected {
+		if sources[name] != source {
+			t.Errorf("Expected %s to come from %s, got %q", name, source, sources[name])
+		}
+	}
+
+	missingDoc, _ := config.ParseDocument([]byte("build:\n    runtime: ${RUNTIME}\n"))
+	_, err = missingDoc.Interpolate(config.Variables{})
+	var interpolationErr *config.InterpolationError
+	if !errors.As(err, &interpolationErr) || len(interpolationErr.Missing) != 1 || interpolationErr.Missing[0].Path != "build.runtime" {
+		t.Errorf("Expected missing RUNTIME to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/project"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+const targetsYml = `version: '1'
+build:
+    framework: streamlit
+deployment:
+    default_target: tokyo
+    targets:
+        production:
+            provider: aws
+            aws:
+                region: us-east-1
+        tokyo:
+            provider: gcp
+            gcp:
+                preset: medium
+                region: asia-northeast1
+`
+
+func TestDeploymentTargets(t *testing.T) {
+	doc, err := config.ParseDocument([]byte(targetsYml))
+	if err != nil {
+		t.Fatalf("Error parsing document: %v", err)
+	}
+	if err := resources.ExpandPresets(doc); err != nil {
+		t.Fatalf("Error expanding presets: %v", err)
+	}
+	cfg, err := doc.Decode()
+	if err != nil {
+		t.Fatalf("Error decoding document: %v", err)
+	}
+	if err := provider.Validate(cfg); err != nil {
+		t.Fatalf("Expected targets to be valid, got %v", err)
+	}
+
+	names := cfg.TargetNames()
+	if len(names) != 2 || names[0] != "production" || names[1] != "tokyo" {
+		t.Errorf("Expected targets in file order, got %v", names)
+	}
+
+	target, err := cfg.Target("")
+	if err != nil {
+		t.Fatalf("Error selecting default target: %v", err)
+	}
+	if target.Name != "tokyo" || target.GCP.Memory != 2048 {
+		t.Errorf("Expected default target tokyo with the medium preset expanded, got %s %+v", target.Name, target.GCP)
+	}
+
+	target, err = cfg.Target("production")
+	if err != nil || target.Provider != "aws" || target.AWS.Region != "us-east-1" {
+		t.Errorf("Unexpected production target: %+v, %v", target, err)
+	}
+	if _, err := cfg.Target("staging"); err == nil {
+		t.Errorf("Expected an unknown target to be rejected")
+	}
+}
+
+func TestValidateDeploymentTargets(t *testing.T) {
+	cfg, err := config.Parse([]byte(`deployment:
+    provider: aws
+    targets:
+        Prod:
+            provider: heroku
+        tokyo:
+            gcp:
+                memory: 100000
+`))
+	if err != nil {
+		t.Fatalf("Error parsing config: %v", err)
+	}
+
+	err = provider.Validate(cfg)
+	var validationErr *resources.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a validation error, got %v", err)
+	}
+	paths := map[string]bool{}
+	for _, problem := range validationErr.Problems {
+		paths[problem.Path] = true
+	}
+	for _, path := range []string{
+		"deployment.targets",
+		"deployment.targets.Prod",
+		"deployment.targets.Prod.provider",
+		"deployment.targets.tokyo.provider",
+		"deployment.targets.tokyo.gcp.memory",
+	} {
+		if !paths[path] {
+			t.Errorf("Expected a problem at %s, got %v", path, validationErr.Problems)
+		}
+	}
+}
+
+func TestAddDeploymentTargetToSingleDeployment(t *testing.T) {
+	dir := t.TempDir()
+	if err := project.CreateSquadbaseYml(dir, "streamlit", "3.10", "poetry", "aws", "ap-northeast-1", nil); err != nil {
+		t.Fatalf("Error creating squadbase.yml: %v", err)
+	}
+	doc, err := config.ReadDocument(dir)
+	if err != nil {
+		t.Fatalf("Error reading document: %v", err)
+	}
+
+	project.SetDeploymentTarget(doc, "tokyo", "gcp", "asia-northeast1")
+
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Error rendering document: %v", err)
+	}
+	cfg, err := config.Parse(data)
+	if err != nil {
+		t.Fatalf("Error parsing updated squadbase.yml: %v", err)
+	}
+	if err := provider.Validate(cfg); err != nil {
+		t.Errorf("Expected updated squadbase.yml to be valid, got %v", err)
+	}
+
+	targets := cfg.Targets()
+	if len(targets) != 2 {
+		t.Fatalf("Expected two targets, got %v", cfg.TargetNames())
+	}
+	if targets[0].Name != config.DefaultTargetName || targets[0].Provider != "aws" || targets[0].AWS.Region != "ap-northeast-1" {
+		t.Errorf("Expected the existing deployment to become the default target, got %+v", targets[0])
+	}
+	if targets[1].Name != "tokyo" || targets[1].Provider != "gcp" || targets[1].GCP.Region != "asia-northeast1" {
+		t.Errorf("Unexpected new target: %+v", targets[1])
+	}
+}
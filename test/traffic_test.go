@@ -0,0 +1,117 @@
+package test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/squadbase/squadbase/internal/config"
+	"github.com/squadbase/squadbase/internal/provider"
+	"github.com/squadbase/squadbase/internal/resources"
+)
+
+func TestValidateTraffic(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		traffic  string
+		problems []string
+	}{
+		{
+			name:     "canary on aws",
+			provider: "aws",
+			traffic:  "split:\n        - revision: latest\n          percent: 10\n        - revision: previous\n          percent: 90\n    tag: preview\n    rollback_threshold: 5\n",
+		},
+		{
+			name:     "split must add up to 100",
+			provider: "gcp",
+			traffic:  "split:\n        - revision: latest\n          percent: 10\n        - revision: previous\n          percent: 80\n",
+			problems: []string{"traffic.split"},
+		},
+		{
+			name:     "lambda routes to two versions",
+			provider: "aws",
+			traffic:  "split:\n        - revision: latest\n          percent: 10\n        - revision: previous\n          percent: 80\n        - revision: app-00001\n          percent: 10\n",
+			problems: []string{"traffic.split", "traffic.split[2].revision"},
+		},
+		{
+			name:     "cloud run routes to named revisions",
+			provider: "gcp",
+			traffic:  "split:\n        - revision: latest\n          percent: 10\n        - revision: previous\n          percent: 80\n        - revision: app-00001\n          percent: 10\n",
+		},
+		{
+			name:     "invalid tag and threshold",
+			provider: "gcp",
+			traffic:  "tag: Preview_1\n    rollback_threshold: 150\n",
+			problems: []string{"traffic.tag", "traffic.rollback_threshold"},
+		},
+		{
+			name:     "azure does not split traffic",
+			provider: "azure",
+			traffic:  "tag: preview\n",
+			problems: []string{"traffic"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Parse([]byte("deployment:\n    provider: " + tt.provider + "\ntraffic:\n    " + tt.traffic))
+			if err != nil {
+				t.Fatalf("Error parsing config: %v", err)
+			}
+
+			err = provider.Validate(cfg)
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Errorf("Expected traffic to be valid, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *resources.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected a validation error, got %v", err)
+			}
+			paths := []string{}
+			for _, problem := range validationErr.Problems {
+				paths = append(paths, problem.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.problems) {
+				t.Errorf("Expected problems at %v, got %v", tt.problems, validationErr.Problems)
+			}
+		})
+	}
+}
+
+func TestTrafficMapping(t *testing.T) {
+	traffic := &config.Traffic{
+		Split: []config.TrafficSplit{
+			{Revision: config.RevisionLatest, Percent: 10},
+			{Revision: config.RevisionPrevious, Percent: 90},
+		},
+		Tag: "preview",
+	}
+
+	aliases := provider.LambdaAliases(traffic)
+	expectedAliases := []provider.LambdaAlias{
+		{Name: "live", FunctionVersion: "previous", AdditionalVersionWeights: map[string]float64{"latest": 0.1}},
+		{Name: "preview", FunctionVersion: "latest"},
+	}
+	if !reflect.DeepEqual(aliases, expectedAliases) {
+		t.Errorf("Unexpected Lambda aliases: %+v", aliases)
+	}
+
+	targets := provider.CloudRunTrafficTargets(traffic)
+	expectedTargets := []provider.CloudRunTraffic{
+		{LatestRevision: true, Percent: 10, Tag: "preview"},
+		{RevisionName: "previous", Percent: 90},
+	}
+	if !reflect.DeepEqual(targets, expectedTargets) {
+		t.Errorf("Unexpected Cloud Run traffic: %+v", targets)
+	}
+
+	targets = provider.CloudRunTrafficTargets(nil)
+	if len(targets) != 1 || !targets[0].LatestRevision || targets[0].Percent != 100 {
+		t.Errorf("Expected all traffic on the latest revision without a traffic block, got %+v", targets)
+	}
+}